@@ -0,0 +1,499 @@
+package httpclient
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrBodyNotSignable 流式请求体无法在发送前读取，不能参与签名
+var ErrBodyNotSignable = errors.New("流式请求体无法签名")
+
+// Authenticator 认证器
+type Authenticator interface {
+	// Apply 发送前对请求认证（设置请求头、签名等）
+	// body 为请求体副本，流式请求体为 nil（需要签名请求体时用 bodySignable 判断）
+	Apply(req *http.Request, body []byte) error
+	// Retry 收到响应后判断是否需要重新认证并重放请求（如 401 质询、Token 过期）
+	// 每个请求最多重放一次
+	Retry(req *http.Request, resp *http.Response) (bool, error)
+}
+
+// SetAuth 设置认证器（nil 表示不认证）
+func (c *Client) SetAuth(auth Authenticator) *Client {
+	c.auth = auth
+	return c
+}
+
+// applyAuth 对请求进行认证
+func (c *Client) applyAuth(req *http.Request) error {
+	if c.auth == nil {
+		return nil
+	}
+	return c.auth.Apply(req, snapshotRequestBody(req))
+}
+
+// bodySignable 请求体是否已读入 body（流式请求体发送前无法读取）
+func bodySignable(req *http.Request, body []byte) bool {
+	return body != nil || replayable(req)
+}
+
+// retryAuth 根据响应判断是否需要重新认证，需要时重放一次请求
+// header 为发送前的请求头（http.Client 会把 cookiejar 的Cookie直接追加到请求上）
+func (c *Client) retryAuth(httpClient *http.Client, req *http.Request, resp *http.Response, header http.Header) (*http.Response, *http.Request, error) {
+	retry, err := c.auth.Retry(req, resp)
+	if err != nil {
+		resp.Body.Close()
+		return nil, req, fmt.Errorf("重新认证失败: %w", err)
+	}
+	if !retry {
+		return resp, req, nil
+	}
+	// 流式请求体已发送，无法重放
+	if !replayable(req) {
+		resp.Body.Close()
+		return nil, req, fmt.Errorf("%w: 服务器要求重新认证 (%s)", ErrBodyNotReplayable, resp.Status)
+	}
+
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	newReq := req.Clone(req.Context())
+	newReq.Header = header.Clone()
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, req, fmt.Errorf("重放请求失败: %w", err)
+		}
+		newReq.Body = body
+	}
+	if err := c.rateLimiter.wait(newReq.Context(), newReq.URL.Hostname()); err != nil {
+		return nil, newReq, fmt.Errorf("等待限流失败: %w", err)
+	}
+	if err := c.applyAuth(newReq); err != nil {
+		return nil, newReq, fmt.Errorf("认证失败: %w", err)
+	}
+
+	resp, err = httpClient.Do(newReq)
+	return resp, newReq, err
+}
+
+// ==================== Basic ====================
+
+// BasicAuth Basic 认证
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+// NewBasicAuth 创建 Basic 认证器
+func NewBasicAuth(username, password string) *BasicAuth {
+	return &BasicAuth{Username: username, Password: password}
+}
+
+// Apply 设置 Authorization 头
+func (a *BasicAuth) Apply(req *http.Request, body []byte) error {
+	req.SetBasicAuth(a.Username, a.Password)
+	return nil
+}
+
+// Retry Basic 认证不重试
+func (a *BasicAuth) Retry(req *http.Request, resp *http.Response) (bool, error) {
+	return false, nil
+}
+
+// ==================== Bearer ====================
+
+// TokenRefreshFunc 刷新Token函数
+type TokenRefreshFunc func() (string, error)
+
+// BearerAuth Bearer Token 认证（401 时自动刷新一次并重放）
+type BearerAuth struct {
+	mu      sync.Mutex
+	token   string
+	refresh TokenRefreshFunc
+}
+
+// NewBearerAuth 创建 Bearer 认证器
+// refresh 为 nil 时不自动刷新
+func NewBearerAuth(token string, refresh TokenRefreshFunc) *BearerAuth {
+	return &BearerAuth{token: token, refresh: refresh}
+}
+
+// Clone 复制认证器（当前Token和刷新函数），之后各自刷新
+func (a *BearerAuth) Clone() Authenticator {
+	return &BearerAuth{token: a.Token(), refresh: a.refresh}
+}
+
+// Token 获取当前Token
+func (a *BearerAuth) Token() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.token
+}
+
+// SetToken 设置Token
+func (a *BearerAuth) SetToken(token string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.token = token
+}
+
+// Apply 设置 Authorization 头
+func (a *BearerAuth) Apply(req *http.Request, body []byte) error {
+	token := a.Token()
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return nil
+}
+
+// Retry 401 时刷新Token
+func (a *BearerAuth) Retry(req *http.Request, resp *http.Response) (bool, error) {
+	if resp.StatusCode != http.StatusUnauthorized || a.refresh == nil {
+		return false, nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// 并发请求时，其他请求可能已经刷新过Token
+	used := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if a.token != "" && used != a.token {
+		return true, nil
+	}
+
+	token, err := a.refresh()
+	if err != nil {
+		return false, err
+	}
+	a.token = token
+	return true, nil
+}
+
+// ==================== Digest ====================
+
+// DigestAuth Digest 认证（RFC 7616），收到 401 质询后计算响应并重放
+// 质询按 host 分别保存，只对发出质询的 host 携带认证
+type DigestAuth struct {
+	Username string
+	Password string
+
+	mu         sync.Mutex
+	challenges map[string]*digestChallenge // host -> 服务器质询
+}
+
+// digestChallenge 单个 host 的质询参数和 nonce 计数
+type digestChallenge struct {
+	params map[string]string
+	nc     int
+}
+
+// NewDigestAuth 创建 Digest 认证器
+func NewDigestAuth(username, password string) *DigestAuth {
+	return &DigestAuth{Username: username, Password: password}
+}
+
+// Clone 复制认证器（只复制用户名密码，nonce 计数不能共用，复制后重新等待质询）
+func (a *DigestAuth) Clone() Authenticator {
+	return &DigestAuth{Username: a.Username, Password: a.Password}
+}
+
+// Apply 该 host 已有质询时计算 Authorization 头（首次请求不带认证，等待服务器质询）
+func (a *DigestAuth) Apply(req *http.Request, body []byte) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	c := a.challenges[digestScope(req)]
+	if c == nil {
+		return nil
+	}
+	c.nc++
+	header, err := a.authorization(req, body, c)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", header)
+	return nil
+}
+
+// Retry 收到 Digest 质询时保存该 host 的质询参数并重放
+func (a *DigestAuth) Retry(req *http.Request, resp *http.Response) (bool, error) {
+	if resp.StatusCode != http.StatusUnauthorized {
+		return false, nil
+	}
+	params := parseDigestChallenge(resp.Header.Values("WWW-Authenticate"))
+	if params == nil {
+		return false, nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// 已携带认证仍被拒绝：除非 nonce 过期(stale=true)或换了 realm，否则是账号密码错误
+	scope := digestScope(req)
+	if old := a.challenges[scope]; old != nil &&
+		strings.HasPrefix(req.Header.Get("Authorization"), "Digest ") &&
+		old.params["realm"] == params["realm"] &&
+		!strings.EqualFold(params["stale"], "true") {
+		return false, nil
+	}
+
+	if a.challenges == nil {
+		a.challenges = make(map[string]*digestChallenge)
+	}
+	a.challenges[scope] = &digestChallenge{params: params}
+	return true, nil
+}
+
+// digestScope 质询的作用范围（scheme://host:port）
+func digestScope(req *http.Request) string {
+	return strings.ToLower(req.URL.Scheme + "://" + req.URL.Host)
+}
+
+// authorization 计算 Digest Authorization 头
+func (a *DigestAuth) authorization(req *http.Request, body []byte, challenge *digestChallenge) (string, error) {
+	c := challenge.params
+	algorithm := c["algorithm"]
+	if algorithm == "" {
+		algorithm = "MD5"
+	}
+
+	var newHash func() hash.Hash
+	switch strings.TrimSuffix(strings.ToUpper(algorithm), "-SESS") {
+	case "MD5":
+		newHash = md5.New
+	case "SHA-256":
+		newHash = sha256.New
+	default:
+		return "", fmt.Errorf("不支持的Digest算法: %s", algorithm)
+	}
+	h := func(s string) string {
+		hh := newHash()
+		hh.Write([]byte(s))
+		return hex.EncodeToString(hh.Sum(nil))
+	}
+
+	cnonce := randomHex(8)
+	nc := fmt.Sprintf("%08x", challenge.nc)
+	uri := req.URL.RequestURI()
+
+	ha1 := h(a.Username + ":" + c["realm"] + ":" + a.Password)
+	if strings.HasSuffix(strings.ToUpper(algorithm), "-SESS") {
+		ha1 = h(ha1 + ":" + c["nonce"] + ":" + cnonce)
+	}
+
+	// 选择 qop：优先 auth，其次 auth-int
+	qop := ""
+	for _, q := range strings.Split(c["qop"], ",") {
+		q = strings.TrimSpace(q)
+		if q == "auth" {
+			qop = q
+			break
+		}
+		if q == "auth-int" {
+			qop = q
+		}
+	}
+
+	ha2 := h(req.Method + ":" + uri)
+	if qop == "auth-int" {
+		if !bodySignable(req, body) {
+			return "", fmt.Errorf("%w: Digest auth-int", ErrBodyNotSignable)
+		}
+		ha2 = h(req.Method + ":" + uri + ":" + h(string(body)))
+	}
+
+	var response string
+	if qop != "" {
+		response = h(ha1 + ":" + c["nonce"] + ":" + nc + ":" + cnonce + ":" + qop + ":" + ha2)
+	} else {
+		response = h(ha1 + ":" + c["nonce"] + ":" + ha2)
+	}
+
+	parts := []string{
+		fmt.Sprintf(`username="%s"`, a.Username),
+		fmt.Sprintf(`realm="%s"`, c["realm"]),
+		fmt.Sprintf(`nonce="%s"`, c["nonce"]),
+		fmt.Sprintf(`uri="%s"`, uri),
+		fmt.Sprintf(`algorithm=%s`, algorithm),
+		fmt.Sprintf(`response="%s"`, response),
+	}
+	if qop != "" {
+		parts = append(parts, "qop="+qop, "nc="+nc, fmt.Sprintf(`cnonce="%s"`, cnonce))
+	}
+	if opaque, ok := c["opaque"]; ok {
+		parts = append(parts, fmt.Sprintf(`opaque="%s"`, opaque))
+	}
+	return "Digest " + strings.Join(parts, ", "), nil
+}
+
+// parseDigestChallenge 从 WWW-Authenticate 头中解析 Digest 质询参数
+func parseDigestChallenge(values []string) map[string]string {
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if len(v) < 7 || !strings.EqualFold(v[:7], "Digest ") {
+			continue
+		}
+		return parseAuthParams(v[7:])
+	}
+	return nil
+}
+
+// parseAuthParams 解析 key=value, key="quoted, value" 形式的参数
+func parseAuthParams(s string) map[string]string {
+	result := make(map[string]string)
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " ,")
+		eq := strings.Index(s, "=")
+		if eq < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = strings.TrimLeft(s[eq+1:], " ")
+
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			// 带引号的值，处理转义
+			var sb strings.Builder
+			i := 1
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				sb.WriteByte(s[i])
+			}
+			value = sb.String()
+			if i < len(s) {
+				i++
+			}
+			s = s[i:]
+		} else {
+			end := strings.Index(s, ",")
+			if end < 0 {
+				end = len(s)
+			}
+			value = strings.TrimSpace(s[:end])
+			s = s[end:]
+		}
+		result[key] = value
+	}
+	return result
+}
+
+// randomHex 生成随机十六进制字符串
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ==================== HMAC 签名 ====================
+
+// HMACAuth HMAC 请求签名
+// 签名内容见 CanonicalRequest，签名结果为十六进制
+type HMACAuth struct {
+	KeyID           string           // 访问密钥ID
+	Secret          []byte           // 签名密钥
+	Hash            func() hash.Hash // 哈希算法（默认 SHA256）
+	KeyIDHeader     string           // 密钥ID请求头（默认 X-Key-Id）
+	SignatureHeader string           // 签名请求头（默认 X-Signature）
+	TimestampHeader string           // 时间戳请求头（默认 X-Timestamp）
+	Base64          bool             // 签名使用 Base64 编码（默认十六进制）
+}
+
+// NewHMACAuth 创建 HMAC 签名认证器
+func NewHMACAuth(keyID, secret string) *HMACAuth {
+	return &HMACAuth{
+		KeyID:           keyID,
+		Secret:          []byte(secret),
+		Hash:            sha256.New,
+		KeyIDHeader:     "X-Key-Id",
+		SignatureHeader: "X-Signature",
+		TimestampHeader: "X-Timestamp",
+	}
+}
+
+// Apply 计算签名并设置请求头（流式请求体返回 ErrBodyNotSignable）
+func (a *HMACAuth) Apply(req *http.Request, body []byte) error {
+	if !bodySignable(req, body) {
+		return fmt.Errorf("%w: HMAC", ErrBodyNotSignable)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	signature := a.Sign(req.Method, req.URL, body, timestamp)
+
+	if a.KeyIDHeader != "" && a.KeyID != "" {
+		req.Header.Set(a.KeyIDHeader, a.KeyID)
+	}
+	req.Header.Set(a.TimestampHeader, timestamp)
+	req.Header.Set(a.SignatureHeader, signature)
+	return nil
+}
+
+// Retry HMAC 签名不重试
+func (a *HMACAuth) Retry(req *http.Request, resp *http.Response) (bool, error) {
+	return false, nil
+}
+
+// Sign 计算签名（服务端可用同样的方法校验）
+func (a *HMACAuth) Sign(method string, u *url.URL, body []byte, timestamp string) string {
+	newHash := a.Hash
+	if newHash == nil {
+		newHash = sha256.New
+	}
+	mac := hmac.New(newHash, a.Secret)
+	mac.Write([]byte(CanonicalRequest(method, u, body, timestamp)))
+	sum := mac.Sum(nil)
+	if a.Base64 {
+		return base64.StdEncoding.EncodeToString(sum)
+	}
+	return hex.EncodeToString(sum)
+}
+
+// CanonicalRequest 构建规范化请求字符串，各部分以换行分隔：
+// 请求方法、路径、按key排序的查询参数、请求体SHA256、时间戳
+func CanonicalRequest(method string, u *url.URL, body []byte, timestamp string) string {
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	query := u.Query()
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(query))
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			pairs = append(pairs, url.QueryEscape(k)+"="+url.QueryEscape(v))
+		}
+	}
+
+	bodyHash := sha256.Sum256(body)
+
+	return strings.Join([]string{
+		strings.ToUpper(method),
+		path,
+		strings.Join(pairs, "&"),
+		hex.EncodeToString(bodyHash[:]),
+		timestamp,
+	}, "\n")
+}
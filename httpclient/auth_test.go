@@ -0,0 +1,252 @@
+package httpclient
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newDigestServer 校验 MD5 qop=auth 的 Digest 认证，stale 为 true 时第一次认证返回 nonce 过期
+func newDigestServer(t *testing.T, realm, password string, stale bool) (*httptest.Server, *int32) {
+	t.Helper()
+	var hits int32
+	var mu sync.Mutex
+	nonce := "n1"
+	md5hex := func(s string) string {
+		sum := md5.Sum([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		mu.Lock()
+		defer mu.Unlock()
+
+		challenge := func(stale bool) {
+			v := `Digest realm="` + realm + `", nonce="` + nonce + `", qop="auth", opaque="op"`
+			if stale {
+				v += ", stale=true"
+			}
+			w.Header().Set("WWW-Authenticate", v)
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "Digest ") {
+			challenge(false)
+			return
+		}
+		p := parseAuthParams(auth[7:])
+		if p["nonce"] != nonce {
+			challenge(true)
+			return
+		}
+		ha1 := md5hex(p["username"] + ":" + realm + ":" + password)
+		ha2 := md5hex(r.Method + ":" + p["uri"])
+		want := md5hex(ha1 + ":" + p["nonce"] + ":" + p["nc"] + ":" + p["cnonce"] + ":" + p["qop"] + ":" + ha2)
+		if p["response"] != want {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if stale {
+			stale = false
+			nonce = "n2"
+			challenge(true)
+			return
+		}
+		w.Write([]byte("ok " + p["nc"]))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func TestDigestAuth(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		stale    bool
+		status   int
+		hits     int32
+	}{
+		{"质询后重放", "secret", false, 200, 2},
+		{"密码错误不重复重放", "wrong", false, 401, 2},
+		// 每个请求只重放一次，stale 质询保存后由下一个请求使用新 nonce
+		{"nonce过期重新质询", "secret", true, 200, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, hits := newDigestServer(t, "api", "secret", tt.stale)
+			client := New().SetAuth(NewDigestAuth("bob", tt.password))
+			resp, err := client.Get(srv.URL+"/a?x=1", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.stale {
+				if resp.StatusCode != 401 {
+					t.Fatalf("stale status = %d", resp.StatusCode)
+				}
+				resp, err = client.Get(srv.URL+"/a?x=1", nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if resp.StatusCode != tt.status || *hits != tt.hits {
+				t.Errorf("status=%d hits=%d, want %d %d", resp.StatusCode, *hits, tt.status, tt.hits)
+			}
+		})
+	}
+
+	// 同一 host 后续请求直接携带认证，nonce 计数递增
+	srv, hits := newDigestServer(t, "api", "secret", false)
+	client := New().SetAuth(NewDigestAuth("bob", "secret"))
+	client.Get(srv.URL, nil)
+	resp, _ := client.Get(srv.URL, nil)
+	if resp.Text() != "ok 00000002" || *hits != 3 {
+		t.Errorf("第二次请求 = %q, hits=%d", resp.Text(), *hits)
+	}
+}
+
+func TestDigestAuthScopedByHost(t *testing.T) {
+	srv, _ := newDigestServer(t, "api", "secret", false)
+	var leaked string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		leaked = r.Header.Get("Authorization")
+	}))
+	defer other.Close()
+
+	client := New().SetAuth(NewDigestAuth("bob", "secret"))
+	if resp, err := client.Get(srv.URL, nil); err != nil || resp.StatusCode != 200 {
+		t.Fatalf("Get = %v, %v", resp, err)
+	}
+	client.Get(other.URL, nil)
+	if leaked != "" {
+		t.Errorf("其他 host 收到了 Digest 认证: %s", leaked)
+	}
+}
+
+// textResponse 构造文本响应
+func textResponse(req *http.Request, status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}
+}
+
+func TestBearerAuthRefresh(t *testing.T) {
+	client, mock := NewMockClient()
+	var refreshed int32
+	mock.On("GET", "*").ReplyFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get("Authorization") != "Bearer new" {
+			return textResponse(req, 401, "expired"), nil
+		}
+		return textResponse(req, 200, "ok"), nil
+	})
+
+	auth := NewBearerAuth("old", func() (string, error) {
+		atomic.AddInt32(&refreshed, 1)
+		return "new", nil
+	})
+	client.SetAuth(auth)
+	resp, err := client.Get("http://api.test/me", nil)
+	if err != nil || resp.Text() != "ok" {
+		t.Fatalf("Get = %v, %v", resp, err)
+	}
+	if refreshed != 1 || auth.Token() != "new" {
+		t.Errorf("refreshed=%d token=%s", refreshed, auth.Token())
+	}
+
+	// 并发请求只刷新一次
+	auth.SetToken("old")
+	atomic.StoreInt32(&refreshed, 0)
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if resp, err := client.Get("http://api.test/me", nil); err != nil || resp.StatusCode != 200 {
+				t.Errorf("并发请求 = %v, %v", resp, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if refreshed != 1 {
+		t.Errorf("并发刷新 %d 次, want 1", refreshed)
+	}
+
+	// 刷新失败返回错误
+	failing := New().SetAuth(NewBearerAuth("old", func() (string, error) {
+		return "", errors.New("refresh denied")
+	}))
+	failing.SetTransport(mock)
+	if _, err := failing.Get("http://api.test/me", nil); err == nil || !strings.Contains(err.Error(), "refresh denied") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestAuthRetryRateLimited(t *testing.T) {
+	srv, hits := newDigestServer(t, "api", "secret", false)
+	client := New().
+		SetAuth(NewDigestAuth("bob", "secret")).
+		SetHostRateLimit(AllHosts, RateLimit{RPS: 20, Burst: 1})
+	d := elapsed(func() { client.Get(srv.URL, nil) })
+	if *hits != 2 || d < 40*time.Millisecond {
+		t.Errorf("hits=%d 耗时=%v，重新认证未经过限流", *hits, d)
+	}
+}
+
+func TestSignStreamingBody(t *testing.T) {
+	tests := []struct {
+		name string
+		auth Authenticator
+	}{
+		{"HMAC", NewHMACAuth("k", "s")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mock := NewMockClient()
+			route := mock.On("POST", "*").Reply(200, "ok")
+			client.SetAuth(tt.auth)
+
+			// 流式请求体无法签名
+			stream := io.MultiReader(strings.NewReader("payload"))
+			if _, err := client.Post("http://api.test/up", stream, nil); !errors.Is(err, ErrBodyNotSignable) {
+				t.Errorf("流式请求体 err = %v", err)
+			}
+			// 内存请求体正常签名
+			if _, err := client.Post("http://api.test/up", "payload", nil); err != nil {
+				t.Fatal(err)
+			}
+			if call := route.LastCall(); call == nil || string(call.Body) != "payload" {
+				t.Errorf("call = %+v", call)
+			}
+		})
+	}
+
+	// Digest 只有 auth-int 需要请求体
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			w.Header().Set("WWW-Authenticate", `Digest realm="r", nonce="n", qop="auth-int"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+	client := New().SetAuth(NewDigestAuth("bob", "secret"))
+	client.Post(srv.URL, "payload", nil)
+	stream := io.MultiReader(strings.NewReader("payload"))
+	if _, err := client.Post(srv.URL, stream, nil); !errors.Is(err, ErrBodyNotSignable) {
+		t.Errorf("Digest auth-int 流式请求体 err = %v", err)
+	}
+	if resp, err := client.Post(srv.URL, "payload", nil); err != nil || resp.StatusCode != 200 {
+		t.Errorf("Digest auth-int = %v, %v", resp, err)
+	}
+}
@@ -0,0 +1,73 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ErrBodyTooLarge 响应体超过大小限制（使用 errors.Is 判断）
+var ErrBodyTooLarge = errors.New("响应体超过大小限制")
+
+// BodyTooLargeError 响应体超过大小限制
+type BodyTooLargeError struct {
+	Limit         int64 // 大小限制（字节）
+	ContentLength int64 // 响应声明的长度（未知时为 -1）
+}
+
+func (e *BodyTooLargeError) Error() string {
+	if e.ContentLength >= 0 {
+		return fmt.Sprintf("响应体超过大小限制: Content-Length %d > %d", e.ContentLength, e.Limit)
+	}
+	return fmt.Sprintf("响应体超过大小限制: > %d", e.Limit)
+}
+
+func (e *BodyTooLargeError) Is(target error) bool {
+	return target == ErrBodyTooLarge
+}
+
+// SetMaxBodySize 设置默认响应体大小限制（解压后计算，0 不限制）
+func (c *Client) SetMaxBodySize(size int64) *Client {
+	c.maxBodySize = size
+	return c
+}
+
+// bodyLimit 当前请求的响应体大小限制（Options 优先，-1 不限制）
+func (c *Client) bodyLimit(opts *Options) int64 {
+	limit := c.maxBodySize
+	if opts.MaxBodySize != 0 {
+		limit = opts.MaxBodySize
+	}
+	if limit < 0 {
+		return 0
+	}
+	return limit
+}
+
+// readBody 读取响应体，超过 limit 时返回 *BodyTooLargeError（limit 为 0 不限制）
+// reader 为解压后的响应体，以解压后的大小计算，防止压缩炸弹
+func readBody(resp *http.Response, reader io.Reader, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return io.ReadAll(reader)
+	}
+
+	// 未压缩且声明长度已超限，不必读取
+	if resp.Header.Get("Content-Encoding") == "" && resp.ContentLength > limit {
+		return nil, &BodyTooLargeError{Limit: limit, ContentLength: resp.ContentLength}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		return body, err
+	}
+	if int64(len(body)) > limit {
+		return nil, &BodyTooLargeError{Limit: limit, ContentLength: -1}
+	}
+	return body, nil
+}
+
+// discardBody 丢弃响应体（读取少量剩余数据以便复用连接）
+func discardBody(body io.Reader) {
+	io.CopyN(io.Discard, body, 64<<10)
+}
@@ -0,0 +1,101 @@
+package httpclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestMaxBodySize(t *testing.T) {
+	body := strings.Repeat("x", 1000)
+	var bomb bytes.Buffer
+	gz := gzip.NewWriter(&bomb)
+	gz.Write(bytes.Repeat([]byte{0}, 1<<20))
+	gz.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/length":
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			w.Write([]byte(body))
+		case "/chunked":
+			w.Write([]byte(body[:500]))
+			w.(http.Flusher).Flush()
+			w.Write([]byte(body[500:]))
+		case "/gzip":
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Write(bomb.Bytes())
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name          string
+		path          string
+		clientLimit   int64
+		opts          *Options
+		wantSize      int
+		contentLength int64 // 超限时错误中的声明长度，0 表示不超限
+	}{
+		{"不限制", "/length", 0, nil, 1000, 0},
+		{"刚好等于限制", "/length", 1000, nil, 1000, 0},
+		{"声明长度超限不读取", "/length", 999, nil, 0, 1000},
+		{"分块传输超限", "/chunked", 999, nil, 0, -1},
+		{"按解压后的大小计算", "/gzip", 64 << 10, nil, 0, -1},
+		{"请求级限制覆盖客户端", "/length", 0, &Options{MaxBodySize: 100}, 0, 1000},
+		{"请求级取消限制", "/chunked", 100, &Options{MaxBodySize: -1}, 1000, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := New().SetMaxBodySize(tt.clientLimit)
+			resp, err := client.Get(srv.URL+tt.path, tt.opts)
+			if tt.contentLength == 0 {
+				if err != nil || len(resp.Body) != tt.wantSize {
+					t.Fatalf("Get = %v, %v", resp, err)
+				}
+				return
+			}
+
+			// 超限时返回错误，不返回截断的响应体
+			if resp != nil {
+				t.Errorf("超限时返回了响应: %d 字节", len(resp.Body))
+			}
+			var tooLarge *BodyTooLargeError
+			if !errors.Is(err, ErrBodyTooLarge) || !errors.As(err, &tooLarge) {
+				t.Fatalf("err = %v, want BodyTooLargeError", err)
+			}
+			if tooLarge.ContentLength != tt.contentLength {
+				t.Errorf("ContentLength = %d, want %d", tooLarge.ContentLength, tt.contentLength)
+			}
+		})
+	}
+}
+
+func TestDiscardBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Probe", "1")
+		w.Write([]byte(strings.Repeat("x", 1000)))
+	}))
+	defer srv.Close()
+
+	// 不读取响应体时大小限制不生效
+	client := New().SetMaxBodySize(10)
+	for i := 0; i < 2; i++ {
+		resp, err := client.R().SetDiscardBody(true).Get(srv.URL)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if resp.StatusCode != 200 || resp.Headers.Get("X-Probe") != "1" || len(resp.Body) != 0 {
+			t.Errorf("resp = %d %v %d 字节", resp.StatusCode, resp.Headers, len(resp.Body))
+		}
+		// 少量剩余数据被丢弃，连接可以复用
+		if i == 1 && !resp.Timings.ConnReused {
+			t.Error("丢弃响应体后连接未复用")
+		}
+	}
+}
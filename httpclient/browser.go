@@ -0,0 +1,376 @@
+package httpclient
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BrowserFamily 浏览器类型
+type BrowserFamily string
+
+const (
+	BrowserChrome  BrowserFamily = "chrome"
+	BrowserEdge    BrowserFamily = "edge"
+	BrowserFirefox BrowserFamily = "firefox"
+	BrowserSafari  BrowserFamily = "safari"
+)
+
+// BrowserPlatform 操作系统平台
+type BrowserPlatform string
+
+const (
+	PlatformWindows BrowserPlatform = "Windows"
+	PlatformMacOS   BrowserPlatform = "macOS"
+	PlatformLinux   BrowserPlatform = "Linux"
+	PlatformAndroid BrowserPlatform = "Android"
+	PlatformIOS     BrowserPlatform = "iOS"
+)
+
+// DeviceType 设备类型
+type DeviceType int
+
+const (
+	DeviceAny     DeviceType = iota // 桌面和移动端
+	DeviceDesktop                   // 仅桌面端
+	DeviceMobile                    // 仅移动端
+)
+
+// browserHeaderKeys 浏览器指纹相关的请求头（切换浏览器时先清除，避免残留不一致的头）
+var browserHeaderKeys = []string{
+	"User-Agent",
+	"Accept",
+	"Accept-Language",
+	"Sec-Ch-Ua",
+	"Sec-Ch-Ua-Mobile",
+	"Sec-Ch-Ua-Platform",
+}
+
+// BrowserProfile 一组相互一致的浏览器请求头
+type BrowserProfile struct {
+	Family          BrowserFamily
+	Platform        BrowserPlatform
+	Mobile          bool
+	Version         string // 浏览器版本（如 "131.0.0.0"、"17.5"）
+	UserAgent       string
+	SecChUa         string // 仅 Chromium 内核（Chrome、Edge）
+	SecChUaMobile   string
+	SecChUaPlatform string
+	Accept          string
+	AcceptLanguage  string
+	AcceptEncoding  string // 浏览器实际发送的值（不会自动设置，br 响应无法解压）
+}
+
+// Headers 转换为请求头（不含 Accept-Encoding）
+func (p *BrowserProfile) Headers() map[string]string {
+	headers := map[string]string{
+		"User-Agent":      p.UserAgent,
+		"Accept":          p.Accept,
+		"Accept-Language": p.AcceptLanguage,
+	}
+	if p.SecChUa != "" {
+		headers["Sec-Ch-Ua"] = p.SecChUa
+		headers["Sec-Ch-Ua-Mobile"] = p.SecChUaMobile
+		headers["Sec-Ch-Ua-Platform"] = p.SecChUaPlatform
+	}
+	return headers
+}
+
+// browserCombo 浏览器和平台的有效组合
+type browserCombo struct {
+	family   BrowserFamily
+	platform BrowserPlatform
+}
+
+var browserCombos = []browserCombo{
+	{BrowserChrome, PlatformWindows},
+	{BrowserChrome, PlatformMacOS},
+	{BrowserChrome, PlatformLinux},
+	{BrowserChrome, PlatformAndroid},
+	{BrowserEdge, PlatformWindows},
+	{BrowserEdge, PlatformMacOS},
+	{BrowserFirefox, PlatformWindows},
+	{BrowserFirefox, PlatformMacOS},
+	{BrowserFirefox, PlatformLinux},
+	{BrowserFirefox, PlatformAndroid},
+	{BrowserSafari, PlatformMacOS},
+	{BrowserSafari, PlatformIOS},
+}
+
+// 版本范围
+var (
+	chromeVersions  = [2]int{124, 131}
+	firefoxVersions = [2]int{125, 133}
+	safariVersions  = []string{"17.0", "17.1", "17.2", "17.3", "17.4", "17.5", "17.6", "18.0", "18.1"}
+	androidVersions = []int{10, 11, 12, 13, 14}
+)
+
+// BrowserGenerator 浏览器请求头生成器（并发安全）
+type BrowserGenerator struct {
+	mu        sync.Mutex
+	rng       *rand.Rand
+	families  []BrowserFamily
+	device    DeviceType
+	languages []string
+}
+
+// NewBrowserGenerator 创建生成器（seed 相同时生成的序列相同，0 使用当前时间）
+func NewBrowserGenerator(seed int64) *BrowserGenerator {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &BrowserGenerator{
+		rng:       rand.New(rand.NewSource(seed)),
+		languages: []string{"en-US"},
+	}
+}
+
+// SetFamilies 限定浏览器类型（默认全部）
+func (g *BrowserGenerator) SetFamilies(families ...BrowserFamily) *BrowserGenerator {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.families = families
+	return g
+}
+
+// SetDevice 限定设备类型（默认桌面和移动端）
+func (g *BrowserGenerator) SetDevice(device DeviceType) *BrowserGenerator {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.device = device
+	return g
+}
+
+// SetLanguages 设置语言偏好（按优先级，如 "zh-CN", "en-US"）
+func (g *BrowserGenerator) SetLanguages(languages ...string) *BrowserGenerator {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(languages) > 0 {
+		g.languages = languages
+	}
+	return g
+}
+
+// Generate 生成一组浏览器请求头
+func (g *BrowserGenerator) Generate() *BrowserProfile {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var candidates []browserCombo
+	for _, combo := range browserCombos {
+		mobile := combo.platform == PlatformAndroid || combo.platform == PlatformIOS
+		if (g.device == DeviceDesktop && mobile) || (g.device == DeviceMobile && !mobile) {
+			continue
+		}
+		if len(g.families) > 0 && !containsFamily(g.families, combo.family) {
+			continue
+		}
+		candidates = append(candidates, combo)
+	}
+	if len(candidates) == 0 {
+		// 条件无法满足（如 Edge + 移动端），退回桌面 Chrome
+		candidates = []browserCombo{{BrowserChrome, PlatformWindows}}
+	}
+
+	combo := candidates[g.rng.Intn(len(candidates))]
+	switch combo.family {
+	case BrowserFirefox:
+		return g.firefox(combo.platform)
+	case BrowserSafari:
+		return g.safari(combo.platform)
+	default:
+		return g.chromium(combo.family, combo.platform)
+	}
+}
+
+// chromium 生成 Chrome / Edge 请求头
+func (g *BrowserGenerator) chromium(family BrowserFamily, platform BrowserPlatform) *BrowserProfile {
+	major := chromeVersions[0] + g.rng.Intn(chromeVersions[1]-chromeVersions[0]+1)
+	version := fmt.Sprintf("%d.0.0.0", major)
+	mobile := platform == PlatformAndroid
+
+	var osPart string
+	switch platform {
+	case PlatformMacOS:
+		osPart = "Macintosh; Intel Mac OS X 10_15_7"
+	case PlatformLinux:
+		osPart = "X11; Linux x86_64"
+	case PlatformAndroid:
+		// UA 精简后 Android 固定为 "Android 10; K"
+		osPart = "Linux; Android 10; K"
+	default:
+		osPart = "Windows NT 10.0; Win64; x64"
+	}
+
+	ua := fmt.Sprintf("Mozilla/5.0 (%s) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/%s ", osPart, version)
+	if mobile {
+		ua += "Mobile "
+	}
+	ua += "Safari/537.36"
+
+	brand := "Google Chrome"
+	if family == BrowserEdge {
+		brand = "Microsoft Edge"
+		ua += " Edg/" + version
+	}
+
+	return &BrowserProfile{
+		Family:          family,
+		Platform:        platform,
+		Mobile:          mobile,
+		Version:         version,
+		UserAgent:       ua,
+		SecChUa:         secChUa(brand, major),
+		SecChUaMobile:   boolHint(mobile),
+		SecChUaPlatform: `"` + string(platform) + `"`,
+		Accept:          "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7",
+		AcceptLanguage:  acceptLanguage(g.languages, false),
+		AcceptEncoding:  "gzip, deflate, br, zstd",
+	}
+}
+
+// firefox 生成 Firefox 请求头（Firefox 不发送 Client Hints）
+func (g *BrowserGenerator) firefox(platform BrowserPlatform) *BrowserProfile {
+	major := firefoxVersions[0] + g.rng.Intn(firefoxVersions[1]-firefoxVersions[0]+1)
+	version := fmt.Sprintf("%d.0", major)
+
+	var ua string
+	switch platform {
+	case PlatformMacOS:
+		ua = fmt.Sprintf("Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:%s) Gecko/20100101 Firefox/%s", version, version)
+	case PlatformLinux:
+		ua = fmt.Sprintf("Mozilla/5.0 (X11; Linux x86_64; rv:%s) Gecko/20100101 Firefox/%s", version, version)
+	case PlatformAndroid:
+		android := androidVersions[g.rng.Intn(len(androidVersions))]
+		ua = fmt.Sprintf("Mozilla/5.0 (Android %d; Mobile; rv:%s) Gecko/%s Firefox/%s", android, version, version, version)
+	default:
+		ua = fmt.Sprintf("Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:%s) Gecko/20100101 Firefox/%s", version, version)
+	}
+
+	return &BrowserProfile{
+		Family:         BrowserFirefox,
+		Platform:       platform,
+		Mobile:         platform == PlatformAndroid,
+		Version:        version,
+		UserAgent:      ua,
+		Accept:         "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+		AcceptLanguage: acceptLanguage(g.languages, true),
+		AcceptEncoding: "gzip, deflate, br, zstd",
+	}
+}
+
+// safari 生成 Safari 请求头（iOS 版本与 Safari 版本一致）
+func (g *BrowserGenerator) safari(platform BrowserPlatform) *BrowserProfile {
+	version := safariVersions[g.rng.Intn(len(safariVersions))]
+
+	var ua string
+	if platform == PlatformIOS {
+		iosVersion := strings.ReplaceAll(version, ".", "_")
+		ua = fmt.Sprintf("Mozilla/5.0 (iPhone; CPU iPhone OS %s like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/%s Mobile/15E148 Safari/604.1", iosVersion, version)
+	} else {
+		ua = fmt.Sprintf("Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/%s Safari/605.1.15", version)
+	}
+
+	return &BrowserProfile{
+		Family:         BrowserSafari,
+		Platform:       platform,
+		Mobile:         platform == PlatformIOS,
+		Version:        version,
+		UserAgent:      ua,
+		Accept:         "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+		AcceptLanguage: acceptLanguage(g.languages, false),
+		AcceptEncoding: "gzip, deflate, br",
+	}
+}
+
+// secChUa 按 Chromium 的 GREASE 算法生成品牌列表（品牌顺序和干扰项随主版本号变化）
+func secChUa(brand string, major int) string {
+	greaseyChars := []string{" ", "(", ":", "-", ".", "/", ")", ";", "=", "?", "_"}
+	greasedVersions := []string{"8", "99", "24"}
+	orders := [][3]int{{0, 1, 2}, {0, 2, 1}, {1, 0, 2}, {1, 2, 0}, {2, 0, 1}, {2, 1, 0}}
+
+	grease := fmt.Sprintf(`"Not%sA%sBrand";v="%s"`,
+		greaseyChars[major%len(greaseyChars)],
+		greaseyChars[(major+1)%len(greaseyChars)],
+		greasedVersions[major%len(greasedVersions)])
+
+	order := orders[major%len(orders)]
+	list := make([]string, 3)
+	list[order[0]] = grease
+	list[order[1]] = fmt.Sprintf(`"Chromium";v="%d"`, major)
+	list[order[2]] = fmt.Sprintf(`"%s";v="%d"`, brand, major)
+	return strings.Join(list, ", ")
+}
+
+// boolHint Client Hints 布尔值
+func boolHint(b bool) string {
+	if b {
+		return "?1"
+	}
+	return "?0"
+}
+
+// acceptLanguage 生成 Accept-Language（"zh-CN" 后补充 "zh"）
+// Chromium、Safari 的 q 值从 0.9 每次递减 0.1；Firefox 按 1-i/n 均匀递减
+func acceptLanguage(languages []string, firefox bool) string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, lang := range languages {
+		for _, tag := range []string{lang, strings.SplitN(lang, "-", 2)[0]} {
+			if tag != "" && !seen[strings.ToLower(tag)] {
+				seen[strings.ToLower(tag)] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	parts := make([]string, len(tags))
+	for i, tag := range tags {
+		if i == 0 {
+			parts[i] = tag
+			continue
+		}
+		q := 1 - float64(i)/10
+		if firefox {
+			q = math.Round((1-float64(i)/float64(len(tags)))*10) / 10
+		}
+		parts[i] = fmt.Sprintf("%s;q=%.1f", tag, math.Max(q, 0.1))
+	}
+	return strings.Join(parts, ",")
+}
+
+// containsFamily 是否包含浏览器类型
+func containsFamily(families []BrowserFamily, family BrowserFamily) bool {
+	for _, f := range families {
+		if f == family {
+			return true
+		}
+	}
+	return false
+}
+
+// SetBrowser 使用一组浏览器请求头（替换之前的 User-Agent、Client Hints 等）
+func (c *Client) SetBrowser(profile *BrowserProfile) *Client {
+	for _, key := range browserHeaderKeys {
+		delete(c.headers, key)
+	}
+	for k, v := range profile.Headers() {
+		c.headers[normalizeHeaderKey(k)] = v
+	}
+	return c
+}
+
+// RotateBrowser 每个请求使用生成器生成新的浏览器请求头（nil 关闭轮换）
+// 轮换的请求头覆盖客户端默认请求头，单个请求设置的请求头优先
+func (c *Client) RotateBrowser(gen *BrowserGenerator) *Client {
+	c.browserRotation = gen
+	return c
+}
+
+// RandomBrowser 随机生成一组浏览器请求头并使用（固定整个会话）
+func (c *Client) RandomBrowser(device DeviceType) *Client {
+	return c.SetBrowser(NewBrowserGenerator(0).SetDevice(device).Generate())
+}
@@ -0,0 +1,52 @@
+package httpclient
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRotateBrowserClearsStaleHeaders(t *testing.T) {
+	client, mock := NewMockClient()
+	route := mock.On("GET", "*").Reply(200, "ok")
+
+	// 默认请求头来自 Chrome，轮换只生成 Firefox
+	chrome := NewBrowserGenerator(1).SetFamilies(BrowserChrome).Generate()
+	client.SetBrowser(chrome).AddHeader("X-App", "demo")
+	client.RotateBrowser(NewBrowserGenerator(1).SetFamilies(BrowserFirefox))
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		ua      string
+	}{
+		{"轮换覆盖默认浏览器头", nil, ""},
+		{"请求级请求头优先", map[string]string{"User-Agent": "custom/1.0"}, "custom/1.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := client.Get("http://api.test/", &Options{Headers: tt.headers}); err != nil {
+				t.Fatal(err)
+			}
+			header := route.LastCall().Header
+			for _, key := range []string{"Sec-Ch-Ua", "Sec-Ch-Ua-Mobile", "Sec-Ch-Ua-Platform"} {
+				if v := header.Get(key); v != "" {
+					t.Errorf("Firefox 请求带有残留的 %s: %s", key, v)
+				}
+			}
+			ua := header.Get("User-Agent")
+			if tt.ua != "" && ua != tt.ua || tt.ua == "" && !strings.Contains(ua, "Firefox") {
+				t.Errorf("User-Agent = %s", ua)
+			}
+			if header.Get("X-App") != "demo" {
+				t.Error("清除了非浏览器的默认请求头")
+			}
+		})
+	}
+
+	// 关闭轮换后恢复默认请求头
+	client.RotateBrowser(nil)
+	client.Get("http://api.test/", nil)
+	if header := route.LastCall().Header; header.Get("Sec-Ch-Ua") != chrome.SecChUa || header.Get("User-Agent") != chrome.UserAgent {
+		t.Errorf("关闭轮换后请求头 = %v", header)
+	}
+}
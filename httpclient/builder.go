@@ -0,0 +1,362 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Request 请求构建器（链式调用）
+// 用法: client.R().SetQuery("a", "1").SetBearer(token).Send("GET", "/users/{id}")
+type Request struct {
+	client         *Client
+	ctx            context.Context
+	baseURL        string
+	query          []queryParam
+	pathParams     map[string]string
+	headers        map[string]string
+	cookies        map[string]string
+	body           interface{}
+	timeout        time.Duration
+	allowRedirects *bool
+	compression    Compression
+	maxBodySize    int64
+	discardBody    bool
+	result         interface{} // 成功响应(2xx)的解析目标
+	errorResult    interface{} // 失败响应的解析目标
+}
+
+// queryParam 查询参数（保持添加顺序）
+type queryParam struct {
+	key   string
+	value string
+}
+
+// R 创建请求构建器
+func (c *Client) R() *Request {
+	return &Request{
+		client:     c,
+		pathParams: make(map[string]string),
+		headers:    make(map[string]string),
+		cookies:    make(map[string]string),
+	}
+}
+
+// SetContext 设置请求上下文
+func (r *Request) SetContext(ctx context.Context) *Request {
+	r.ctx = ctx
+	return r
+}
+
+// SetBaseURL 设置基础URL（Send 传入相对路径时拼接，覆盖客户端的基础URL）
+func (r *Request) SetBaseURL(baseURL string) *Request {
+	r.baseURL = baseURL
+	return r
+}
+
+// SetQuery 设置查询参数（覆盖同名参数）
+func (r *Request) SetQuery(key, value string) *Request {
+	r.DelQuery(key)
+	r.query = append(r.query, queryParam{key: key, value: value})
+	return r
+}
+
+// AddQuery 添加查询参数（同名参数可重复，如 a=1&a=2）
+func (r *Request) AddQuery(key string, values ...string) *Request {
+	for _, v := range values {
+		r.query = append(r.query, queryParam{key: key, value: v})
+	}
+	return r
+}
+
+// SetQueryParams 批量设置查询参数（覆盖同名参数）
+func (r *Request) SetQueryParams(params map[string]string) *Request {
+	for k, v := range params {
+		r.SetQuery(k, v)
+	}
+	return r
+}
+
+// SetQueryValues 批量添加多值查询参数
+func (r *Request) SetQueryValues(values url.Values) *Request {
+	for k, vs := range values {
+		r.DelQuery(k)
+		r.AddQuery(k, vs...)
+	}
+	return r
+}
+
+// DelQuery 删除查询参数
+func (r *Request) DelQuery(key string) *Request {
+	kept := r.query[:0]
+	for _, p := range r.query {
+		if p.key != key {
+			kept = append(kept, p)
+		}
+	}
+	r.query = kept
+	return r
+}
+
+// SetPathParam 设置路径参数，替换URL中的 {name}
+func (r *Request) SetPathParam(name, value string) *Request {
+	r.pathParams[name] = value
+	return r
+}
+
+// SetPathParams 批量设置路径参数
+func (r *Request) SetPathParams(params map[string]string) *Request {
+	for k, v := range params {
+		r.pathParams[k] = v
+	}
+	return r
+}
+
+// SetHeader 设置请求头
+func (r *Request) SetHeader(key, value string) *Request {
+	r.headers[normalizeHeaderKey(key)] = value
+	return r
+}
+
+// SetHeaders 批量设置请求头
+func (r *Request) SetHeaders(headers map[string]string) *Request {
+	for k, v := range headers {
+		r.SetHeader(k, v)
+	}
+	return r
+}
+
+// SetBrowser 使用一组浏览器请求头
+func (r *Request) SetBrowser(profile *BrowserProfile) *Request {
+	return r.SetHeaders(profile.Headers())
+}
+
+// SetCookie 设置Cookie
+func (r *Request) SetCookie(name, value string) *Request {
+	r.cookies[name] = value
+	return r
+}
+
+// SetCookies 批量设置Cookie
+func (r *Request) SetCookies(cookies map[string]string) *Request {
+	for k, v := range cookies {
+		r.cookies[k] = v
+	}
+	return r
+}
+
+// SetBearer 设置 Bearer Token
+func (r *Request) SetBearer(token string) *Request {
+	return r.SetHeader("Authorization", "Bearer "+token)
+}
+
+// SetBasicAuth 设置 Basic 认证
+func (r *Request) SetBasicAuth(username, password string) *Request {
+	auth := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	return r.SetHeader("Authorization", "Basic "+auth)
+}
+
+// SetBody 设置请求体（[]byte、string、io.Reader 原样发送，其他类型按 Content-Type 编码，默认JSON）
+func (r *Request) SetBody(body interface{}) *Request {
+	r.body = body
+	return r
+}
+
+// SetJSON 设置JSON请求体
+func (r *Request) SetJSON(data interface{}) *Request {
+	r.body = data
+	return r.SetHeader("Content-Type", "application/json")
+}
+
+// SetForm 设置表单请求体
+func (r *Request) SetForm(data url.Values) *Request {
+	r.body = data.Encode()
+	return r.SetHeader("Content-Type", "application/x-www-form-urlencoded")
+}
+
+// SetXML 设置XML请求体
+func (r *Request) SetXML(data interface{}) *Request {
+	r.body = data
+	return r.SetHeader("Content-Type", ContentTypeXML)
+}
+
+// SetMsgpack 设置MessagePack请求体
+func (r *Request) SetMsgpack(data interface{}) *Request {
+	r.body = data
+	return r.SetHeader("Content-Type", ContentTypeMsgpack)
+}
+
+// SetProtobuf 设置Protobuf请求体（data 必须实现 proto.Message）
+func (r *Request) SetProtobuf(data interface{}) *Request {
+	r.body = data
+	return r.SetHeader("Content-Type", ContentTypeProtobuf)
+}
+
+// SetFormStruct 设置表单请求体（结构体字段名使用 form 标签）
+func (r *Request) SetFormStruct(data interface{}) *Request {
+	r.body = data
+	return r.SetHeader("Content-Type", ContentTypeForm)
+}
+
+// SetTimeout 设置超时时间
+func (r *Request) SetTimeout(timeout time.Duration) *Request {
+	r.timeout = timeout
+	return r
+}
+
+// SetAllowRedirects 设置是否允许重定向
+func (r *Request) SetAllowRedirects(allow bool) *Request {
+	r.allowRedirects = &allow
+	return r
+}
+
+// SetCompression 设置请求体压缩（覆盖客户端设置，CompressIdentity 不压缩）
+func (r *Request) SetCompression(algo Compression) *Request {
+	r.compression = algo
+	return r
+}
+
+// SetMaxBodySize 设置响应体大小限制（覆盖客户端设置，-1 不限制）
+func (r *Request) SetMaxBodySize(size int64) *Request {
+	r.maxBodySize = size
+	return r
+}
+
+// SetDiscardBody 设置不读取响应体（只需要状态码和响应头时使用）
+func (r *Request) SetDiscardBody(discard bool) *Request {
+	r.discardBody = discard
+	return r
+}
+
+// SetResult 设置成功响应(2xx)的解析目标（按响应 Content-Type 解码）
+func (r *Request) SetResult(v interface{}) *Request {
+	r.result = v
+	return r
+}
+
+// SetError 设置失败响应(非2xx)的解析目标
+func (r *Request) SetError(v interface{}) *Request {
+	r.errorResult = v
+	return r
+}
+
+// Get 发送GET请求
+func (r *Request) Get(urlStr string) (*Response, error) {
+	return r.Send("GET", urlStr)
+}
+
+// Post 发送POST请求
+func (r *Request) Post(urlStr string) (*Response, error) {
+	return r.Send("POST", urlStr)
+}
+
+// Put 发送PUT请求
+func (r *Request) Put(urlStr string) (*Response, error) {
+	return r.Send("PUT", urlStr)
+}
+
+// Delete 发送DELETE请求
+func (r *Request) Delete(urlStr string) (*Response, error) {
+	return r.Send("DELETE", urlStr)
+}
+
+// Patch 发送PATCH请求
+func (r *Request) Patch(urlStr string) (*Response, error) {
+	return r.Send("PATCH", urlStr)
+}
+
+// Head 发送HEAD请求
+func (r *Request) Head(urlStr string) (*Response, error) {
+	return r.Send("HEAD", urlStr)
+}
+
+// Send 发送请求
+func (r *Request) Send(method, urlStr string) (*Response, error) {
+	fullURL, err := r.buildURL(urlStr)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.client.doRequest(strings.ToUpper(method), fullURL, r.body, &Options{
+		Headers:        r.headers,
+		Cookies:        r.cookies,
+		Timeout:        r.timeout,
+		AllowRedirects: r.allowRedirects,
+		Context:        r.ctx,
+		Compression:    r.compression,
+		MaxBodySize:    r.maxBodySize,
+		DiscardBody:    r.discardBody,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 解析结果
+	if resp.IsSuccess() {
+		if r.result != nil && len(resp.Body) > 0 {
+			if err := resp.Decode(r.result); err != nil {
+				return resp, fmt.Errorf("解析响应失败: %w", err)
+			}
+		}
+	} else if r.errorResult != nil && len(resp.Body) > 0 {
+		// 错误响应格式不固定，解析失败时忽略
+		resp.Decode(r.errorResult)
+	}
+
+	return resp, nil
+}
+
+// buildURL 拼接基础URL、替换路径参数、追加查询参数
+func (r *Request) buildURL(urlStr string) (string, error) {
+	for name, value := range r.pathParams {
+		urlStr = strings.ReplaceAll(urlStr, "{"+name+"}", url.PathEscape(value))
+	}
+
+	if r.baseURL != "" {
+		urlStr = joinURL(r.baseURL, urlStr)
+	} else {
+		urlStr = r.client.resolveURL(urlStr)
+	}
+
+	if len(r.query) == 0 {
+		return urlStr, nil
+	}
+
+	parsedURL, err := url.Parse(urlStr)
+	if err != nil {
+		return "", fmt.Errorf("解析URL失败: %w", err)
+	}
+
+	// 按添加顺序追加到已有查询参数之后
+	var sb strings.Builder
+	sb.WriteString(parsedURL.RawQuery)
+	for _, p := range r.query {
+		if sb.Len() > 0 {
+			sb.WriteByte('&')
+		}
+		sb.WriteString(url.QueryEscape(p.key))
+		sb.WriteByte('=')
+		sb.WriteString(url.QueryEscape(p.value))
+	}
+	parsedURL.RawQuery = sb.String()
+
+	return parsedURL.String(), nil
+}
+
+// joinURL 拼接基础URL和路径（路径为完整URL时直接返回）
+// 例如 "https://api.com/v1" + "/users" => "https://api.com/v1/users"
+func joinURL(baseURL, path string) string {
+	if path == "" {
+		return baseURL
+	}
+	if u, err := url.Parse(path); err == nil && u.IsAbs() {
+		return path
+	}
+	if strings.HasPrefix(path, "?") {
+		return baseURL + path
+	}
+	return strings.TrimRight(baseURL, "/") + "/" + strings.TrimLeft(path, "/")
+}
@@ -0,0 +1,112 @@
+package httpclient
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestRequestBuildURL(t *testing.T) {
+	client := New().SetBaseURL("https://api.test/v1/")
+
+	tests := []struct {
+		name  string
+		build func(r *Request) *Request
+		path  string
+		want  string
+	}{
+		{"拼接基础URL", func(r *Request) *Request { return r }, "/users", "https://api.test/v1/users"},
+		{"完整URL不拼接", func(r *Request) *Request { return r }, "https://other.test/x", "https://other.test/x"},
+		{"请求级基础URL", func(r *Request) *Request { return r.SetBaseURL("http://b.test") }, "users", "http://b.test/users"},
+		{"路径参数转义", func(r *Request) *Request {
+			return r.SetPathParams(map[string]string{"id": "a b/c", "kind": "中文"})
+		}, "/users/{id}/{kind}", "https://api.test/v1/users/a%20b%2Fc/%E4%B8%AD%E6%96%87"},
+		{"查询参数保持顺序", func(r *Request) *Request {
+			return r.SetQuery("b", "2").SetQuery("a", "1").AddQuery("b", "3")
+		}, "/q", "https://api.test/v1/q?b=2&a=1&b=3"},
+		{"SetQuery 覆盖同名", func(r *Request) *Request {
+			return r.AddQuery("a", "1", "2").SetQuery("a", "3")
+		}, "/q", "https://api.test/v1/q?a=3"},
+		{"查询参数转义", func(r *Request) *Request {
+			return r.SetQuery("q", "a&b=c d+e").SetQuery("名", "值")
+		}, "/q", "https://api.test/v1/q?q=a%26b%3Dc+d%2Be&%E5%90%8D=%E5%80%BC"},
+		{"追加到已有查询参数之后", func(r *Request) *Request { return r.AddQuery("page", "2") }, "/q?sort=desc", "https://api.test/v1/q?sort=desc&page=2"},
+		{"删除查询参数", func(r *Request) *Request {
+			return r.AddQuery("a", "1").AddQuery("b", "2").DelQuery("a")
+		}, "/q", "https://api.test/v1/q?b=2"},
+		{"多值查询参数", func(r *Request) *Request {
+			return r.AddQuery("tag", "x").SetQueryValues(url.Values{"tag": {"go", "http"}})
+		}, "/q", "https://api.test/v1/q?tag=go&tag=http"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.build(client.R()).buildURL(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("buildURL = %s\nwant      %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJoinURL(t *testing.T) {
+	tests := []struct{ base, path, want string }{
+		{"https://a.test/v1", "/users", "https://a.test/v1/users"},
+		{"https://a.test/v1/", "users", "https://a.test/v1/users"},
+		{"https://a.test/v1", "", "https://a.test/v1"},
+		{"https://a.test/v1", "?x=1", "https://a.test/v1?x=1"},
+		{"https://a.test/v1", "wss://b.test/ws", "wss://b.test/ws"},
+	}
+	for _, tt := range tests {
+		if got := joinURL(tt.base, tt.path); got != tt.want {
+			t.Errorf("joinURL(%q, %q) = %q, want %q", tt.base, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestRequestSend(t *testing.T) {
+	client, mock := NewMockClient()
+	client.SetBaseURL("https://api.test")
+	route := mock.On("POST", "/users/42").ReplyJSON(201, map[string]interface{}{"id": 42, "name": "bob"})
+	mock.On("GET", "/missing").ReplyJSON(404, map[string]string{"error": "not found"})
+
+	var result struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	resp, err := client.R().
+		SetPathParam("id", "42").
+		SetHeader("x-trace", "t1").
+		SetCookie("sid", "s1").
+		SetBearer("tok").
+		SetJSON(map[string]string{"name": "bob"}).
+		SetResult(&result).
+		Post("/users/{id}")
+	if err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	if resp.StatusCode != 201 || result.ID != 42 || result.Name != "bob" {
+		t.Errorf("result = %+v", result)
+	}
+
+	call := route.LastCall()
+	if call == nil {
+		t.Fatal("请求未发送")
+	}
+	header := call.Header
+	if header.Get("X-Trace") != "t1" || header.Get("Authorization") != "Bearer tok" || header.Get("Cookie") != "sid=s1" {
+		t.Errorf("请求头 = %v", header)
+	}
+	if string(call.Body) != `{"name":"bob"}` || header.Get("Content-Type") != "application/json" {
+		t.Errorf("请求体 = %s (%s)", call.Body, header.Get("Content-Type"))
+	}
+
+	var apiErr struct {
+		Error string `json:"error"`
+	}
+	resp, err = client.R().SetResult(&result).SetError(&apiErr).Get("/missing")
+	if err != nil || resp.StatusCode != 404 || apiErr.Error != "not found" {
+		t.Errorf("错误响应 = %v, %v, %+v", resp, err, apiErr)
+	}
+}
@@ -0,0 +1,589 @@
+package httpclient
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheStatus 响应的缓存状态
+type CacheStatus string
+
+const (
+	CacheNone        CacheStatus = ""            // 未启用缓存或请求不可缓存
+	CacheMiss        CacheStatus = "MISS"        // 未命中，从网络获取
+	CacheHit         CacheStatus = "HIT"         // 命中，直接返回缓存
+	CacheRevalidated CacheStatus = "REVALIDATED" // 缓存已过期，经服务器确认(304)后返回缓存
+)
+
+// CacheMode 缓存模式
+type CacheMode int
+
+const (
+	CacheModeDefault CacheMode = iota // 遵循 RFC 9111 缓存语义
+	CacheModeForce                    // 强制缓存：忽略响应头，缓存所有成功的GET并始终返回缓存（开发调试用）
+)
+
+// CachedResponse 缓存的响应
+type CachedResponse struct {
+	URL          string            `json:"url"`
+	StatusCode   int               `json:"status_code"`
+	Status       string            `json:"status"`
+	Proto        string            `json:"proto"`
+	Header       http.Header       `json:"header"`
+	Body         []byte            `json:"body"`
+	Vary         map[string]string `json:"vary,omitempty"` // Vary 指定的请求头取值
+	RequestTime  time.Time         `json:"request_time"`   // 发出请求的时间
+	ResponseTime time.Time         `json:"response_time"`  // 收到响应的时间
+}
+
+// CacheStorage 缓存存储接口
+type CacheStorage interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, resp *CachedResponse)
+	Delete(key string)
+}
+
+// SetCache 设置响应缓存（nil 表示关闭缓存，设置了认证器 SetAuth 的客户端不使用缓存）
+// 缓存按 URL 和请求携带的 Cookie 区分；WithSession/Clone 创建的会话不继承缓存，MemoryCache 会为会话新建同样大小的缓存
+func (c *Client) SetCache(storage CacheStorage) *Client {
+	c.cache = storage
+	c.cacheShared = false
+	return c
+}
+
+// SetSharedCache 设置多个客户端共享的响应缓存（如多个账号共用一个 DiskCache）
+// 共享缓存不保存 Cache-Control: private 的响应
+func (c *Client) SetSharedCache(storage CacheStorage) *Client {
+	c.cache = storage
+	c.cacheShared = true
+	return c
+}
+
+// sessionCache 会话使用的缓存：MemoryCache 新建同样大小的缓存，共享缓存继续共享，其他存储不继承
+func (c *Client) sessionCache() CacheStorage {
+	if c.cacheShared {
+		return c.cache
+	}
+	if m, ok := c.cache.(*MemoryCache); ok {
+		return NewMemoryCache(m.maxEntries)
+	}
+	return nil
+}
+
+// SetCacheMode 设置缓存模式
+func (c *Client) SetCacheMode(mode CacheMode) *Client {
+	c.cacheMode = mode
+	return c
+}
+
+// ==================== 内存缓存 ====================
+
+// MemoryCache 内存LRU缓存
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+}
+
+// memoryCacheItem LRU链表节点
+type memoryCacheItem struct {
+	key  string
+	resp *CachedResponse
+}
+
+// NewMemoryCache 创建内存LRU缓存
+// maxEntries: 最大缓存条目数（<=0 时默认1000）
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = 1000
+	}
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+// Get 获取缓存
+func (m *MemoryCache) Get(key string) (*CachedResponse, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if elem, ok := m.items[key]; ok {
+		m.ll.MoveToFront(elem)
+		return elem.Value.(*memoryCacheItem).resp, true
+	}
+	return nil, false
+}
+
+// Set 写入缓存（超出容量时淘汰最久未使用的条目）
+func (m *MemoryCache) Set(key string, resp *CachedResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if elem, ok := m.items[key]; ok {
+		m.ll.MoveToFront(elem)
+		elem.Value.(*memoryCacheItem).resp = resp
+		return
+	}
+	m.items[key] = m.ll.PushFront(&memoryCacheItem{key: key, resp: resp})
+	for m.ll.Len() > m.maxEntries {
+		oldest := m.ll.Back()
+		m.ll.Remove(oldest)
+		delete(m.items, oldest.Value.(*memoryCacheItem).key)
+	}
+}
+
+// Delete 删除缓存
+func (m *MemoryCache) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if elem, ok := m.items[key]; ok {
+		m.ll.Remove(elem)
+		delete(m.items, key)
+	}
+}
+
+// Len 获取缓存条目数
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ll.Len()
+}
+
+// ==================== 磁盘缓存 ====================
+
+// DiskCache 磁盘缓存（每个条目一个JSON文件）
+type DiskCache struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewDiskCache 创建磁盘缓存
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+// path 获取缓存文件路径
+func (d *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get 获取缓存
+func (d *DiskCache) Get(key string) (*CachedResponse, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	data, err := os.ReadFile(d.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var resp CachedResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, false
+	}
+	return &resp, true
+}
+
+// Set 写入缓存
+func (d *DiskCache) Set(key string, resp *CachedResponse) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	// 先写临时文件再重命名，避免读到写了一半的文件
+	tmp := d.path(key) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return
+	}
+	os.Rename(tmp, d.path(key))
+}
+
+// Delete 删除缓存
+func (d *DiskCache) Delete(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	os.Remove(d.path(key))
+}
+
+// Clear 清空磁盘缓存
+func (d *DiskCache) Clear() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	files, err := filepath.Glob(filepath.Join(d.dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		os.Remove(f)
+	}
+	return nil
+}
+
+// ==================== 缓存语义 ====================
+
+// 默认可缓存的状态码（RFC 9110 15.1）
+var cacheableStatus = map[int]bool{
+	200: true, 203: true, 204: true, 300: true, 301: true, 308: true,
+	404: true, 405: true, 410: true, 414: true, 501: true,
+}
+
+// cacheKey 缓存key：URL，请求带 Cookie（含 cookiejar 中的）时加上 Cookie 的摘要，不同账号互不命中
+func (c *Client) cacheKey(req *http.Request) string {
+	cookies := make([]string, 0)
+	for _, cookie := range req.Cookies() {
+		cookies = append(cookies, cookie.Name+"="+cookie.Value)
+	}
+	if c.httpClient.Jar != nil {
+		for _, cookie := range c.httpClient.Jar.Cookies(req.URL) {
+			cookies = append(cookies, cookie.Name+"="+cookie.Value)
+		}
+	}
+	key := urlCacheKey(req)
+	if len(cookies) > 0 {
+		sort.Strings(cookies)
+		sum := sha256.Sum256([]byte(strings.Join(cookies, "; ")))
+		key += " cookie:" + hex.EncodeToString(sum[:16])
+	}
+	return key
+}
+
+// urlCacheKey 不区分 Cookie 的缓存key
+func urlCacheKey(req *http.Request) string {
+	return "GET " + req.URL.String()
+}
+
+// parseCacheControl 解析 Cache-Control 头
+func parseCacheControl(header http.Header) map[string]string {
+	result := make(map[string]string)
+	for _, line := range header.Values("Cache-Control") {
+		for _, part := range strings.Split(line, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			if idx := strings.Index(part, "="); idx > 0 {
+				result[strings.ToLower(part[:idx])] = strings.Trim(part[idx+1:], `"`)
+			} else {
+				result[strings.ToLower(part)] = ""
+			}
+		}
+	}
+	return result
+}
+
+// ccSeconds 获取 Cache-Control 中的秒数
+func ccSeconds(cc map[string]string, name string) (time.Duration, bool) {
+	v, ok := cc[name]
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return time.Duration(n) * time.Second, true
+}
+
+// freshnessLifetime 计算新鲜期（RFC 9111 4.2.1）
+func (r *CachedResponse) freshnessLifetime() time.Duration {
+	cc := parseCacheControl(r.Header)
+	if maxAge, ok := ccSeconds(cc, "max-age"); ok {
+		return maxAge
+	}
+
+	date := r.date()
+	if expires := r.Header.Get("Expires"); expires != "" {
+		t, err := http.ParseTime(expires)
+		if err != nil {
+			return 0 // 无效的 Expires 视为已过期
+		}
+		return t.Sub(date)
+	}
+
+	// 启发式新鲜期：Last-Modified 距今时间的10%
+	if lm := r.Header.Get("Last-Modified"); lm != "" {
+		if t, err := http.ParseTime(lm); err == nil && date.After(t) {
+			return date.Sub(t) / 10
+		}
+	}
+	return 0
+}
+
+// date 获取响应的 Date（缺失时使用收到响应的时间）
+func (r *CachedResponse) date() time.Time {
+	if t, err := http.ParseTime(r.Header.Get("Date")); err == nil {
+		return t
+	}
+	return r.ResponseTime
+}
+
+// age 计算当前年龄（RFC 9111 4.2.3）
+func (r *CachedResponse) age(now time.Time) time.Duration {
+	apparentAge := r.ResponseTime.Sub(r.date())
+	if apparentAge < 0 {
+		apparentAge = 0
+	}
+	ageValue := time.Duration(0)
+	if n, err := strconv.ParseInt(r.Header.Get("Age"), 10, 64); err == nil && n > 0 {
+		ageValue = time.Duration(n) * time.Second
+	}
+	responseDelay := r.ResponseTime.Sub(r.RequestTime)
+	correctedAge := ageValue + responseDelay
+	if apparentAge > correctedAge {
+		correctedAge = apparentAge
+	}
+	return correctedAge + now.Sub(r.ResponseTime)
+}
+
+// isFresh 判断缓存是否可直接使用（考虑请求的 Cache-Control）
+func (r *CachedResponse) isFresh(req *http.Request, now time.Time) bool {
+	respCC := parseCacheControl(r.Header)
+	reqCC := parseCacheControl(req.Header)
+
+	if _, ok := respCC["no-cache"]; ok {
+		return false
+	}
+	if _, ok := reqCC["no-cache"]; ok {
+		return false
+	}
+	if req.Header.Get("Pragma") == "no-cache" && req.Header.Get("Cache-Control") == "" {
+		return false
+	}
+
+	lifetime := r.freshnessLifetime()
+	age := r.age(now)
+
+	if maxAge, ok := ccSeconds(reqCC, "max-age"); ok && maxAge < lifetime {
+		lifetime = maxAge
+	}
+	if minFresh, ok := ccSeconds(reqCC, "min-fresh"); ok {
+		age += minFresh
+	}
+	if age < lifetime {
+		return true
+	}
+
+	// 请求允许使用过期缓存（除非响应要求必须重新验证）
+	if _, ok := respCC["must-revalidate"]; ok {
+		return false
+	}
+	if v, ok := reqCC["max-stale"]; ok {
+		if v == "" {
+			return true
+		}
+		if maxStale, ok := ccSeconds(reqCC, "max-stale"); ok {
+			return age < lifetime+maxStale
+		}
+	}
+	return false
+}
+
+// matchVary 检查请求是否匹配缓存的 Vary 头
+func (r *CachedResponse) matchVary(req *http.Request) bool {
+	for name, value := range r.Vary {
+		if req.Header.Get(name) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// hasValidator 是否有可用于重新验证的校验器
+func (r *CachedResponse) hasValidator() bool {
+	return r.Header.Get("ETag") != "" || r.Header.Get("Last-Modified") != ""
+}
+
+// varyHeaders 解析响应的 Vary 头，返回请求中对应的取值（Vary: * 返回 false）
+func varyHeaders(req *http.Request, header http.Header) (map[string]string, bool) {
+	result := make(map[string]string)
+	for _, line := range header.Values("Vary") {
+		for _, name := range strings.Split(line, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if name == "*" {
+				return nil, false
+			}
+			result[http.CanonicalHeaderKey(name)] = req.Header.Get(name)
+		}
+	}
+	return result, true
+}
+
+// isStorable 判断响应是否可以写入缓存（RFC 9111 3）
+func (c *Client) isStorable(req *http.Request, resp *http.Response) bool {
+	if req.Method != http.MethodGet {
+		return false
+	}
+	if c.cacheMode == CacheModeForce {
+		return resp.StatusCode >= 200 && resp.StatusCode < 300
+	}
+	if !cacheableStatus[resp.StatusCode] {
+		return false
+	}
+	reqCC := parseCacheControl(req.Header)
+	respCC := parseCacheControl(resp.Header)
+	if _, ok := reqCC["no-store"]; ok {
+		return false
+	}
+	if _, ok := respCC["no-store"]; ok {
+		return false
+	}
+	// 共享缓存不保存 private 响应（RFC 9111 5.2.2.7）
+	if _, private := respCC["private"]; private && c.cacheShared {
+		return false
+	}
+	// 带 Authorization 的请求只有响应明确允许共享时才缓存（RFC 9111 3.5）
+	if req.Header.Get("Authorization") != "" {
+		_, public := respCC["public"]
+		_, mustRevalidate := respCC["must-revalidate"]
+		_, sMaxAge := respCC["s-maxage"]
+		if !public && !mustRevalidate && !sMaxAge {
+			return false
+		}
+	}
+	// 需要有显式过期时间或校验器，否则缓存没有意义
+	_, hasMaxAge := respCC["max-age"]
+	return hasMaxAge ||
+		resp.Header.Get("Expires") != "" ||
+		resp.Header.Get("ETag") != "" ||
+		resp.Header.Get("Last-Modified") != ""
+}
+
+// cacheLookup 查询缓存
+// 命中新鲜缓存时返回缓存响应；缓存过期但有校验器时添加条件请求头并返回旧缓存
+// 设置了认证器时不使用缓存：认证在限流之后才执行，查询时还不知道认证身份
+// key 为本次请求的缓存key（发送前计算，响应下发的 Cookie 不影响写入位置）
+func (c *Client) cacheLookup(req *http.Request) (fresh, stale *CachedResponse, key string) {
+	if c.cache == nil || c.auth != nil || req.Method != http.MethodGet {
+		return nil, nil, ""
+	}
+	key = c.cacheKey(req)
+	entry, ok := c.cache.Get(key)
+	if !ok || !entry.matchVary(req) {
+		return nil, nil, key
+	}
+
+	if c.cacheMode == CacheModeForce || entry.isFresh(req, time.Now()) {
+		return entry, nil, key
+	}
+
+	if entry.hasValidator() {
+		if etag := entry.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lm := entry.Header.Get("Last-Modified"); lm != "" {
+			req.Header.Set("If-Modified-Since", lm)
+		}
+		return nil, entry, key
+	}
+	return nil, nil, key
+}
+
+// cacheUpdate 处理网络响应：304 合并缓存、可缓存响应写入缓存、非安全方法使缓存失效
+// 返回最终使用的响应、响应体和缓存状态；缓存中的响应体与返回的互不共享
+func (c *Client) cacheUpdate(req *http.Request, key string, resp *http.Response, body []byte, stale *CachedResponse, requestTime time.Time) (*http.Response, []byte, CacheStatus) {
+	if c.cache == nil {
+		return resp, body, CacheNone
+	}
+
+	// 非安全方法成功后使对应的缓存失效（RFC 9111 4.4）
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		if resp.StatusCode < 400 {
+			c.cache.Delete(urlCacheKey(req))
+			c.cache.Delete(c.cacheKey(req))
+		}
+		return resp, body, CacheNone
+	}
+	if req.Method != http.MethodGet || c.auth != nil || key == "" {
+		return resp, body, CacheNone
+	}
+	// 发生了重定向：响应属于最后一跳的地址，不能按原地址缓存
+	if resp.Request != nil && resp.Request.URL.String() != req.URL.String() {
+		return resp, body, CacheMiss
+	}
+
+	now := time.Now()
+
+	// 304：使用新的响应头更新缓存（RFC 9111 4.3.4）
+	if resp.StatusCode == http.StatusNotModified && stale != nil {
+		updated := *stale
+		updated.Header = stale.Header.Clone()
+		for k, v := range resp.Header {
+			if k == "Content-Length" || k == "Set-Cookie" {
+				continue
+			}
+			updated.Header[k] = v
+		}
+		updated.RequestTime = requestTime
+		updated.ResponseTime = now
+		c.cache.Set(key, &updated)
+		return updated.httpResponse(req, resp.Header.Values("Set-Cookie")), cloneBytes(updated.Body), CacheRevalidated
+	}
+
+	if c.isStorable(req, resp) {
+		vary, ok := varyHeaders(req, resp.Header)
+		if ok || c.cacheMode == CacheModeForce {
+			header := resp.Header.Clone()
+			header.Del("Set-Cookie")
+			// 响应体已解压，去掉相关头避免再次解压
+			header.Del("Content-Encoding")
+			header.Del("Content-Length")
+			c.cache.Set(key, &CachedResponse{
+				URL:          req.URL.String(),
+				StatusCode:   resp.StatusCode,
+				Status:       resp.Status,
+				Proto:        resp.Proto,
+				Header:       header,
+				Body:         cloneBytes(body),
+				Vary:         vary,
+				RequestTime:  requestTime,
+				ResponseTime: now,
+			})
+		}
+	} else if stale != nil {
+		c.cache.Delete(key)
+	}
+
+	return resp, body, CacheMiss
+}
+
+// httpResponse 将缓存转换为 http.Response
+// setCookies 为需要保留的 Set-Cookie（例如304响应中携带的）
+func (r *CachedResponse) httpResponse(req *http.Request, setCookies []string) *http.Response {
+	header := r.Header.Clone()
+	for _, v := range setCookies {
+		header.Add("Set-Cookie", v)
+	}
+	return &http.Response{
+		Status:     r.Status,
+		StatusCode: r.StatusCode,
+		Proto:      r.Proto,
+		Header:     header,
+		Request:    req,
+	}
+}
+
+// cloneBytes 复制字节切片（调用方修改响应体不影响缓存）
+func cloneBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append(make([]byte, 0, len(b)), b...)
+}
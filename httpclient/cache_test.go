@@ -0,0 +1,179 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// newCacheTestServer 返回 "<path> <Cookie>" 并记录每个路径的请求次数
+func newCacheTestServer(t *testing.T) (*httptest.Server, func(path string) int) {
+	t.Helper()
+	var mu sync.Mutex
+	hits := make(map[string]int)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.URL.Path]++
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/etag":
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Cache-Control", "no-cache")
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/private":
+			w.Header().Set("Cache-Control", "private, max-age=60")
+		case "/redirect":
+			http.Redirect(w, r, "/final", http.StatusFound)
+			return
+		default:
+			w.Header().Set("Cache-Control", "max-age=60")
+		}
+		w.Write([]byte(r.URL.Path + " " + r.Header.Get("Cookie")))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func(path string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return hits[path]
+	}
+}
+
+func TestCacheFreshAndRevalidate(t *testing.T) {
+	srv, hits := newCacheTestServer(t)
+	client := New().SetCache(NewMemoryCache(0))
+
+	tests := []struct {
+		path   string
+		status CacheStatus
+		hits   int
+	}{
+		{"/max-age", CacheMiss, 1},
+		{"/max-age", CacheHit, 1},
+		{"/etag", CacheMiss, 1},
+		{"/etag", CacheRevalidated, 2},
+	}
+	for _, tt := range tests {
+		resp, err := client.Get(srv.URL+tt.path, nil)
+		if err != nil {
+			t.Fatalf("Get %s: %v", tt.path, err)
+		}
+		if resp.CacheStatus != tt.status || hits(tt.path) != tt.hits || resp.Text() != tt.path+" " {
+			t.Errorf("%s: status=%q hits=%d body=%q, want %q %d", tt.path, resp.CacheStatus, hits(tt.path), resp.Text(), tt.status, tt.hits)
+		}
+	}
+}
+
+func TestCacheKeyedByCookie(t *testing.T) {
+	srv, hits := newCacheTestServer(t)
+	client := New().SetCache(NewMemoryCache(0))
+
+	client.AddCookie("sid", "alice")
+	alice, _ := client.Get(srv.URL+"/profile", nil)
+	client.AddCookie("sid", "bob")
+	bob, _ := client.Get(srv.URL+"/profile", nil)
+
+	if hits("/profile") != 2 || bob.CacheStatus != CacheMiss || bob.Text() != "/profile sid=bob" {
+		t.Fatalf("换 Cookie 后命中了其他账号的缓存: hits=%d body=%q", hits("/profile"), bob.Text())
+	}
+	if alice.Text() != "/profile sid=alice" {
+		t.Errorf("alice = %q", alice.Text())
+	}
+
+	client.AddCookie("sid", "alice")
+	if resp, _ := client.Get(srv.URL+"/profile", nil); resp.CacheStatus != CacheHit || resp.Text() != "/profile sid=alice" {
+		t.Errorf("同一 Cookie 未命中缓存: %q %q", resp.CacheStatus, resp.Text())
+	}
+}
+
+func TestCacheSessions(t *testing.T) {
+	srv, hits := newCacheTestServer(t)
+	client := New().SetCache(NewMemoryCache(10))
+	client.Get(srv.URL+"/shared", nil)
+
+	// 会话使用独立的内存缓存
+	session := client.WithSession()
+	if session.cache == nil || session.cache == client.cache {
+		t.Fatalf("会话缓存 = %v", session.cache)
+	}
+	if resp, _ := session.Get(srv.URL+"/shared", nil); resp.CacheStatus != CacheMiss || hits("/shared") != 2 {
+		t.Errorf("会话命中了原客户端的缓存: %q", resp.CacheStatus)
+	}
+	if clone := client.Clone(); clone.cache == client.cache {
+		t.Error("Clone 共享了缓存")
+	}
+
+	// 其他存储不继承
+	diskCache, err := NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if New().SetCache(diskCache).WithSession().cache != nil {
+		t.Error("会话继承了非共享的磁盘缓存")
+	}
+
+	// 共享缓存不保存 private 响应
+	shared := NewMemoryCache(10)
+	a := New().SetSharedCache(shared)
+	b := a.WithSession()
+	if b.cache != shared {
+		t.Fatal("共享缓存未被会话继承")
+	}
+	a.Get(srv.URL+"/private", nil)
+	a.Get(srv.URL+"/public", nil)
+	if resp, _ := b.Get(srv.URL+"/private", nil); resp.CacheStatus != CacheMiss || hits("/private") != 2 {
+		t.Errorf("共享缓存保存了 private 响应: %q", resp.CacheStatus)
+	}
+	if resp, _ := b.Get(srv.URL+"/public", nil); resp.CacheStatus != CacheHit {
+		t.Errorf("共享缓存未命中公共响应: %q", resp.CacheStatus)
+	}
+
+	// 私有缓存可以保存 private 响应
+	own := New().SetCache(NewMemoryCache(10))
+	own.Get(srv.URL+"/private", nil)
+	if resp, _ := own.Get(srv.URL+"/private", nil); resp.CacheStatus != CacheHit {
+		t.Errorf("私有缓存未保存 private 响应: %q", resp.CacheStatus)
+	}
+}
+
+func TestCacheBodyIsolation(t *testing.T) {
+	srv, _ := newCacheTestServer(t)
+	client := New().SetCache(NewMemoryCache(0))
+
+	miss, _ := client.Get(srv.URL+"/body", nil)
+	miss.Body[0] = 'X'
+	hit, _ := client.Get(srv.URL+"/body", nil)
+	if hit.CacheStatus != CacheHit || hit.Text() != "/body " {
+		t.Fatalf("修改未命中的响应体影响了缓存: %q", hit.Text())
+	}
+	hit.Body[0] = 'Y'
+	if again, _ := client.Get(srv.URL+"/body", nil); again.Text() != "/body " {
+		t.Errorf("修改命中的响应体影响了缓存: %q", again.Text())
+	}
+}
+
+func TestCacheSkipsRedirectedResponse(t *testing.T) {
+	srv, hits := newCacheTestServer(t)
+	client := New().SetCache(NewMemoryCache(0))
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(srv.URL+"/redirect", nil)
+		if err != nil || resp.Text() != "/final " {
+			t.Fatalf("Get = %v, %v", resp, err)
+		}
+		if resp.CacheStatus == CacheHit {
+			t.Error("重定向后的响应按原地址缓存")
+		}
+	}
+	if hits("/redirect") != 2 {
+		t.Errorf("/redirect hits = %d, want 2", hits("/redirect"))
+	}
+	// 最后一跳的地址本身仍可缓存
+	client.Get(srv.URL+"/final", nil)
+	if resp, _ := client.Get(srv.URL+"/final", nil); resp.CacheStatus != CacheHit {
+		t.Errorf("/final = %q", resp.CacheStatus)
+	}
+}
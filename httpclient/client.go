@@ -8,6 +8,7 @@ import (
 	"net/http/cookiejar"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/net/proxy"
@@ -15,16 +16,34 @@ import (
 
 // Client HTTP客户端
 type Client struct {
-	httpClient   *http.Client
-	transport    *http.Transport
-	headers      map[string]string
-	cookies      map[string]string
-	timeout      time.Duration
-	maxRedirects int
-	verify       bool
-	proxyURL     string
-	proxyType    string // "http" 或 "socks5"
-	jar          *cookiejar.Jar
+	httpClient      *http.Client
+	transport       *http.Transport
+	headers         map[string]string
+	cookies         map[string]string
+	cookieMu        sync.RWMutex // 保护 cookies（SSE 在后台重连时也会写入）
+	timeout         time.Duration
+	maxRedirects    int
+	verify          bool
+	proxyURL        string
+	proxyType       string // "http" 或 "socks5"
+	jar             *cookiejar.Jar
+	harRecorder     *HARRecorder      // HAR录制器（可选）
+	logHook         LogHookFn         // 日志钩子（可选）
+	rateLimiter     *rateLimiter      // 限流器
+	cache           CacheStorage      // 响应缓存（可选）
+	cacheShared     bool              // 缓存是否由多个客户端共享
+	cacheMode       CacheMode         // 缓存模式
+	auth            Authenticator     // 认证器（可选）
+	baseURL         string            // 基础URL
+	transportOwner  *Client           // 共享 Transport 的原客户端（WithSession），nil 表示使用自己的 Transport
+	codecs          map[string]Codec  // 编解码器（按媒体类型）
+	bodyCodec       string            // 默认请求体编码
+	compression     Compression       // 请求体压缩算法
+	compressMinSize int               // 请求体压缩阈值（字节）
+	maxBodySize     int64             // 响应体大小限制（字节，0 不限制）
+	browserRotation *BrowserGenerator // 浏览器请求头轮换（可选）
+	dns             *dnsConfig        // DNS覆盖和自定义解析
+	roundTripper    http.RoundTripper // 自定义传输层（可选，如 MockTransport）
 }
 
 // New 创建新的HTTP客户端
@@ -39,6 +58,10 @@ func New() *Client {
 		verify:       true,
 		jar:          jar,
 		proxyType:    "",
+		rateLimiter:  newRateLimiter(),
+		dns:          newDNSConfig(),
+		codecs:       defaultCodecs(),
+		bodyCodec:    ContentTypeJSON,
 	}
 
 	// 创建 Transport，使用动态代理函数
@@ -51,6 +74,7 @@ func New() *Client {
 		ExpectContinueTimeout: 1 * time.Second,
 		DisableKeepAlives:     false,
 		Proxy:                 c.getProxyFunc(), // 动态代理函数
+		DialContext:           c.dialContext,    // 应用DNS覆盖和自定义解析
 	}
 
 	c.httpClient = &http.Client{
@@ -68,6 +92,10 @@ func (c *Client) getProxyFunc() func(*http.Request) (*url.URL, error) {
 		if c.proxyURL == "" || c.proxyType == "socks5" {
 			return nil, nil
 		}
+		// 目标域名需要自行解析时由 dialContext 建立 CONNECT 隧道，其他请求正常经代理转发
+		if c.dns.handles(requestAddr(req.URL)) {
+			return nil, nil
+		}
 		return url.Parse(c.proxyURL)
 	}
 }
@@ -117,6 +145,7 @@ func NewWithConfig(cfg Config) *Client {
 // proxyStr: 代理地址，格式: "ip:port" 或 "ip:port:user:pass" 或完整URL
 // proxyType: 代理类型 "http" 或 "socks5"
 func (c *Client) SetProxy(proxyStr string, proxyType string) *Client {
+	c.ownTransport()
 	oldProxyType := c.proxyType
 
 	if proxyStr == "" {
@@ -216,41 +245,92 @@ func (c *Client) rebuildTransport() {
 		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
 	}
 
-	// 代理配置
+	c.transport = transport
+	c.httpClient.Transport = c.activeTransport()
+
+	// 代理配置（SOCKS5 使用自定义 DialContext，HTTP 代理使用动态 Proxy 函数）
+	c.bindTransport()
+}
+
+// bindTransport 将 Transport 的代理和拨号函数绑定到当前客户端（复制 Transport 后也需要调用）
+func (c *Client) bindTransport() {
 	if c.proxyType == "socks5" && c.proxyURL != "" {
-		// SOCKS5 代理需要自定义 DialContext
-		proxyURL, err := url.Parse(c.proxyURL)
-		if err == nil {
-			var auth *proxy.Auth
-			if proxyURL.User != nil {
-				auth = &proxy.Auth{
-					User: proxyURL.User.Username(),
-				}
-				auth.Password, _ = proxyURL.User.Password()
-			}
+		c.transport.Proxy = nil
+		c.transport.DialContext = c.socks5DialContext()
+		return
+	}
+	c.transport.Proxy = c.getProxyFunc()
+	c.transport.DialContext = c.dialContext
+}
 
-			dialer, err := proxy.SOCKS5("tcp", proxyURL.Host, auth, proxy.Direct)
-			if err == nil {
-				transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
-					return dialer.Dial(network, addr)
-				}
-			}
+// socks5DialContext 创建 SOCKS5 拨号函数（代理地址无效时返回 nil）
+func (c *Client) socks5DialContext() func(ctx context.Context, network, addr string) (net.Conn, error) {
+	proxyURL, err := url.Parse(c.proxyURL)
+	if err != nil {
+		return nil
+	}
+	var auth *proxy.Auth
+	if proxyURL.User != nil {
+		auth = &proxy.Auth{
+			User: proxyURL.User.Username(),
 		}
-	} else {
-		// HTTP 代理使用动态 Proxy 函数
-		transport.Proxy = c.getProxyFunc()
+		auth.Password, _ = proxyURL.User.Password()
 	}
 
-	c.transport = transport
-	c.httpClient.Transport = transport
+	dialer, err := proxy.SOCKS5("tcp", proxyURL.Host, auth, proxy.Direct)
+	if err != nil {
+		return nil
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		done := traceProxyDial(ctx)
+		defer done()
+		// 配置了DNS覆盖或解析器时，把解析后的IP交给代理
+		return c.dialResolvedVia(ctx, addr, func(ctx context.Context, addr string) (net.Conn, error) {
+			if cd, ok := dialer.(proxy.ContextDialer); ok {
+				return cd.DialContext(ctx, network, addr)
+			}
+			return dialer.Dial(network, addr)
+		})
+	}
+}
+
+// dialContext 直连和HTTP代理的拨号函数（应用DNS覆盖和自定义解析）
+// HTTP代理且目标域名需要自行解析时 getProxyFunc 不返回代理，由这里建立 CONNECT 隧道，
+// 使目标域名也使用本地解析结果；连接代理本身时 addr 是代理地址，直接拨号
+func (c *Client) dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if c.proxyURL != "" && c.proxyType != "socks5" && c.dns.handles(addr) && !c.isProxyAddr(addr) {
+		done := traceProxyDial(ctx)
+		defer done()
+		return c.dialResolvedVia(ctx, addr, c.dialHTTPConnect)
+	}
+	return c.dialResolved(ctx, network, addr)
+}
+
+// isProxyAddr addr 是否为HTTP代理自身的地址
+func (c *Client) isProxyAddr(addr string) bool {
+	u, err := url.Parse(c.proxyURL)
+	return err == nil && strings.EqualFold(requestAddr(u), addr)
+}
+
+// requestAddr URL 对应的 host:port（省略端口时按协议补全）
+func requestAddr(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" || u.Scheme == "wss" {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
 }
 
 // SetVerify 设置是否验证SSL证书
 func (c *Client) SetVerify(verify bool) *Client {
-	if c.verify == verify {
+	if c.transportClient().verify == verify {
 		return c // 没有变化，不需要更新
 	}
 	c.verify = verify
+	c.ownTransport()
 
 	// 更新 TLS 配置
 	if c.transport.TLSClientConfig == nil {
@@ -323,6 +403,8 @@ func normalizeHeaderKey(key string) string {
 
 // SetCookies 设置Cookie（覆盖）
 func (c *Client) SetCookies(cookies map[string]string) *Client {
+	c.cookieMu.Lock()
+	defer c.cookieMu.Unlock()
 	c.cookies = make(map[string]string)
 	for k, v := range cookies {
 		c.cookies[k] = v
@@ -332,12 +414,16 @@ func (c *Client) SetCookies(cookies map[string]string) *Client {
 
 // AddCookie 添加单个Cookie
 func (c *Client) AddCookie(name, value string) *Client {
+	c.cookieMu.Lock()
+	defer c.cookieMu.Unlock()
 	c.cookies[name] = value
 	return c
 }
 
 // UpdateCookies 更新Cookie
 func (c *Client) UpdateCookies(cookies interface{}) *Client {
+	c.cookieMu.Lock()
+	defer c.cookieMu.Unlock()
 	switch v := cookies.(type) {
 	case string:
 		// 解析Cookie字符串
@@ -359,6 +445,8 @@ func (c *Client) UpdateCookies(cookies interface{}) *Client {
 
 // GetCookies 获取当前所有Cookie
 func (c *Client) GetCookies() map[string]string {
+	c.cookieMu.RLock()
+	defer c.cookieMu.RUnlock()
 	result := make(map[string]string)
 	for k, v := range c.cookies {
 		result[k] = v
@@ -377,7 +465,9 @@ func (c *Client) GetHeaders() map[string]string {
 
 // ClearCookies 清空Cookie
 func (c *Client) ClearCookies() *Client {
+	c.cookieMu.Lock()
 	c.cookies = make(map[string]string)
+	c.cookieMu.Unlock()
 	jar, _ := cookiejar.New(nil)
 	c.jar = jar
 	c.httpClient.Jar = jar
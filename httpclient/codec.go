@@ -0,0 +1,489 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+// 常用 Content-Type
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeXML      = "application/xml"
+	ContentTypeForm     = "application/x-www-form-urlencoded"
+	ContentTypeProtobuf = "application/x-protobuf"
+	ContentTypeMsgpack  = "application/msgpack"
+)
+
+// ErrNoCodec 没有与 Content-Type 匹配的编解码器
+var ErrNoCodec = errors.New("没有匹配的编解码器")
+
+// Codec 请求体/响应体编解码器
+type Codec interface {
+	ContentType() string                        // 编码后的 Content-Type
+	Marshal(v interface{}) ([]byte, error)      // 编码请求体
+	Unmarshal(data []byte, v interface{}) error // 解码响应体
+}
+
+// JSONCodec JSON编解码器
+type JSONCodec struct{}
+
+func (JSONCodec) ContentType() string                        { return ContentTypeJSON }
+func (JSONCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+// XMLCodec XML编解码器
+type XMLCodec struct{}
+
+func (XMLCodec) ContentType() string                        { return ContentTypeXML }
+func (XMLCodec) Marshal(v interface{}) ([]byte, error)      { return xml.Marshal(v) }
+func (XMLCodec) Unmarshal(data []byte, v interface{}) error { return xml.Unmarshal(data, v) }
+
+// MsgpackCodec MessagePack编解码器（字段名使用 msgpack 标签）
+type MsgpackCodec struct{}
+
+func (MsgpackCodec) ContentType() string                        { return ContentTypeMsgpack }
+func (MsgpackCodec) Marshal(v interface{}) ([]byte, error)      { return msgpack.Marshal(v) }
+func (MsgpackCodec) Unmarshal(data []byte, v interface{}) error { return msgpack.Unmarshal(data, v) }
+
+// ProtobufCodec Protobuf编解码器（值必须实现 proto.Message）
+type ProtobufCodec struct{}
+
+func (ProtobufCodec) ContentType() string { return ContentTypeProtobuf }
+
+func (ProtobufCodec) Marshal(v interface{}) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%T 未实现 proto.Message", v)
+	}
+	return proto.Marshal(msg)
+}
+
+func (ProtobufCodec) Unmarshal(data []byte, v interface{}) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("%T 未实现 proto.Message", v)
+	}
+	return proto.Unmarshal(data, msg)
+}
+
+// FormCodec URL编码表单编解码器
+// 支持 url.Values、map[string]string 和结构体（字段名使用 form 标签，如 `form:"name,omitempty"`，"-" 忽略）
+type FormCodec struct{}
+
+func (FormCodec) ContentType() string { return ContentTypeForm }
+
+func (FormCodec) Marshal(v interface{}) ([]byte, error) {
+	values, err := encodeForm(v)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(values.Encode()), nil
+}
+
+func (FormCodec) Unmarshal(data []byte, v interface{}) error {
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return err
+	}
+	return decodeForm(values, v)
+}
+
+// defaultCodecs 内置编解码器（按媒体类型）
+func defaultCodecs() map[string]Codec {
+	return map[string]Codec{
+		ContentTypeJSON:         JSONCodec{},
+		"text/json":             JSONCodec{},
+		ContentTypeXML:          XMLCodec{},
+		"text/xml":              XMLCodec{},
+		ContentTypeForm:         FormCodec{},
+		ContentTypeProtobuf:     ProtobufCodec{},
+		"application/protobuf":  ProtobufCodec{},
+		ContentTypeMsgpack:      MsgpackCodec{},
+		"application/x-msgpack": MsgpackCodec{},
+	}
+}
+
+// RegisterCodec 注册编解码器（默认按 codec.ContentType()，可额外指定多个 Content-Type）
+func (c *Client) RegisterCodec(codec Codec, contentTypes ...string) *Client {
+	if len(contentTypes) == 0 {
+		contentTypes = []string{codec.ContentType()}
+	}
+	for _, ct := range contentTypes {
+		c.codecs[mediaType(ct)] = codec
+	}
+	return c
+}
+
+// SetBodyCodec 设置默认请求体编码（非 []byte/string/io.Reader 且未指定 Content-Type 时使用，默认JSON）
+func (c *Client) SetBodyCodec(contentType string) *Client {
+	c.bodyCodec = mediaType(contentType)
+	return c
+}
+
+// GetCodec 获取 Content-Type 对应的编解码器（+json、+xml 后缀按JSON、XML处理）
+func (c *Client) GetCodec(contentType string) (Codec, bool) {
+	return lookupCodec(c.codecs, contentType)
+}
+
+// encodeBody 编码请求体，返回编码结果和 Content-Type
+// contentType 为请求中已指定的 Content-Type，为空时使用默认编码，没有匹配的编解码器时返回 ErrNoCodec
+func (c *Client) encodeBody(v interface{}, contentType string) ([]byte, string, error) {
+	if contentType == "" {
+		contentType = c.bodyCodec
+	}
+	codec, ok := c.GetCodec(contentType)
+	if !ok {
+		return nil, "", fmt.Errorf("%w: %s", ErrNoCodec, contentType)
+	}
+	data, err := codec.Marshal(v)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, codec.ContentType(), nil
+}
+
+// lookupCodec 按媒体类型查找编解码器
+func lookupCodec(codecs map[string]Codec, contentType string) (Codec, bool) {
+	mt := mediaType(contentType)
+	if codec, ok := codecs[mt]; ok {
+		return codec, true
+	}
+	switch {
+	case strings.HasSuffix(mt, "+json"):
+		return codecs[ContentTypeJSON], codecs[ContentTypeJSON] != nil
+	case strings.HasSuffix(mt, "+xml"):
+		return codecs[ContentTypeXML], codecs[ContentTypeXML] != nil
+	}
+	return nil, false
+}
+
+// mediaType 去掉 Content-Type 中的参数（如 charset）
+func mediaType(contentType string) string {
+	if mt, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mt
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+// headerValue 不区分大小写读取 map 中的请求头
+func headerValue(headers map[string]string, key string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return ""
+}
+
+// encodeForm 将 url.Values、map 或结构体编码为表单
+func encodeForm(v interface{}) (url.Values, error) {
+	switch data := v.(type) {
+	case url.Values:
+		return data, nil
+	case map[string]string:
+		values := make(url.Values)
+		for k, s := range data {
+			values.Set(k, s)
+		}
+		return values, nil
+	case map[string][]string:
+		return url.Values(data), nil
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return url.Values{}, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("表单编码不支持 %T", v)
+	}
+
+	values := make(url.Values)
+	if err := encodeFormStruct(rv, values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// encodeFormStruct 编码结构体字段（匿名嵌入的结构体字段展开）
+func encodeFormStruct(rv reflect.Value, values url.Values) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		fv := rv.Field(i)
+
+		name, omitempty, skip := formFieldName(field)
+		if skip {
+			continue
+		}
+		if field.Anonymous && field.Tag.Get("form") == "" {
+			for fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					break
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				if err := encodeFormStruct(fv, values); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if omitempty && fv.IsZero() {
+			continue
+		}
+
+		for fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				break
+			}
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Ptr {
+			continue
+		}
+
+		if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 || fv.Kind() == reflect.Array {
+			for j := 0; j < fv.Len(); j++ {
+				s, err := formatFormValue(fv.Index(j))
+				if err != nil {
+					return fmt.Errorf("字段 %s: %w", field.Name, err)
+				}
+				values.Add(name, s)
+			}
+			continue
+		}
+
+		s, err := formatFormValue(fv)
+		if err != nil {
+			return fmt.Errorf("字段 %s: %w", field.Name, err)
+		}
+		values.Add(name, s)
+	}
+	return nil
+}
+
+// formFieldName 解析 form 标签，返回字段名、是否 omitempty、是否忽略
+func formFieldName(field reflect.StructField) (name string, omitempty bool, skip bool) {
+	if !field.IsExported() && !field.Anonymous {
+		return "", false, true
+	}
+	tag := field.Tag.Get("form")
+	if tag == "-" {
+		return "", false, true
+	}
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return name, omitempty, false
+}
+
+// formatFormValue 将基础类型格式化为字符串
+func formatFormValue(v reflect.Value) (string, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		return t.Format(time.RFC3339), nil
+	}
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String(), nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes()), nil
+		}
+	}
+	return "", fmt.Errorf("不支持的类型 %s", v.Type())
+}
+
+// decodeForm 将表单解码到 url.Values、map 或结构体指针
+func decodeForm(values url.Values, v interface{}) error {
+	switch data := v.(type) {
+	case *url.Values:
+		*data = values
+		return nil
+	case *map[string]string:
+		m := make(map[string]string, len(values))
+		for k := range values {
+			m[k] = values.Get(k)
+		}
+		*data = m
+		return nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("表单解码需要结构体指针，得到 %T", v)
+	}
+	_, err := decodeFormStruct(values, rv.Elem())
+	return err
+}
+
+// decodeFormStruct 解码结构体字段（匿名嵌入的结构体字段展开），返回是否有字段被赋值
+func decodeFormStruct(values url.Values, rv reflect.Value) (bool, error) {
+	set := false
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		fv := rv.Field(i)
+
+		name, _, skip := formFieldName(field)
+		if skip {
+			continue
+		}
+		if field.Anonymous && field.Tag.Get("form") == "" {
+			switch {
+			case fv.Kind() == reflect.Struct:
+				ok, err := decodeFormStruct(values, fv)
+				if err != nil {
+					return set, err
+				}
+				set = set || ok
+				continue
+			case fv.Kind() == reflect.Ptr && fv.Type().Elem().Kind() == reflect.Struct:
+				// 嵌入的结构体指针为 nil 时，有字段被赋值才分配（未导出类型无法分配，跳过）
+				elem := fv
+				if fv.IsNil() {
+					if !fv.CanSet() {
+						continue
+					}
+					elem = reflect.New(fv.Type().Elem())
+				}
+				ok, err := decodeFormStruct(values, elem.Elem())
+				if err != nil {
+					return set, err
+				}
+				if ok && fv.IsNil() {
+					fv.Set(elem)
+				}
+				set = set || ok
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		vals, ok := values[name]
+		if !ok || len(vals) == 0 {
+			continue
+		}
+
+		if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+			slice := reflect.MakeSlice(fv.Type(), len(vals), len(vals))
+			for j, s := range vals {
+				if err := parseFormValue(slice.Index(j), s); err != nil {
+					return set, fmt.Errorf("字段 %s: %w", field.Name, err)
+				}
+			}
+			fv.Set(slice)
+			set = true
+			continue
+		}
+
+		if err := parseFormValue(fv, vals[0]); err != nil {
+			return set, fmt.Errorf("字段 %s: %w", field.Name, err)
+		}
+		set = true
+	}
+	return set, nil
+}
+
+// parseFormValue 将字符串解析到基础类型字段
+func parseFormValue(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return parseFormValue(v.Elem(), s)
+	}
+	if v.Type() == reflect.TypeOf(time.Time{}) {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes([]byte(s))
+			return nil
+		}
+		return fmt.Errorf("不支持的类型 %s", v.Type())
+	default:
+		return fmt.Errorf("不支持的类型 %s", v.Type())
+	}
+	return nil
+}
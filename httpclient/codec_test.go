@@ -0,0 +1,120 @@
+package httpclient
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestEncodeBody(t *testing.T) {
+	client := New()
+	body := map[string]string{"name": "bob"}
+
+	tests := []struct {
+		name        string
+		contentType string
+		bodyCodec   string
+		want        string
+		wantType    string
+		err         error
+	}{
+		{"默认JSON", "", "", `{"name":"bob"}`, ContentTypeJSON, nil},
+		{"默认编码改为表单", "", ContentTypeForm, "name=bob", ContentTypeForm, nil},
+		{"指定Content-Type", "application/x-www-form-urlencoded; charset=utf-8", "", "name=bob", ContentTypeForm, nil},
+		{"+json 后缀", "application/vnd.api+json", "", `{"name":"bob"}`, ContentTypeJSON, nil},
+		{"没有匹配的编解码器", "text/csv", "", "", "", ErrNoCodec},
+		{"默认编码没有编解码器", "", "text/csv", "", "", ErrNoCodec},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client.SetBodyCodec(ContentTypeJSON)
+			if tt.bodyCodec != "" {
+				client.SetBodyCodec(tt.bodyCodec)
+			}
+			data, ct, err := client.encodeBody(body, tt.contentType)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if string(data) != tt.want || ct != tt.wantType {
+				t.Errorf("encodeBody = %s (%s), want %s (%s)", data, ct, tt.want, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestPostUnknownContentType(t *testing.T) {
+	client, mock := NewMockClient()
+	route := mock.On("POST", "*").Reply(200, "ok")
+	_, err := client.Post("http://api.test/", map[string]int{"a": 1}, &Options{
+		Headers: map[string]string{"Content-Type": "text/csv"},
+	})
+	if !errors.Is(err, ErrNoCodec) {
+		t.Errorf("err = %v, want ErrNoCodec", err)
+	}
+	if route.CallCount() != 0 {
+		t.Error("没有编解码器时仍按JSON发送了请求")
+	}
+}
+
+type formBase struct {
+	ID int `form:"id"`
+}
+
+type FormPage struct {
+	Page int `form:"page"`
+}
+
+type formQuery struct {
+	*FormPage
+	*formBase
+	Name string   `form:"name"`
+	Tags []string `form:"tag"`
+}
+
+func TestFormEmbeddedPointer(t *testing.T) {
+	tests := []struct {
+		name     string
+		form     string
+		wantPage *FormPage
+	}{
+		{"分配嵌入的结构体指针", "name=bob&page=3&tag=a&tag=b", &FormPage{Page: 3}},
+		{"没有对应字段时保持nil", "name=bob&tag=a&tag=b", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, _ := url.ParseQuery(tt.form)
+			var q formQuery
+			if err := decodeForm(values, &q); err != nil {
+				t.Fatalf("decodeForm: %v", err)
+			}
+			if q.Name != "bob" || len(q.Tags) != 2 {
+				t.Errorf("q = %+v", q)
+			}
+			if (q.FormPage == nil) != (tt.wantPage == nil) || q.FormPage != nil && *q.FormPage != *tt.wantPage {
+				t.Errorf("FormPage = %+v, want %+v", q.FormPage, tt.wantPage)
+			}
+			// 未导出类型的嵌入指针无法分配，跳过
+			if q.formBase != nil {
+				t.Errorf("formBase = %+v", q.formBase)
+			}
+		})
+	}
+
+	// 已分配的嵌入指针直接写入
+	q := formQuery{FormPage: &FormPage{}, formBase: &formBase{}}
+	if err := decodeForm(url.Values{"page": {"2"}, "id": {"7"}}, &q); err != nil {
+		t.Fatal(err)
+	}
+	if q.Page != 2 || q.ID != 7 {
+		t.Errorf("q = %+v %+v", q.FormPage, q.formBase)
+	}
+
+	// 编码时同样展开嵌入的结构体指针
+	values, err := encodeForm(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values.Get("page") != "2" || values.Get("id") != "7" {
+		t.Errorf("encodeForm = %v", values)
+	}
+}
@@ -0,0 +1,217 @@
+package httpclient
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression 请求体压缩算法
+type Compression string
+
+const (
+	CompressNone     Compression = ""         // 使用客户端设置
+	CompressIdentity Compression = "identity" // 不压缩（用于单个请求关闭客户端的压缩）
+	CompressGzip     Compression = "gzip"
+	CompressZstd     Compression = "zstd"
+)
+
+// ErrBodyNotReplayable 流式请求体已发送，无法在重新认证或 307/308 重定向时重放
+var ErrBodyNotReplayable = errors.New("流式请求体无法重放")
+
+// streamReplayLimit 流式请求体不超过该大小时读入内存整体压缩，支持重放
+const streamReplayLimit = 4 << 20
+
+// SetRequestCompression 设置请求体压缩（minSize 字节以下的请求体不压缩）
+// 已知长度和不超过4MB的 io.Reader 请求体整体压缩，重试和重定向时可重放；
+// 更大的 io.Reader 边读边压缩，需要重放时返回 ErrBodyNotReplayable
+func (c *Client) SetRequestCompression(algo Compression, minSize int) *Client {
+	c.compression = algo
+	c.compressMinSize = minSize
+	return c
+}
+
+// requestCompression 当前请求使用的压缩算法（已手动设置 Content-Encoding 时不压缩）
+func (c *Client) requestCompression(opts *Options) Compression {
+	if headerValue(opts.Headers, "Content-Encoding") != "" || headerValue(c.headers, "Content-Encoding") != "" {
+		return CompressNone
+	}
+	algo := c.compression
+	if opts.Compression != CompressNone {
+		algo = opts.Compression
+	}
+	if algo == CompressIdentity {
+		return CompressNone
+	}
+	return algo
+}
+
+// compressBody 压缩请求体，返回新的请求体和是否已压缩
+func (c *Client) compressBody(body io.Reader, algo Compression) (io.Reader, bool, error) {
+	if algo != CompressGzip && algo != CompressZstd {
+		return nil, false, fmt.Errorf("不支持的压缩算法: %s", algo)
+	}
+
+	// 内存中的请求体整体压缩（保留 GetBody，支持重放）
+	var data []byte
+	switch v := body.(type) {
+	case *bytes.Reader:
+		data = make([]byte, v.Len())
+		v.Read(data)
+	case *strings.Reader:
+		data = make([]byte, v.Len())
+		v.Read(data)
+	case *bytes.Buffer:
+		data = v.Bytes()
+	default:
+		return c.compressStream(body, algo)
+	}
+
+	return c.compressData(data, algo)
+}
+
+// compressData 整体压缩内存中的请求体
+func (c *Client) compressData(data []byte, algo Compression) (io.Reader, bool, error) {
+	if len(data) < c.compressMinSize {
+		return bytes.NewReader(data), false, nil
+	}
+	compressed, err := compressBytes(data, algo)
+	if err != nil {
+		return nil, false, fmt.Errorf("压缩请求体失败: %w", err)
+	}
+	return bytes.NewReader(compressed), true, nil
+}
+
+// compressStream 压缩 io.Reader 请求体
+// 先读取最多 streamReplayLimit 字节，读完的按内存请求体处理，否则边读边压缩
+func (c *Client) compressStream(body io.Reader, algo Compression) (io.Reader, bool, error) {
+	limit := max(streamReplayLimit, c.compressMinSize)
+	head, err := io.ReadAll(io.LimitReader(body, int64(limit)+1))
+	if err != nil {
+		closeBody(body)
+		return nil, false, fmt.Errorf("读取请求体失败: %w", err)
+	}
+	if len(head) <= limit {
+		closeBody(body)
+		return c.compressData(head, algo)
+	}
+
+	reader := io.MultiReader(bytes.NewReader(head), bufio.NewReader(body))
+	return &compressReader{body: body, reader: reader, algo: algo}, true, nil
+}
+
+// compressReader 流式压缩的请求体（首次读取时才启动压缩协程，未发送的请求不会泄漏协程）
+type compressReader struct {
+	body   io.Reader
+	reader io.Reader
+	algo   Compression
+	once   sync.Once
+	pr     *io.PipeReader
+}
+
+func (r *compressReader) start() {
+	pr, pw := io.Pipe()
+	r.pr = pr
+	go func() {
+		defer closeBody(r.body)
+
+		var w io.WriteCloser
+		if r.algo == CompressZstd {
+			zw, err := zstd.NewWriter(pw, zstd.WithEncoderConcurrency(1))
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			w = zw
+		} else {
+			w = gzip.NewWriter(pw)
+		}
+
+		if _, err := io.Copy(w, r.reader); err != nil {
+			w.Close()
+			pw.CloseWithError(err)
+			return
+		}
+		pw.CloseWithError(w.Close())
+	}()
+}
+
+func (r *compressReader) Read(p []byte) (int, error) {
+	r.once.Do(r.start)
+	return r.pr.Read(p)
+}
+
+func (r *compressReader) Close() error {
+	started := true
+	r.once.Do(func() { started = false })
+	if !started {
+		closeBody(r.body)
+		return nil
+	}
+	return r.pr.Close()
+}
+
+// closeBody 关闭可关闭的请求体（与标准库行为一致）
+func closeBody(body io.Reader) {
+	if closer, ok := body.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
+var (
+	zstdEncoderOnce sync.Once
+	zstdEncoder     *zstd.Encoder
+	zstdEncoderErr  error
+)
+
+// decompressBytes 解压字节数据（用于录制压缩后的请求体）
+func decompressBytes(data []byte, algo Compression) ([]byte, error) {
+	switch algo {
+	case CompressZstd:
+		decoder, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		defer decoder.Close()
+		return decoder.DecodeAll(data, nil)
+	case CompressGzip:
+		gr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer gr.Close()
+		return io.ReadAll(gr)
+	default:
+		return nil, fmt.Errorf("不支持的压缩算法: %s", algo)
+	}
+}
+
+// compressBytes 压缩字节数据
+func compressBytes(data []byte, algo Compression) ([]byte, error) {
+	if algo == CompressZstd {
+		zstdEncoderOnce.Do(func() {
+			zstdEncoder, zstdEncoderErr = zstd.NewWriter(nil)
+		})
+		if zstdEncoderErr != nil {
+			return nil, zstdEncoderErr
+		}
+		return zstdEncoder.EncodeAll(data, make([]byte, 0, len(data)/2)), nil
+	}
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := gw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
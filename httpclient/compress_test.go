@@ -0,0 +1,141 @@
+package httpclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// decompressTestBody 按 Content-Encoding 解压请求体
+func decompressTestBody(t *testing.T, encoding string, body []byte) string {
+	t.Helper()
+	switch encoding {
+	case "":
+		return string(body)
+	case "gzip":
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("gzip: %v", err)
+		}
+		data, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("gzip: %v", err)
+		}
+		return string(data)
+	case "zstd":
+		zr, err := zstd.NewReader(bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("zstd: %v", err)
+		}
+		defer zr.Close()
+		data, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("zstd: %v", err)
+		}
+		return string(data)
+	}
+	t.Fatalf("未知的 Content-Encoding: %s", encoding)
+	return ""
+}
+
+func TestRequestCompressionThreshold(t *testing.T) {
+	small := strings.Repeat("a", 99)
+	large := strings.Repeat("a", 100)
+
+	tests := []struct {
+		name     string
+		algo     Compression
+		body     interface{}
+		opts     *Options
+		encoding string
+	}{
+		{"低于阈值不压缩", CompressGzip, small, nil, ""},
+		{"达到阈值压缩", CompressGzip, large, nil, "gzip"},
+		{"zstd", CompressZstd, []byte(large), nil, "zstd"},
+		{"流式请求体", CompressGzip, io.MultiReader(strings.NewReader(large)), nil, "gzip"},
+		{"流式请求体低于阈值", CompressGzip, io.MultiReader(strings.NewReader(small)), nil, ""},
+		{"编码后的结构体", CompressGzip, map[string]string{"k": large}, nil, "gzip"},
+		{"请求级关闭压缩", CompressGzip, large, &Options{Compression: CompressIdentity}, ""},
+		{"请求级开启压缩", CompressNone, large, &Options{Compression: CompressZstd}, "zstd"},
+		{"已指定 Content-Encoding", CompressGzip, large, &Options{Headers: map[string]string{"Content-Encoding": "br"}}, "br"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mock := NewMockClient()
+			route := mock.On("POST", "*").Reply(200, "ok")
+			client.SetRequestCompression(tt.algo, 100)
+
+			if _, err := client.Post("http://api.test/", tt.body, tt.opts); err != nil {
+				t.Fatalf("Post: %v", err)
+			}
+			call := route.LastCall()
+			if got := call.Header.Get("Content-Encoding"); got != tt.encoding {
+				t.Fatalf("Content-Encoding = %q, want %q", got, tt.encoding)
+			}
+			if tt.encoding == "br" {
+				return
+			}
+			data := decompressTestBody(t, tt.encoding, call.Body)
+			if !strings.Contains(data, large[:99]) {
+				t.Errorf("解压后的请求体 = %.40q", data)
+			}
+		})
+	}
+}
+
+func TestRequestCompressionReplay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/echo", http.StatusTemporaryRedirect)
+			return
+		}
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		n, _ := io.Copy(io.Discard, zr)
+		fmt.Fprintf(w, "%s %d", r.Header.Get("Content-Encoding"), n)
+	}))
+	defer srv.Close()
+
+	client := New().SetRequestCompression(CompressGzip, 0)
+	smallStream := strings.Repeat("s", 1<<20)
+	largeStream := strings.Repeat("l", streamReplayLimit+1)
+
+	tests := []struct {
+		name string
+		body interface{}
+		want string
+		err  error
+	}{
+		{"内存请求体可重放", []byte(smallStream), fmt.Sprintf("gzip %d", len(smallStream)), nil},
+		{"不超过4MB的流式请求体可重放", io.MultiReader(strings.NewReader(smallStream)), fmt.Sprintf("gzip %d", len(smallStream)), nil},
+		{"超过4MB的流式请求体不能重放", io.MultiReader(strings.NewReader(largeStream)), "", ErrBodyNotReplayable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.Post(srv.URL+"/redirect", tt.body, nil)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if tt.err == nil && resp.Text() != tt.want {
+				t.Errorf("响应 = %q, want %q", resp.Text(), tt.want)
+			}
+		})
+	}
+
+	// 超过4MB的流式请求体不需要重放时边读边压缩
+	resp, err := client.Post(srv.URL+"/echo", io.MultiReader(strings.NewReader(largeStream)), nil)
+	if err != nil || resp.Text() != fmt.Sprintf("gzip %d", len(largeStream)) {
+		t.Errorf("流式压缩 = %v, %v", resp, err)
+	}
+}
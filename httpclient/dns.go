@@ -0,0 +1,545 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// ErrDNSNoRecord 域名没有 A/AAAA 记录
+var ErrDNSNoRecord = errors.New("没有DNS记录")
+
+// Resolver DNS解析器
+type Resolver interface {
+	// Resolve 解析域名，返回IP列表和缓存时间（TTL）
+	Resolve(ctx context.Context, host string) ([]net.IP, time.Duration, error)
+}
+
+// SystemResolver 系统DNS（无法获取TTL，使用固定缓存时间）
+type SystemResolver struct {
+	TTL time.Duration // 缓存时间（默认60秒）
+}
+
+// Resolve 使用系统DNS解析
+func (r *SystemResolver) Resolve(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, 0, err
+	}
+	ips := make([]net.IP, len(addrs))
+	for i, addr := range addrs {
+		ips[i] = addr.IP
+	}
+	ttl := r.TTL
+	if ttl <= 0 {
+		ttl = 60 * time.Second
+	}
+	return sortIPs(ips), ttl, nil
+}
+
+// UDPResolver 使用指定DNS服务器（UDP，响应被截断时改用TCP）
+type UDPResolver struct {
+	Server  string        // DNS服务器地址（如 "8.8.8.8:53"）
+	Timeout time.Duration // 单次查询超时（默认5秒）
+}
+
+// NewUDPResolver 创建指定DNS服务器的解析器（省略端口时使用53）
+func NewUDPResolver(server string) *UDPResolver {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+	return &UDPResolver{Server: server, Timeout: 5 * time.Second}
+}
+
+// Resolve 查询 A 和 AAAA 记录
+func (r *UDPResolver) Resolve(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+	return resolveBoth(ctx, host, r.exchange)
+}
+
+// exchange 发送一次DNS查询
+func (r *UDPResolver) exchange(ctx context.Context, query []byte) ([]byte, error) {
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resp, err := r.exchangeNet(ctx, "udp", query)
+	if err != nil {
+		return nil, err
+	}
+	// TC 标志：响应被截断，改用TCP重新查询
+	if len(resp) > 2 && resp[2]&0x02 != 0 {
+		return r.exchangeNet(ctx, "tcp", query)
+	}
+	return resp, nil
+}
+
+// exchangeNet 通过UDP或TCP发送查询（TCP 报文带2字节长度前缀）
+func (r *UDPResolver) exchangeNet(ctx context.Context, network string, query []byte) ([]byte, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, network, r.Server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if network == "udp" {
+		if _, err := conn.Write(query); err != nil {
+			return nil, err
+		}
+		buf := make([]byte, 4096)
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	}
+
+	msg := make([]byte, 2+len(query))
+	binary.BigEndian.PutUint16(msg, uint16(len(query)))
+	copy(msg[2:], query)
+	if _, err := conn.Write(msg); err != nil {
+		return nil, err
+	}
+	var length [2]byte
+	if _, err := io.ReadFull(conn, length[:]); err != nil {
+		return nil, err
+	}
+	resp := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DoHResolver DNS over HTTPS 解析器（RFC 8484）
+type DoHResolver struct {
+	URL    string       // 查询地址（如 "https://cloudflare-dns.com/dns-query"）
+	Client *http.Client // 发送查询使用的客户端（默认10秒超时）
+}
+
+// NewDoHResolver 创建 DNS over HTTPS 解析器
+func NewDoHResolver(url string) *DoHResolver {
+	return &DoHResolver{
+		URL:    url,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Resolve 查询 A 和 AAAA 记录
+func (r *DoHResolver) Resolve(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+	return resolveBoth(ctx, host, r.exchange)
+}
+
+// exchange 以 POST application/dns-message 发送查询
+func (r *DoHResolver) exchange(ctx context.Context, query []byte) ([]byte, error) {
+	// RFC 8484 建议 ID 为 0，便于HTTP缓存
+	id := [2]byte{query[0], query[1]}
+	query = append([]byte(nil), query...)
+	query[0], query[1] = 0, 0
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.URL, bytes.NewReader(query))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("DoH查询失败: %s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return nil, err
+	}
+	// 还原查询ID，便于统一校验
+	if len(body) >= 2 {
+		copy(body, id[:])
+	}
+	return body, nil
+}
+
+// resolveBoth 并发查询 A 和 AAAA 记录（IPv4 在前）
+func resolveBoth(ctx context.Context, host string, exchange func(context.Context, []byte) ([]byte, error)) ([]net.IP, time.Duration, error) {
+	types := []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA}
+	type result struct {
+		ips []net.IP
+		ttl time.Duration
+		err error
+	}
+	results := make([]result, len(types))
+
+	var wg sync.WaitGroup
+	for i, qtype := range types {
+		wg.Add(1)
+		go func(i int, qtype dnsmessage.Type) {
+			defer wg.Done()
+			ips, ttl, err := lookup(ctx, host, qtype, exchange)
+			results[i] = result{ips, ttl, err}
+		}(i, qtype)
+	}
+	wg.Wait()
+
+	var ips []net.IP
+	var ttl time.Duration
+	var firstErr error
+	for _, r := range results {
+		if r.err != nil {
+			if firstErr == nil {
+				firstErr = r.err
+			}
+			continue
+		}
+		ips = append(ips, r.ips...)
+		if len(r.ips) > 0 && (ttl == 0 || r.ttl < ttl) {
+			ttl = r.ttl
+		}
+	}
+	if len(ips) == 0 {
+		if firstErr != nil {
+			return nil, 0, fmt.Errorf("解析 %s 失败: %w", host, firstErr)
+		}
+		return nil, 0, fmt.Errorf("解析 %s 失败: %w", host, ErrDNSNoRecord)
+	}
+	return ips, ttl, nil
+}
+
+// lookup 查询一种记录类型
+func lookup(ctx context.Context, host string, qtype dnsmessage.Type, exchange func(context.Context, []byte) ([]byte, error)) ([]net.IP, time.Duration, error) {
+	name, err := dnsmessage.NewName(strings.TrimSuffix(host, ".") + ".")
+	if err != nil {
+		return nil, 0, err
+	}
+	var idBytes [2]byte
+	rand.Read(idBytes[:])
+	id := binary.BigEndian.Uint16(idBytes[:])
+
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{
+			{Name: name, Type: qtype, Class: dnsmessage.ClassINET},
+		},
+	}
+	query, err := msg.Pack()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	resp, err := exchange(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	return parseDNSAnswer(resp, id)
+}
+
+// parseDNSAnswer 解析响应中的 A/AAAA 记录，TTL 取最小值
+func parseDNSAnswer(resp []byte, id uint16) ([]net.IP, time.Duration, error) {
+	var p dnsmessage.Parser
+	header, err := p.Start(resp)
+	if err != nil {
+		return nil, 0, fmt.Errorf("解析DNS响应失败: %w", err)
+	}
+	if header.ID != id {
+		return nil, 0, errors.New("DNS响应ID不匹配")
+	}
+	if header.RCode == dnsmessage.RCodeNameError {
+		return nil, 0, ErrDNSNoRecord
+	}
+	if header.RCode != dnsmessage.RCodeSuccess {
+		return nil, 0, fmt.Errorf("DNS查询失败: %s", header.RCode)
+	}
+	if err := p.SkipAllQuestions(); err != nil {
+		return nil, 0, err
+	}
+
+	var ips []net.IP
+	var minTTL uint32
+	for {
+		ah, err := p.AnswerHeader()
+		if err == dnsmessage.ErrSectionDone {
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		switch ah.Type {
+		case dnsmessage.TypeA:
+			r, err := p.AResource()
+			if err != nil {
+				return nil, 0, err
+			}
+			ips = append(ips, net.IP(r.A[:]))
+		case dnsmessage.TypeAAAA:
+			r, err := p.AAAAResource()
+			if err != nil {
+				return nil, 0, err
+			}
+			ips = append(ips, net.IP(r.AAAA[:]))
+		default:
+			// CNAME 等记录跳过（递归服务器会在同一响应中返回最终地址）
+			if err := p.SkipAnswer(); err != nil {
+				return nil, 0, err
+			}
+			continue
+		}
+		if minTTL == 0 || ah.TTL < minTTL {
+			minTTL = ah.TTL
+		}
+	}
+	return ips, time.Duration(minTTL) * time.Second, nil
+}
+
+// sortIPs IPv4 排在 IPv6 前面
+func sortIPs(ips []net.IP) []net.IP {
+	sorted := make([]net.IP, 0, len(ips))
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			sorted = append(sorted, ip)
+		}
+	}
+	for _, ip := range ips {
+		if ip.To4() == nil {
+			sorted = append(sorted, ip)
+		}
+	}
+	return sorted
+}
+
+// dnsConfig 客户端的DNS覆盖、解析器和缓存
+type dnsConfig struct {
+	mu        sync.RWMutex
+	overrides map[string][]string // host 或 host:port => IP列表
+	resolver  Resolver
+	cache     map[string]dnsCacheEntry
+}
+
+// dnsCacheEntry DNS缓存项
+type dnsCacheEntry struct {
+	ips     []net.IP
+	expires time.Time
+}
+
+func newDNSConfig() *dnsConfig {
+	return &dnsConfig{
+		overrides: make(map[string][]string),
+		cache:     make(map[string]dnsCacheEntry),
+	}
+}
+
+// clone 复制配置（解析器共享，覆盖和缓存各自独立）
+func (d *dnsConfig) clone() *dnsConfig {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	n := &dnsConfig{
+		overrides: make(map[string][]string, len(d.overrides)),
+		resolver:  d.resolver,
+		cache:     make(map[string]dnsCacheEntry, len(d.cache)),
+	}
+	for host, ips := range d.overrides {
+		n.overrides[host] = append([]string(nil), ips...)
+	}
+	for host, entry := range d.cache {
+		n.cache[host] = entry
+	}
+	return n
+}
+
+// handles 该地址（host:port）是否需要自行解析：设置了解析器或有对应的覆盖，IP地址除外
+func (d *dnsConfig) handles(addr string) bool {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || net.ParseIP(host) != nil {
+		return false
+	}
+	host = strings.ToLower(host)
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.resolver != nil {
+		return true
+	}
+	if _, ok := d.overrides[net.JoinHostPort(host, port)]; ok {
+		return true
+	}
+	_, ok := d.overrides[host]
+	return ok
+}
+
+// SetDNSOverride 固定域名解析结果（类似 curl --resolve，host 可带端口只对该端口生效）
+// 覆盖同时作用于直连、HTTP代理和SOCKS5代理连接
+func (c *Client) SetDNSOverride(host string, ips ...string) *Client {
+	c.ownTransport()
+	c.dns.mu.Lock()
+	if len(ips) == 0 {
+		delete(c.dns.overrides, strings.ToLower(host))
+	} else {
+		c.dns.overrides[strings.ToLower(host)] = ips
+	}
+	c.dns.mu.Unlock()
+	c.transport.CloseIdleConnections()
+	return c
+}
+
+// SetDNSOverrides 批量固定域名解析结果
+func (c *Client) SetDNSOverrides(overrides map[string]string) *Client {
+	for host, ip := range overrides {
+		c.SetDNSOverride(host, ip)
+	}
+	return c
+}
+
+// ClearDNSOverrides 清除所有域名覆盖
+func (c *Client) ClearDNSOverrides() *Client {
+	c.ownTransport()
+	c.dns.mu.Lock()
+	c.dns.overrides = make(map[string][]string)
+	c.dns.mu.Unlock()
+	c.transport.CloseIdleConnections()
+	return c
+}
+
+// SetResolver 设置DNS解析器（nil 恢复默认），结果按TTL缓存
+// 设置后HTTP代理连接改为 CONNECT 隧道，使目标域名也使用本地解析结果（只有覆盖时仅对覆盖的域名建立隧道）
+func (c *Client) SetResolver(resolver Resolver) *Client {
+	c.ownTransport()
+	c.dns.mu.Lock()
+	c.dns.resolver = resolver
+	c.dns.cache = make(map[string]dnsCacheEntry)
+	c.dns.mu.Unlock()
+	c.transport.CloseIdleConnections()
+	return c
+}
+
+// ClearDNSCache 清空DNS缓存
+func (c *Client) ClearDNSCache() *Client {
+	c.dns.mu.Lock()
+	c.dns.cache = make(map[string]dnsCacheEntry)
+	c.dns.mu.Unlock()
+	return c
+}
+
+// resolveAddr 将 host:port 解析为 ip:port 列表（未配置覆盖和解析器时原样返回）
+func (c *Client) resolveAddr(ctx context.Context, addr string) ([]string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || net.ParseIP(host) != nil {
+		return []string{addr}, nil
+	}
+	host = strings.ToLower(host)
+
+	c.dns.mu.RLock()
+	ips, ok := c.dns.overrides[net.JoinHostPort(host, port)]
+	if !ok {
+		ips, ok = c.dns.overrides[host]
+	}
+	resolver := c.dns.resolver
+	entry, cached := c.dns.cache[host]
+	c.dns.mu.RUnlock()
+
+	if ok {
+		return joinAddrs(ips, port), nil
+	}
+	if resolver == nil {
+		return []string{addr}, nil
+	}
+	if cached && time.Now().Before(entry.expires) {
+		return joinIPs(entry.ips, port), nil
+	}
+
+	// 触发 httptrace DNS 回调，使耗时统计包含自定义解析
+	trace := httptrace.ContextClientTrace(ctx)
+	if trace != nil && trace.DNSStart != nil {
+		trace.DNSStart(httptrace.DNSStartInfo{Host: host})
+	}
+	resolved, ttl, err := resolver.Resolve(ctx, host)
+	if trace != nil && trace.DNSDone != nil {
+		addrs := make([]net.IPAddr, len(resolved))
+		for i, ip := range resolved {
+			addrs[i] = net.IPAddr{IP: ip}
+		}
+		trace.DNSDone(httptrace.DNSDoneInfo{Addrs: addrs, Err: err})
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(resolved) == 0 {
+		return nil, fmt.Errorf("解析 %s 失败: %w", host, ErrDNSNoRecord)
+	}
+
+	if ttl > 0 {
+		c.dns.mu.Lock()
+		c.dns.cache[host] = dnsCacheEntry{ips: resolved, expires: time.Now().Add(ttl)}
+		c.dns.mu.Unlock()
+	}
+	return joinIPs(resolved, port), nil
+}
+
+// dialResolved 按解析结果依次尝试连接
+func (c *Client) dialResolved(ctx context.Context, network, addr string) (net.Conn, error) {
+	var d net.Dialer
+	return c.dialResolvedVia(ctx, addr, func(ctx context.Context, addr string) (net.Conn, error) {
+		return d.DialContext(ctx, network, addr)
+	})
+}
+
+// dialResolvedVia 解析地址后使用 dial 依次尝试（用于直连和代理拨号）
+func (c *Client) dialResolvedVia(ctx context.Context, addr string, dial func(ctx context.Context, addr string) (net.Conn, error)) (net.Conn, error) {
+	addrs, err := c.resolveAddr(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	var lastErr error
+	for _, a := range addrs {
+		conn, err := dial(ctx, a)
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+// joinAddrs 拼接 IP 和端口
+func joinAddrs(ips []string, port string) []string {
+	addrs := make([]string, len(ips))
+	for i, ip := range ips {
+		addrs[i] = net.JoinHostPort(ip, port)
+	}
+	return addrs
+}
+
+// joinIPs 拼接 IP 和端口
+func joinIPs(ips []net.IP, port string) []string {
+	addrs := make([]string, len(ips))
+	for i, ip := range ips {
+		addrs[i] = net.JoinHostPort(ip.String(), port)
+	}
+	return addrs
+}
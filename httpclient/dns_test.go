@@ -0,0 +1,270 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// dnsTestAnswer 按查询构造响应：A 查询返回 ip，AAAA 查询返回空结果
+func dnsTestAnswer(t *testing.T, query []byte, ip net.IP, ttl uint32, truncated bool) []byte {
+	t.Helper()
+	var msg dnsmessage.Message
+	if err := msg.Unpack(query); err != nil {
+		t.Errorf("解析查询失败: %v", err)
+		return nil
+	}
+	msg.Header.Response = true
+	msg.Header.Truncated = truncated
+	q := msg.Questions[0]
+	if q.Type == dnsmessage.TypeA && !truncated {
+		var a [4]byte
+		copy(a[:], ip.To4())
+		msg.Answers = []dnsmessage.Resource{{
+			Header: dnsmessage.ResourceHeader{Name: q.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: ttl},
+			Body:   &dnsmessage.AResource{A: a},
+		}}
+	}
+	resp, err := msg.Pack()
+	if err != nil {
+		t.Errorf("构造响应失败: %v", err)
+	}
+	return resp
+}
+
+// countingResolver 记录解析次数的解析器
+type countingResolver struct {
+	calls int32
+	ip    net.IP
+	ttl   time.Duration
+}
+
+func (r *countingResolver) Resolve(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+	atomic.AddInt32(&r.calls, 1)
+	return []net.IP{r.ip}, r.ttl, nil
+}
+
+func TestResolveAddr(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string]string
+		resolver  *countingResolver
+		addr      string
+		want      string
+		calls     int32 // 解析两次后解析器被调用的次数
+	}{
+		{"没有配置原样返回", nil, nil, "a.test:80", "a.test:80", 0},
+		{"IP地址不解析", nil, &countingResolver{ip: net.ParseIP("10.0.0.1"), ttl: time.Hour}, "1.2.3.4:80", "1.2.3.4:80", 0},
+		{"域名覆盖", map[string]string{"A.test": "10.0.0.2"}, nil, "a.test:443", "10.0.0.2:443", 0},
+		{"按端口覆盖", map[string]string{"a.test:8080": "10.0.0.3"}, nil, "a.test:8080", "10.0.0.3:8080", 0},
+		{"端口不匹配不覆盖", map[string]string{"a.test:8080": "10.0.0.3"}, nil, "a.test:80", "a.test:80", 0},
+		{"覆盖优先于解析器", map[string]string{"a.test": "10.0.0.2"}, &countingResolver{ip: net.ParseIP("10.0.0.1"), ttl: time.Hour}, "a.test:80", "10.0.0.2:80", 0},
+		{"按TTL缓存", nil, &countingResolver{ip: net.ParseIP("10.0.0.1"), ttl: time.Hour}, "a.test:80", "10.0.0.1:80", 1},
+		{"TTL为0不缓存", nil, &countingResolver{ip: net.ParseIP("10.0.0.1")}, "a.test:80", "10.0.0.1:80", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := New().SetDNSOverrides(tt.overrides)
+			if tt.resolver != nil {
+				client.SetResolver(tt.resolver)
+			}
+			for i := 0; i < 2; i++ {
+				addrs, err := client.resolveAddr(context.Background(), tt.addr)
+				if err != nil || len(addrs) != 1 || addrs[0] != tt.want {
+					t.Fatalf("resolveAddr = %v, %v, want %s", addrs, err, tt.want)
+				}
+			}
+			if tt.resolver != nil && tt.resolver.calls != tt.calls {
+				t.Errorf("解析 %d 次, want %d", tt.resolver.calls, tt.calls)
+			}
+		})
+	}
+
+	// 缓存过期和清空缓存后重新解析
+	r := &countingResolver{ip: net.ParseIP("10.0.0.1"), ttl: 20 * time.Millisecond}
+	client := New().SetResolver(r)
+	client.resolveAddr(context.Background(), "a.test:80")
+	time.Sleep(30 * time.Millisecond)
+	client.resolveAddr(context.Background(), "a.test:80")
+	client.ClearDNSCache()
+	client.resolveAddr(context.Background(), "a.test:80")
+	if r.calls != 3 {
+		t.Errorf("过期和清空后解析 %d 次, want 3", r.calls)
+	}
+}
+
+func TestDNSOverrideWithHTTPProxy(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("target"))
+	}))
+	defer target.Close()
+	_, port, _ := net.SplitHostPort(target.Listener.Addr().String())
+
+	// HTTP代理替身：记录 CONNECT 目标和转发请求的URL
+	var mu sync.Mutex
+	var seen []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = append(seen, r.Method+" "+r.Host)
+		mu.Unlock()
+		if r.Method != http.MethodConnect {
+			w.Write([]byte("forwarded"))
+			return
+		}
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer upstream.Close()
+		conn, brw, _ := w.(http.Hijacker).Hijack()
+		defer conn.Close()
+		conn.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n"))
+		go io.Copy(upstream, brw)
+		io.Copy(conn, upstream)
+	}))
+	defer proxy.Close()
+
+	client := New().
+		SetHTTPProxy(strings.TrimPrefix(proxy.URL, "http://")).
+		SetDNSOverride("override.test", "127.0.0.1")
+
+	tests := []struct {
+		url  string
+		body string
+		seen string
+	}{
+		// 覆盖的域名在本地解析，通过 CONNECT 隧道连接解析后的IP
+		{"http://override.test:" + port + "/", "target", "CONNECT 127.0.0.1:" + port},
+		// 其他域名正常经代理转发
+		{"http://other.test/", "forwarded", "GET other.test"},
+	}
+	for _, tt := range tests {
+		mu.Lock()
+		seen = nil
+		mu.Unlock()
+		resp, err := client.Get(tt.url, nil)
+		if err != nil || resp.Text() != tt.body {
+			t.Fatalf("Get %s = %v, %v", tt.url, resp, err)
+		}
+		mu.Lock()
+		if len(seen) != 1 || seen[0] != tt.seen {
+			t.Errorf("%s: 代理收到 %v, want %s", tt.url, seen, tt.seen)
+		}
+		mu.Unlock()
+	}
+}
+
+func TestUDPResolver(t *testing.T) {
+	// TCP 和 UDP 监听同一端口，UDP 对 A 查询返回截断响应
+	tcpLn, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tcpLn.Close()
+	udpConn, err := net.ListenPacket("udp", tcpLn.Addr().String())
+	if err != nil {
+		t.Skipf("无法监听UDP: %v", err)
+	}
+	defer udpConn.Close()
+
+	ip := net.ParseIP("10.1.2.3")
+	var tcpQueries int32
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := udpConn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var p dnsmessage.Parser
+			p.Start(buf[:n])
+			q, _ := p.Question()
+			udpConn.WriteTo(dnsTestAnswer(t, buf[:n], ip, 30, q.Type == dnsmessage.TypeA), addr)
+		}
+	}()
+	go func() {
+		for {
+			conn, err := tcpLn.Accept()
+			if err != nil {
+				return
+			}
+			atomic.AddInt32(&tcpQueries, 1)
+			var length [2]byte
+			io.ReadFull(conn, length[:])
+			query := make([]byte, binary.BigEndian.Uint16(length[:]))
+			io.ReadFull(conn, query)
+			resp := dnsTestAnswer(t, query, ip, 30, false)
+			msg := binary.BigEndian.AppendUint16(nil, uint16(len(resp)))
+			conn.Write(append(msg, resp...))
+			conn.Close()
+		}
+	}()
+
+	ips, ttl, err := NewUDPResolver(tcpLn.Addr().String()).Resolve(context.Background(), "a.test")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if len(ips) != 1 || !ips[0].Equal(ip) || ttl != 30*time.Second {
+		t.Errorf("Resolve = %v %v", ips, ttl)
+	}
+	if tcpQueries != 1 {
+		t.Errorf("截断响应改用TCP %d 次, want 1", tcpQueries)
+	}
+}
+
+func TestDoHResolver(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/dns-query" {
+			http.NotFound(w, r)
+			return
+		}
+		query, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/dns-message" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		// RFC 8484 建议查询 ID 为 0
+		if len(query) < 2 || query[0] != 0 || query[1] != 0 {
+			http.Error(w, "id", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/dns-message")
+		w.Write(dnsTestAnswer(t, query, net.ParseIP("10.9.8.7"), 120, false))
+	}))
+	defer srv.Close()
+
+	ips, ttl, err := NewDoHResolver(srv.URL+"/dns-query").Resolve(context.Background(), "a.test")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if len(ips) != 1 || ips[0].String() != "10.9.8.7" || ttl != 120*time.Second {
+		t.Errorf("Resolve = %v %v", ips, ttl)
+	}
+
+	// 服务器错误
+	if _, _, err := NewDoHResolver(srv.URL+"/missing").Resolve(context.Background(), "a.test"); err == nil {
+		t.Error("DoH 错误响应应返回错误")
+	}
+
+	// 作为客户端解析器使用
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer target.Close()
+	_, port, _ := net.SplitHostPort(target.Listener.Addr().String())
+	client := New().SetTimeout(time.Second).SetResolver(&countingResolver{ip: net.ParseIP("127.0.0.1"), ttl: time.Minute})
+	if resp, err := client.Get("http://resolved.test:"+port+"/", nil); err != nil || resp.Text() != "ok" {
+		t.Errorf("Get = %v, %v", resp, err)
+	}
+}
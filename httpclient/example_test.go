@@ -1,6 +1,8 @@
 package httpclient_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -161,3 +163,356 @@ func Example_responseHelpers() {
 	fmt.Println(resp.GetAllHeaders())
 	fmt.Println(resp.GetAllCookies())
 }
+
+func Example_harRecorder() {
+	// 录制请求为HAR，可在浏览器开发者工具中导入
+	recorder := httpclient.NewHARRecorder().
+		SetMaxBodySize(64*1024).
+		RedactFields("password", "token").
+		RedactCookies(true)
+
+	client := httpclient.New().SetHARRecorder(recorder)
+
+	client.PostForm("https://httpbin.org/post", map[string]string{
+		"username": "admin",
+		"password": "123456",
+	}, nil)
+	client.Get("https://httpbin.org/cookies", nil)
+
+	fmt.Println("已记录:", recorder.Len())
+	recorder.Save("login.har")
+}
+
+func Example_timings() {
+	client := httpclient.New().
+		SetLogHook(func(log *httpclient.RequestLog) {
+			fmt.Printf("%s %s %d 耗时=%v 首字节=%v 复用=%v\n",
+				log.Method, log.URL, log.StatusCode,
+				log.Timings.Total, log.Timings.TTFB, log.Timings.ConnReused)
+		})
+
+	resp, err := client.Get("https://httpbin.org/get", nil)
+	if err != nil {
+		panic(err)
+	}
+
+	// 各阶段耗时
+	t := resp.Timings
+	fmt.Println("DNS:", t.DNSLookup)
+	fmt.Println("TCP连接:", t.TCPConnect)
+	fmt.Println("代理握手:", t.ProxyConnect)
+	fmt.Println("TLS握手:", t.TLSHandshake)
+	fmt.Println("服务端处理:", t.ServerProcessing)
+	fmt.Println("远端地址:", t.RemoteAddr)
+}
+
+func Example_rateLimit() {
+	client := httpclient.New().
+		// 全局：所有host合计每秒最多20个请求，最多50个并发
+		SetRateLimit(httpclient.RateLimit{RPS: 20, MaxConcurrent: 50}).
+		// 每个host：每秒2个请求，最多5个并发，两次请求间随机间隔200~800ms
+		SetHostRateLimit(httpclient.AllHosts, httpclient.RateLimit{
+			RPS:           2,
+			MaxConcurrent: 5,
+			MinDelay:      200 * time.Millisecond,
+			MaxDelay:      800 * time.Millisecond,
+		}).
+		// 单独放宽某个host
+		SetHostRateLimit("httpbin.org", httpclient.RateLimit{RPS: 10})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := client.Get("https://httpbin.org/get", &httpclient.Options{Context: ctx})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("限流等待:", resp.Timings.Blocked)
+}
+
+func Example_cache() {
+	// 内存LRU缓存，遵循 Cache-Control / ETag / Last-Modified / Vary
+	client := httpclient.New().SetCache(httpclient.NewMemoryCache(500))
+
+	resp, _ := client.Get("https://httpbin.org/cache/60", nil)
+	fmt.Println(resp.CacheStatus) // MISS
+
+	resp, _ = client.Get("https://httpbin.org/cache/60", nil)
+	fmt.Println(resp.CacheStatus, resp.FromCache()) // HIT true
+
+	// 磁盘缓存 + 强制缓存模式（开发调试时避免重复请求）
+	diskCache, err := httpclient.NewDiskCache(".httpcache")
+	if err != nil {
+		panic(err)
+	}
+	devClient := httpclient.New().
+		SetCache(diskCache).
+		SetCacheMode(httpclient.CacheModeForce)
+
+	resp, _ = devClient.Get("https://httpbin.org/get", nil)
+	fmt.Println(resp.CacheStatus)
+}
+
+func Example_requestBuilder() {
+	client := httpclient.New()
+
+	type User struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	type APIError struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+
+	var user User
+	var apiErr APIError
+
+	// 基础URL + 路径参数 + 多值/有序查询参数 + 认证 + 结果解析
+	resp, err := client.R().
+		SetBaseURL("https://api.example.com/v1").
+		SetPathParam("id", "1001").
+		SetQuery("fields", "id,name").
+		AddQuery("tag", "a", "b"). // tag=a&tag=b
+		SetBearer("your-token").
+		SetResult(&user).
+		SetError(&apiErr).
+		Get("/users/{id}")
+	if err != nil {
+		panic(err)
+	}
+	if resp.IsSuccess() {
+		fmt.Println(user.Name)
+	} else {
+		fmt.Println(apiErr.Message)
+	}
+
+	// POST JSON + Basic 认证
+	client.R().
+		SetBasicAuth("admin", "123456").
+		SetJSON(map[string]interface{}{"name": "张三"}).
+		Send("POST", "https://httpbin.org/post")
+}
+
+func Example_auth() {
+	// Digest 认证：自动处理 401 质询
+	client := httpclient.New().SetAuth(httpclient.NewDigestAuth("user", "passwd"))
+	resp, _ := client.Get("https://httpbin.org/digest-auth/auth/user/passwd", nil)
+	fmt.Println(resp.StatusCode)
+
+	// Bearer Token：401 时自动刷新一次并重放请求
+	bearer := httpclient.NewBearerAuth("old-token", func() (string, error) {
+		// 调用登录/刷新接口获取新Token
+		return "new-token", nil
+	})
+	client2 := httpclient.New().SetAuth(bearer)
+	client2.Get("https://api.example.com/profile", nil)
+	fmt.Println(bearer.Token())
+
+	// HMAC 签名：方法、路径、排序后的查询参数、请求体哈希、时间戳
+	signer := httpclient.NewHMACAuth("access-key", "secret-key")
+	signer.SignatureHeader = "X-Sign"
+	client3 := httpclient.New().SetAuth(signer)
+	client3.PostJSON("https://api.example.com/order?b=2&a=1", map[string]interface{}{"id": 1}, nil)
+}
+
+func Example_baseURLAndSession() {
+	// 基础URL：请求传入相对路径
+	base := httpclient.New().
+		SetBaseURL("https://api.example.com/v1").
+		AddHeader("User-Agent", "MyClient/1.0").
+		SetProxy("127.0.0.1:7890", "http")
+
+	base.Get("/status", nil) // https://api.example.com/v1/status
+
+	// 每个账号一个会话：共享连接池，请求头和Cookie独立
+	account1 := base.WithSession().AddCookie("token", "aaa")
+	account2 := base.WithSession().AddCookie("token", "bbb")
+	account1.Get("/profile", nil)
+	account2.Get("/profile", nil)
+
+	// 完全独立的副本（独立连接池，可单独换代理）
+	other := base.Clone().SetProxy("127.0.0.1:1080", "socks5")
+	other.Get("/status", nil)
+}
+
+func Example_webSocket() {
+	// 先登录获取Cookie，再使用同一个客户端（Cookie、请求头、代理、TLS配置）建立WebSocket
+	client := httpclient.New().SetProxy("127.0.0.1:1080", "socks5")
+	client.PostForm("https://example.com/login", map[string]string{"user": "admin"}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ws, err := client.WebSocketContext(ctx, "wss://example.com/ws", &httpclient.WSOptions{
+		Headers:      map[string]string{"Origin": "https://example.com"},
+		PingInterval: 20 * time.Second, // 心跳保活
+	})
+	if err != nil {
+		panic(err)
+	}
+	defer ws.Close()
+
+	ws.WriteJSON(map[string]string{"action": "subscribe", "channel": "ticker"})
+
+	for {
+		msgType, data, err := ws.ReadMessage()
+		if err != nil {
+			fmt.Println("连接断开:", err)
+			return
+		}
+		if msgType == httpclient.TextMessage {
+			fmt.Println(string(data))
+		}
+	}
+}
+
+func Example_sse() {
+	client := httpclient.New().AddHeader("Authorization", "Bearer token")
+
+	// 断线后自动携带 Last-Event-ID 重连，连续失败5次后停止
+	stream, err := client.SSE("https://example.com/events", &httpclient.SSEOptions{
+		RetryDelay: 2 * time.Second,
+		MaxRetries: 5,
+	})
+	if err != nil {
+		panic(err)
+	}
+	defer stream.Close()
+
+	for event := range stream.Events() {
+		fmt.Println(event.ID, event.Event, event.Data)
+	}
+	if err := stream.Err(); err != nil {
+		fmt.Println("事件流结束:", err)
+	}
+}
+
+func Example_codecs() {
+	client := httpclient.New()
+
+	// 结构体按 form 标签编码为表单
+	type Login struct {
+		User     string `form:"user"`
+		Password string `form:"password"`
+		Remember bool   `form:"remember,omitempty"`
+	}
+	client.PostFormStruct("https://example.com/login", Login{User: "admin", Password: "123456"}, nil)
+
+	// XML / MessagePack 请求体
+	client.PostXML("https://example.com/api/xml", struct {
+		Name string `xml:"name"`
+	}{Name: "test"}, nil)
+	client.R().SetMsgpack(map[string]interface{}{"id": 1}).Post("https://example.com/api/msgpack")
+
+	// 响应按 Content-Type 自动选择解码器（XML、Protobuf、MessagePack、表单、JSON）
+	var result struct {
+		Code int    `json:"code" xml:"code" msgpack:"code"`
+		Msg  string `json:"msg" xml:"msg" msgpack:"msg"`
+	}
+	resp, err := client.Get("https://example.com/api/data", nil)
+	if err != nil {
+		panic(err)
+	}
+	if err := resp.Decode(&result); err != nil {
+		panic(err)
+	}
+	fmt.Println(result.Code, result.Msg)
+
+	// 注册自定义编解码器，并设为默认请求体编码
+	client.RegisterCodec(httpclient.MsgpackCodec{}, "application/vnd.example+msgpack").
+		SetBodyCodec(httpclient.ContentTypeMsgpack)
+	client.Post("https://example.com/api/msgpack", map[string]int{"id": 1}, nil)
+}
+
+func Example_requestCompression() {
+	// 超过1KB的请求体使用gzip压缩（自动设置 Content-Encoding，重试和307/308重定向可重放）
+	client := httpclient.New().SetRequestCompression(httpclient.CompressGzip, 1024)
+
+	payload := make([]map[string]interface{}, 1000)
+	for i := range payload {
+		payload[i] = map[string]interface{}{"id": i, "name": "item"}
+	}
+	client.PostJSON("https://example.com/api/batch", payload, nil)
+
+	// 单个请求改用zstd，或关闭压缩
+	client.Post("https://example.com/api/batch", payload, &httpclient.Options{Compression: httpclient.CompressZstd})
+	client.R().SetCompression(httpclient.CompressIdentity).SetJSON(payload).Post("https://example.com/api/raw")
+}
+
+func Example_maxBodySize() {
+	// 默认限制响应体10MB（按解压后大小计算，防止压缩炸弹）
+	client := httpclient.New().SetMaxBodySize(10 << 20)
+
+	_, err := client.Get("https://example.com/large", nil)
+	if errors.Is(err, httpclient.ErrBodyTooLarge) {
+		fmt.Println("响应体过大:", err)
+	}
+
+	// 单个请求取消限制
+	client.Get("https://example.com/download", &httpclient.Options{MaxBodySize: -1})
+
+	// 只探测状态码和响应头，不读取响应体
+	resp, err := client.Get("https://example.com/health", &httpclient.Options{DiscardBody: true})
+	if err == nil {
+		fmt.Println(resp.StatusCode, resp.GetHeader("Server"))
+	}
+}
+
+func Example_browserHeaders() {
+	// 固定种子，生成可复现的浏览器请求头（User-Agent、Sec-Ch-Ua、Accept-Language 等相互一致）
+	gen := httpclient.NewBrowserGenerator(42).
+		SetFamilies(httpclient.BrowserChrome, httpclient.BrowserEdge).
+		SetDevice(httpclient.DeviceDesktop).
+		SetLanguages("zh-CN", "en-US")
+
+	profile := gen.Generate()
+	fmt.Println(profile.UserAgent)
+	fmt.Println(profile.SecChUa)
+
+	// 整个会话使用同一组请求头
+	client := httpclient.New().SetBrowser(profile)
+	client.Get("https://example.com", nil)
+
+	// 每个请求轮换一组新的请求头
+	rotating := httpclient.New().RotateBrowser(httpclient.NewBrowserGenerator(0))
+	rotating.Get("https://example.com", nil)
+}
+
+func Example_dns() {
+	// 固定域名解析（类似 curl --resolve），直连和代理连接都生效
+	client := httpclient.New().
+		SetDNSOverride("api.example.com", "203.0.113.10").
+		SetDNSOverride("api.example.com:8443", "203.0.113.11") // 只对8443端口生效
+	client.Get("https://api.example.com/status", nil)
+
+	// 使用 DNS over HTTPS，结果按TTL缓存
+	doh := httpclient.New().SetResolver(httpclient.NewDoHResolver("https://cloudflare-dns.com/dns-query"))
+	doh.Get("https://example.com", nil)
+
+	// 使用指定DNS服务器
+	udp := httpclient.New().SetResolver(httpclient.NewUDPResolver("8.8.8.8"))
+	udp.Get("https://example.com", nil)
+}
+
+func Example_mockTransport() {
+	// 单元测试中替换传输层，不需要真实服务器
+	client, mock := httpclient.NewMockClient()
+	client.SetBaseURL("https://api.example.com")
+
+	users := mock.On("GET", "/users/*").
+		ReplyJSON(200, map[string]interface{}{"id": 1, "name": "张三"}).
+		Expect(1)
+	mock.On("POST", "/orders").
+		WithBody(func(body []byte) bool { return len(body) > 0 }).
+		Reply(201, `{"id":100}`)
+	mock.On("GET", "/slow").Delay(2*time.Second).Reply(200, "ok")        // 模拟延迟
+	mock.On("GET", "/down").ReplyError(errors.New("connection refused")) // 模拟网络错误
+
+	resp, _ := client.Get("/users/1", nil)
+	fmt.Println(resp.StatusCode, resp.Text())
+	client.PostJSON("/orders", map[string]int{"sku": 1}, nil)
+
+	fmt.Println(users.CallCount(), string(mock.Calls()[1].Body))
+	// 测试函数中: mock.AssertExpectations(t)
+}
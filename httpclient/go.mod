@@ -2,4 +2,11 @@ module github.com/Drunkard-baifeng/golibs/httpclient
 
 go 1.21
 
-require golang.org/x/net v0.20.0
+require (
+	github.com/klauspost/compress v1.17.11
+	github.com/vmihailenco/msgpack/v5 v5.4.1
+	golang.org/x/net v0.20.0
+	google.golang.org/protobuf v1.33.0
+)
+
+require github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
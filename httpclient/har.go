@@ -0,0 +1,568 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// 脱敏后的替换值
+const harRedacted = "[REDACTED]"
+
+// HAR HAR 1.2 根对象
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+// HARLog HAR日志
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Entries []HAREntry `json:"entries"`
+}
+
+// HARCreator 生成工具信息
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HAREntry 单次请求记录
+type HAREntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"` // 总耗时（毫秒）
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+	ServerIPAddress string      `json:"serverIPAddress,omitempty"`
+	Connection      string      `json:"connection,omitempty"`
+	Error           string      `json:"_error,omitempty"` // 请求失败原因（自定义字段）
+}
+
+// HARRequest 请求信息
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+// HARResponse 响应信息
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+// HARNameValue 名值对（header、cookie、query）
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARPostData 请求体
+type HARPostData struct {
+	MimeType string         `json:"mimeType"`
+	Params   []HARNameValue `json:"params,omitempty"`
+	Text     string         `json:"text"`
+	Comment  string         `json:"comment,omitempty"`
+}
+
+// HARContent 响应体
+type HARContent struct {
+	Size        int    `json:"size"`
+	MimeType    string `json:"mimeType"`
+	Compression int    `json:"compression,omitempty"` // 压缩节省的字节数
+	Text        string `json:"text,omitempty"`
+	Encoding    string `json:"encoding,omitempty"`
+	Comment     string `json:"comment,omitempty"`
+}
+
+// HARTimings 各阶段耗时（毫秒，-1 表示不适用）
+type HARTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// HARRecorder 请求录制器，将请求/响应记录为 HAR 1.2 格式
+// 可在浏览器开发者工具中打开，或与浏览器抓包结果对比
+type HARRecorder struct {
+	mu            sync.Mutex
+	entries       []HAREntry
+	maxBodySize   int             // 记录的最大body字节数（0 不限制）
+	redactHeaders map[string]bool // 需要脱敏的请求头/响应头
+	redactFields  map[string]bool // 需要脱敏的查询参数/表单/JSON字段
+	redactCookies bool            // 是否脱敏Cookie值
+}
+
+// NewHARRecorder 创建HAR录制器
+// 默认 body 最多记录 1MB，并对 Authorization、Proxy-Authorization 和 Cookie 值脱敏
+func NewHARRecorder() *HARRecorder {
+	r := &HARRecorder{
+		entries:       make([]HAREntry, 0),
+		maxBodySize:   1 << 20,
+		redactHeaders: make(map[string]bool),
+		redactFields:  make(map[string]bool),
+		redactCookies: true,
+	}
+	r.RedactHeaders("Authorization", "Proxy-Authorization")
+	return r
+}
+
+// SetMaxBodySize 设置记录的最大body字节数（0 不限制，超出部分截断）
+func (r *HARRecorder) SetMaxBodySize(size int) *HARRecorder {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.maxBodySize = size
+	return r
+}
+
+// RedactHeaders 添加需要脱敏的请求头/响应头
+func (r *HARRecorder) RedactHeaders(names ...string) *HARRecorder {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, name := range names {
+		r.redactHeaders[normalizeHeaderKey(name)] = true
+	}
+	return r
+}
+
+// RedactFields 添加需要脱敏的字段（如 password、token）
+// 作用于查询参数、表单请求体，以及 JSON 请求体和响应体（任意层级的同名键）
+func (r *HARRecorder) RedactFields(names ...string) *HARRecorder {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, name := range names {
+		r.redactFields[strings.ToLower(name)] = true
+	}
+	return r
+}
+
+// RedactCookies 设置是否脱敏Cookie值（默认开启，同时作用于 Cookie/Set-Cookie 头）
+func (r *HARRecorder) RedactCookies(redact bool) *HARRecorder {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.redactCookies = redact
+	return r
+}
+
+// Len 获取已记录的条目数
+func (r *HARRecorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.entries)
+}
+
+// Reset 清空已记录的条目
+func (r *HARRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = make([]HAREntry, 0)
+}
+
+// Entries 获取已记录的条目（返回副本）
+func (r *HARRecorder) Entries() []HAREntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make([]HAREntry, len(r.entries))
+	copy(result, r.entries)
+	return result
+}
+
+// HAR 导出为HAR对象
+func (r *HARRecorder) HAR() *HAR {
+	return &HAR{
+		Log: HARLog{
+			Version: "1.2",
+			Creator: HARCreator{Name: "golibs/httpclient", Version: "1.0"},
+			Entries: r.Entries(),
+		},
+	}
+}
+
+// Export 导出为HAR JSON
+func (r *HARRecorder) Export() ([]byte, error) {
+	return json.MarshalIndent(r.HAR(), "", "  ")
+}
+
+// Save 保存HAR到文件
+func (r *HARRecorder) Save(path string) error {
+	data, err := r.Export()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// harExchange 一次请求的原始数据
+type harExchange struct {
+	req      *http.Request
+	reqBody  []byte
+	resp     *http.Response
+	respBody []byte
+	respSize int // 实际传输的响应体字节数（未知时为 -1，命中缓存时为 0）
+	start    time.Time
+	timings  Timings
+	err      error
+}
+
+// record 记录一次请求
+func (r *HARRecorder) record(ex *harExchange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry := HAREntry{
+		StartedDateTime: ex.start.Format(time.RFC3339Nano),
+		Time:            durationMs(ex.timings.Total),
+		Request:         r.buildRequest(ex),
+		Timings:         harTimings(ex.timings),
+		ServerIPAddress: serverIP(ex.timings.RemoteAddr),
+	}
+
+	if ex.err != nil {
+		entry.Error = ex.err.Error()
+	}
+	if ex.resp != nil {
+		entry.Response = r.buildResponse(ex.resp, ex.respBody, ex.respSize)
+	} else {
+		entry.Response = HARResponse{
+			Cookies:     []HARNameValue{},
+			Headers:     []HARNameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		}
+	}
+
+	r.entries = append(r.entries, entry)
+}
+
+// buildRequest 构建HAR请求
+func (r *HARRecorder) buildRequest(ex *harExchange) HARRequest {
+	req := ex.req
+
+	cookies := make([]HARNameValue, 0)
+	for _, cookie := range req.Cookies() {
+		cookies = append(cookies, r.cookieValue(cookie))
+	}
+
+	query := make([]HARNameValue, 0)
+	for name, values := range req.URL.Query() {
+		for _, v := range values {
+			query = append(query, HARNameValue{Name: name, Value: r.fieldValue(name, v)})
+		}
+	}
+
+	// 请求实际使用的协议版本以响应为准（HTTP/2 等），没有响应时按 HTTP/1.1 记录
+	httpVersion := "HTTP/1.1"
+	if ex.resp != nil && ex.resp.Proto != "" {
+		httpVersion = ex.resp.Proto
+	}
+
+	result := HARRequest{
+		Method:      req.Method,
+		URL:         r.redactURL(req.URL),
+		HTTPVersion: httpVersion,
+		Cookies:     cookies,
+		Headers:     r.headerValues(req.Header),
+		QueryString: query,
+		HeadersSize: -1,
+		BodySize:    len(ex.reqBody),
+	}
+
+	if ex.reqBody != nil {
+		mimeType := req.Header.Get("Content-Type")
+		postData := &HARPostData{MimeType: mimeType}
+		// 压缩的请求体按解压后的内容记录（bodySize 仍为实际发送的大小）
+		reqBody := ex.reqBody
+		if encoding := req.Header.Get("Content-Encoding"); encoding != "" && !strings.EqualFold(encoding, "identity") {
+			decoded, err := decompressBytes(reqBody, Compression(strings.ToLower(encoding)))
+			if err != nil {
+				postData.Comment = fmt.Sprintf("请求体已压缩（%s），未记录", encoding)
+				result.PostData = postData
+				return result
+			}
+			reqBody = decoded
+		}
+		if strings.HasPrefix(mimeType, "application/x-www-form-urlencoded") {
+			form, _ := url.ParseQuery(string(reqBody))
+			for name, values := range form {
+				for _, v := range values {
+					postData.Params = append(postData.Params, HARNameValue{Name: name, Value: r.fieldValue(name, v)})
+				}
+			}
+			postData.Text = r.redactForm(form)
+		} else if text, ok := r.redactJSON(mimeType, reqBody); ok {
+			postData.Text, postData.Comment = r.truncateText(text)
+		} else {
+			postData.Text, postData.Comment = r.truncateText(reqBody)
+		}
+		result.PostData = postData
+	} else if req.Body != nil && req.GetBody == nil {
+		result.BodySize = -1
+		result.PostData = &HARPostData{
+			MimeType: req.Header.Get("Content-Type"),
+			Comment:  "流式请求体未记录",
+		}
+	}
+
+	return result
+}
+
+// buildResponse 构建HAR响应（body 为解压后的内容，bodySize 为实际传输的大小）
+func (r *HARRecorder) buildResponse(resp *http.Response, body []byte, bodySize int) HARResponse {
+	cookies := make([]HARNameValue, 0)
+	for _, cookie := range resp.Cookies() {
+		cookies = append(cookies, r.cookieValue(cookie))
+	}
+
+	content := HARContent{
+		Size:     len(body),
+		MimeType: resp.Header.Get("Content-Type"),
+	}
+	if bodySize > 0 && bodySize < len(body) {
+		content.Compression = len(body) - bodySize
+	}
+	if text, ok := r.redactJSON(content.MimeType, body); ok {
+		content.Text, content.Comment = r.truncateText(text)
+	} else if utf8.Valid(body) {
+		content.Text, content.Comment = r.truncateText(body)
+	} else {
+		data := body
+		if r.maxBodySize > 0 && len(data) > r.maxBodySize {
+			data = data[:r.maxBodySize]
+			content.Comment = "truncated"
+		}
+		content.Text = base64.StdEncoding.EncodeToString(data)
+		content.Encoding = "base64"
+	}
+
+	return HARResponse{
+		Status:      resp.StatusCode,
+		StatusText:  strings.TrimSpace(strings.TrimPrefix(resp.Status, strconv.Itoa(resp.StatusCode))),
+		HTTPVersion: resp.Proto,
+		Cookies:     cookies,
+		Headers:     r.headerValues(resp.Header),
+		Content:     content,
+		RedirectURL: resp.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    bodySize,
+	}
+}
+
+// headerValues 转换header并脱敏
+func (r *HARRecorder) headerValues(header http.Header) []HARNameValue {
+	result := make([]HARNameValue, 0, len(header))
+	for name, values := range header {
+		key := normalizeHeaderKey(name)
+		redact := r.redactHeaders[key] || (r.redactCookies && (key == "Cookie" || key == "Set-Cookie"))
+		for _, v := range values {
+			if redact {
+				v = harRedacted
+			}
+			result = append(result, HARNameValue{Name: name, Value: v})
+		}
+	}
+	return result
+}
+
+// cookieValue 转换Cookie并脱敏
+func (r *HARRecorder) cookieValue(cookie *http.Cookie) HARNameValue {
+	value := cookie.Value
+	if r.redactCookies {
+		value = harRedacted
+	}
+	return HARNameValue{Name: cookie.Name, Value: value}
+}
+
+// fieldValue 查询参数/表单字段脱敏
+func (r *HARRecorder) fieldValue(name, value string) string {
+	if r.redactFields[strings.ToLower(name)] {
+		return harRedacted
+	}
+	return value
+}
+
+// redactForm 对表单内容脱敏后重新编码
+func (r *HARRecorder) redactForm(values url.Values) string {
+	redacted := make(url.Values, len(values))
+	for name, vs := range values {
+		for _, v := range vs {
+			redacted.Add(name, r.fieldValue(name, v))
+		}
+	}
+	return redacted.Encode()
+}
+
+// redactJSON 对 JSON 请求体/响应体中的同名字段脱敏（任意层级），不是 JSON 或无需脱敏时返回 false
+func (r *HARRecorder) redactJSON(mimeType string, body []byte) ([]byte, bool) {
+	if len(r.redactFields) == 0 || !strings.Contains(strings.ToLower(mimeType), "json") {
+		return nil, false
+	}
+	var v interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return nil, false
+	}
+	redacted, err := json.Marshal(r.redactJSONValue(v))
+	if err != nil {
+		return nil, false
+	}
+	return redacted, true
+}
+
+// redactJSONValue 递归脱敏 JSON 值
+func (r *HARRecorder) redactJSONValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			if r.redactFields[strings.ToLower(k)] {
+				val[k] = harRedacted
+			} else {
+				val[k] = r.redactJSONValue(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = r.redactJSONValue(item)
+		}
+	}
+	return v
+}
+
+// redactURL 对URL中的查询参数和用户信息脱敏
+func (r *HARRecorder) redactURL(u *url.URL) string {
+	clean := *u
+	if clean.User != nil {
+		clean.User = url.User(clean.User.Username())
+	}
+	if len(r.redactFields) > 0 && clean.RawQuery != "" {
+		clean.RawQuery = r.redactForm(clean.Query())
+	}
+	return clean.String()
+}
+
+// truncateText 按最大长度截断文本
+func (r *HARRecorder) truncateText(data []byte) (string, string) {
+	if r.maxBodySize > 0 && len(data) > r.maxBodySize {
+		return string(data[:r.maxBodySize]), "truncated"
+	}
+	return string(data), ""
+}
+
+// harTimings 转换耗时为HAR格式（复用连接时 dns/connect/ssl 为 -1）
+func harTimings(t Timings) HARTimings {
+	result := HARTimings{
+		Blocked: durationMs(t.Blocked),
+		DNS:     -1,
+		Connect: -1,
+		SSL:     -1,
+		Wait:    durationMs(t.ServerProcessing),
+		Receive: durationMs(t.ContentTransfer),
+	}
+	if !t.ConnReused {
+		result.DNS = durationMs(t.DNSLookup)
+		// HAR 的 connect 包含 SSL 握手
+		result.Connect = durationMs(t.TCPConnect + t.ProxyConnect + t.TLSHandshake)
+		if t.TLSHandshake > 0 {
+			result.SSL = durationMs(t.TLSHandshake)
+		}
+	}
+	return result
+}
+
+// serverIP 从 host:port 中提取IP
+func serverIP(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+// durationMs 转换为毫秒
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// snapshotRequestBody 读取请求体副本（仅支持可重复读取的请求体）
+func snapshotRequestBody(req *http.Request) []byte {
+	if req.Body == nil || req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// recordHAR 记录一次请求（未设置录制器时不做任何事）
+// wireSize 为实际传输的响应体大小（未知时为 -1，命中缓存时为 0）
+func (c *Client) recordHAR(ex *harExchange, resp *http.Response, body []byte, wireSize int, timings Timings, err error) {
+	if ex == nil || c.harRecorder == nil {
+		return
+	}
+	ex.timings = timings
+	ex.resp = resp
+	ex.respBody = body
+	ex.respSize = wireSize
+	ex.err = err
+	c.harRecorder.record(ex)
+}
+
+// SetHARRecorder 设置HAR录制器（nil 表示关闭录制）
+func (c *Client) SetHARRecorder(recorder *HARRecorder) *Client {
+	c.harRecorder = recorder
+	return c
+}
+
+// GetHARRecorder 获取HAR录制器
+func (c *Client) GetHARRecorder() *HARRecorder {
+	return c.harRecorder
+}
+
+// countingReader 统计读取的字节数（用于记录实际传输的响应体大小）
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
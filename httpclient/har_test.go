@@ -0,0 +1,112 @@
+package httpclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHARRedaction(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3cret"})
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"user":{"name":"bob","token":"t0ken"},"list":[{"password":"pw"}]}`))
+	}))
+	defer srv.Close()
+
+	recorder := NewHARRecorder().RedactFields("password", "token")
+	client := New().SetHARRecorder(recorder)
+	client.AddCookie("sid", "c00kie")
+
+	_, err := client.Post(srv.URL+"/login?token=q1&page=2", map[string]string{"name": "bob", "password": "123456"}, &Options{
+		Headers: map[string]string{"Authorization": "Bearer abc"},
+	})
+	if err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+
+	entries := recorder.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("entries = %d", len(entries))
+	}
+	data, err := recorder.Export()
+	if err != nil {
+		t.Fatal(err)
+	}
+	har := string(data)
+	for _, secret := range []string{"s3cret", "t0ken", `"pw"`, "123456", "c00kie", "Bearer abc", "q1"} {
+		if strings.Contains(har, secret) {
+			t.Errorf("HAR 中包含未脱敏的 %s", secret)
+		}
+	}
+
+	entry := entries[0]
+	if !strings.Contains(entry.Request.URL, "page=2") {
+		t.Errorf("URL = %s", entry.Request.URL)
+	}
+	if !strings.Contains(entry.Response.Content.Text, `"name":"bob"`) {
+		t.Errorf("响应体 = %s", entry.Response.Content.Text)
+	}
+	if len(entry.Response.Cookies) != 1 || entry.Response.Cookies[0].Value != harRedacted {
+		t.Errorf("响应 Cookies = %+v", entry.Response.Cookies)
+	}
+
+	// 关闭 Cookie 脱敏
+	recorder.Reset()
+	recorder.RedactCookies(false)
+	client.Get(srv.URL, nil)
+	if entries := recorder.Entries(); len(entries) != 1 || entries[0].Response.Cookies[0].Value != "s3cret" {
+		t.Errorf("关闭脱敏后 Cookies = %+v", entries[0].Response.Cookies)
+	}
+}
+
+func TestHARResponseBodySize(t *testing.T) {
+	body := strings.Repeat("hello har ", 100)
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write([]byte(body))
+	gz.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		if r.URL.Path == "/gzip" {
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Write(compressed.Bytes())
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	recorder := NewHARRecorder()
+	client := New().SetHARRecorder(recorder)
+	client.Get(srv.URL+"/gzip", &Options{Headers: map[string]string{"Accept-Encoding": "gzip"}})
+	client.Get(srv.URL+"/plain", nil)
+
+	entries := recorder.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("entries = %d", len(entries))
+	}
+	tests := []struct {
+		name        string
+		entry       HAREntry
+		bodySize    int
+		compression int
+	}{
+		{"gzip", entries[0], compressed.Len(), len(body) - compressed.Len()},
+		{"plain", entries[1], len(body), 0},
+	}
+	for _, tt := range tests {
+		resp := tt.entry.Response
+		if resp.BodySize != tt.bodySize || resp.Content.Size != len(body) || resp.Content.Compression != tt.compression {
+			t.Errorf("%s: bodySize=%d size=%d compression=%d, want %d %d %d",
+				tt.name, resp.BodySize, resp.Content.Size, resp.Content.Compression, tt.bodySize, len(body), tt.compression)
+		}
+		if resp.Content.Text != body {
+			t.Errorf("%s: 响应体未按解压后记录", tt.name)
+		}
+	}
+}
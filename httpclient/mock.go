@@ -0,0 +1,459 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrMockNoRoute 没有匹配的模拟路由
+var ErrMockNoRoute = errors.New("没有匹配的模拟路由")
+
+// SetTransport 使用自定义传输层（如 MockTransport），nil 恢复默认
+// 代理、TLS、DNS 等设置只对默认传输层生效；WebSocket 不经过传输层
+func (c *Client) SetTransport(rt http.RoundTripper) *Client {
+	c.roundTripper = rt
+	c.httpClient.Transport = c.activeTransport()
+	return c
+}
+
+// activeTransport 当前使用的传输层
+func (c *Client) activeTransport() http.RoundTripper {
+	if c.roundTripper != nil {
+		return c.roundTripper
+	}
+	if c.transportOwner != nil {
+		return c.transportOwner.activeTransport()
+	}
+	return c.transport
+}
+
+// TestingT 断言使用的测试接口（*testing.T 满足）
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// MockCall 一次被拦截的请求
+type MockCall struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte
+	Time   time.Time
+}
+
+// MockTransport 模拟传输层，按注册的路由返回预设响应（并发安全）
+// 用法: mock := NewMockTransport(); mock.On("GET", "/users/*").ReplyJSON(200, users); client.SetTransport(mock)
+type MockTransport struct {
+	mu       sync.Mutex
+	routes   []*MockRoute
+	calls    []*MockCall
+	fallback http.RoundTripper
+}
+
+// NewMockTransport 创建模拟传输层
+func NewMockTransport() *MockTransport {
+	return &MockTransport{}
+}
+
+// NewMockClient 创建使用模拟传输层的客户端
+func NewMockClient() (*Client, *MockTransport) {
+	mock := NewMockTransport()
+	return New().SetTransport(mock), mock
+}
+
+// On 注册路由（先注册的优先匹配）
+// method 为空或 "*" 匹配任意方法；pattern 以 "/" 开头时只匹配路径，否则匹配完整URL（不含查询参数），支持 path.Match 通配符，结尾 "*" 匹配任意后缀
+func (m *MockTransport) On(method, pattern string) *MockRoute {
+	route := &MockRoute{
+		method:   strings.ToUpper(method),
+		pattern:  pattern,
+		query:    make(map[string]string),
+		header:   make(map[string]string),
+		expected: -1,
+	}
+	m.mu.Lock()
+	m.routes = append(m.routes, route)
+	m.mu.Unlock()
+	return route
+}
+
+// SetFallback 没有匹配路由时交给 rt 处理（默认返回 ErrMockNoRoute）
+func (m *MockTransport) SetFallback(rt http.RoundTripper) *MockTransport {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fallback = rt
+	return m
+}
+
+// Calls 所有被拦截的请求（按时间顺序）
+func (m *MockTransport) Calls() []*MockCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*MockCall(nil), m.calls...)
+}
+
+// CallCount 匹配 method 和 pattern 的请求次数（规则同 On）
+func (m *MockTransport) CallCount(method, pattern string) int {
+	probe := &MockRoute{method: strings.ToUpper(method), pattern: pattern}
+	count := 0
+	for _, call := range m.Calls() {
+		if probe.matchMethodURL(call.Method, call.URL) {
+			count++
+		}
+	}
+	return count
+}
+
+// Reset 清空路由和请求记录
+func (m *MockTransport) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.routes = nil
+	m.calls = nil
+}
+
+// AssertExpectations 检查所有设置了 Expect 的路由调用次数
+func (m *MockTransport) AssertExpectations(t TestingT) bool {
+	t.Helper()
+	m.mu.Lock()
+	routes := append([]*MockRoute(nil), m.routes...)
+	m.mu.Unlock()
+
+	ok := true
+	for _, route := range routes {
+		route.mu.Lock()
+		expected, actual := route.expected, len(route.calls)
+		route.mu.Unlock()
+		if expected >= 0 && actual != expected {
+			t.Errorf("%s %s 期望调用 %d 次，实际 %d 次", route.method, route.pattern, expected, actual)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (m *MockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	call := &MockCall{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Header: req.Header.Clone(),
+		Body:   body,
+		Time:   time.Now(),
+	}
+
+	m.mu.Lock()
+	m.calls = append(m.calls, call)
+	var route *MockRoute
+	for _, r := range m.routes {
+		if r.match(req, body) {
+			route = r
+			break
+		}
+	}
+	fallback := m.fallback
+	m.mu.Unlock()
+
+	if route == nil {
+		if fallback != nil {
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			return fallback.RoundTrip(req)
+		}
+		return nil, fmt.Errorf("%w: %s %s", ErrMockNoRoute, req.Method, req.URL)
+	}
+	return route.respond(req, call)
+}
+
+// MockRoute 模拟路由
+type MockRoute struct {
+	mu        sync.Mutex
+	method    string
+	pattern   string
+	query     map[string]string
+	header    map[string]string
+	bodyMatch func(body []byte) bool
+	replies   []mockReply
+	delay     time.Duration
+	times     int // 最多匹配次数（0 不限制）
+	expected  int // 期望调用次数（-1 不检查）
+	calls     []*MockCall
+}
+
+// mockReply 预设响应或错误
+type mockReply struct {
+	status  int
+	header  http.Header
+	body    []byte
+	err     error
+	handler func(req *http.Request) (*http.Response, error)
+}
+
+// WithQuery 要求查询参数匹配
+func (r *MockRoute) WithQuery(key, value string) *MockRoute {
+	r.query[key] = value
+	return r
+}
+
+// WithHeader 要求请求头匹配
+func (r *MockRoute) WithHeader(key, value string) *MockRoute {
+	r.header[key] = value
+	return r
+}
+
+// WithBody 要求请求体满足条件
+func (r *MockRoute) WithBody(match func(body []byte) bool) *MockRoute {
+	r.bodyMatch = match
+	return r
+}
+
+// Reply 返回预设响应（body 为 []byte、string 原样返回，其他类型JSON序列化）
+// 多次调用时按顺序依次返回，最后一个响应重复使用
+func (r *MockRoute) Reply(status int, body interface{}) *MockRoute {
+	reply := mockReply{status: status, header: make(http.Header)}
+	switch v := body.(type) {
+	case nil:
+	case []byte:
+		reply.body = v
+	case string:
+		reply.body = []byte(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			reply.err = fmt.Errorf("序列化模拟响应失败: %w", err)
+		}
+		reply.body = data
+		reply.header.Set("Content-Type", "application/json")
+	}
+	return r.addReply(reply)
+}
+
+// ReplyJSON 返回JSON响应
+func (r *MockRoute) ReplyJSON(status int, v interface{}) *MockRoute {
+	return r.Reply(status, v).SetHeader("Content-Type", "application/json")
+}
+
+// ReplyResponse 返回预设的 Response（状态码、响应头、响应体）
+func (r *MockRoute) ReplyResponse(resp *Response) *MockRoute {
+	header := make(http.Header)
+	for k, v := range resp.Headers {
+		header[k] = append([]string(nil), v...)
+	}
+	for _, cookie := range resp.Cookies {
+		header.Add("Set-Cookie", cookie.String())
+	}
+	status := resp.StatusCode
+	if status == 0 {
+		status = http.StatusOK
+	}
+	return r.addReply(mockReply{status: status, header: header, body: resp.Body})
+}
+
+// ReplyError 模拟网络错误（如连接被拒绝、超时）
+func (r *MockRoute) ReplyError(err error) *MockRoute {
+	return r.addReply(mockReply{err: err})
+}
+
+// ReplyFunc 使用自定义函数生成响应
+func (r *MockRoute) ReplyFunc(fn func(req *http.Request) (*http.Response, error)) *MockRoute {
+	return r.addReply(mockReply{handler: fn})
+}
+
+// SetHeader 为最近一个响应设置响应头
+func (r *MockRoute) SetHeader(key, value string) *MockRoute {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.replies) > 0 && r.replies[len(r.replies)-1].header != nil {
+		r.replies[len(r.replies)-1].header.Set(key, value)
+	}
+	return r
+}
+
+// Delay 模拟响应延迟（请求取消或超时时提前返回）
+func (r *MockRoute) Delay(d time.Duration) *MockRoute {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.delay = d
+	return r
+}
+
+// Times 最多匹配 n 次，之后交给后面的路由
+func (r *MockRoute) Times(n int) *MockRoute {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.times = n
+	return r
+}
+
+// Expect 设置期望调用次数（由 AssertExpectations 检查）
+func (r *MockRoute) Expect(n int) *MockRoute {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.expected = n
+	return r
+}
+
+// CallCount 路由被调用次数
+func (r *MockRoute) CallCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.calls)
+}
+
+// Calls 路由收到的请求
+func (r *MockRoute) Calls() []*MockCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*MockCall(nil), r.calls...)
+}
+
+// LastCall 路由收到的最后一个请求（没有时返回 nil）
+func (r *MockRoute) LastCall() *MockCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.calls) == 0 {
+		return nil
+	}
+	return r.calls[len(r.calls)-1]
+}
+
+func (r *MockRoute) addReply(reply mockReply) *MockRoute {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.replies = append(r.replies, reply)
+	return r
+}
+
+// match 判断请求是否匹配（匹配成功时占用一次调用次数）
+func (r *MockRoute) match(req *http.Request, body []byte) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.times > 0 && len(r.calls) >= r.times {
+		return false
+	}
+	if !r.matchMethodURL(req.Method, req.URL.String()) {
+		return false
+	}
+	query := req.URL.Query()
+	for k, v := range r.query {
+		if query.Get(k) != v {
+			return false
+		}
+	}
+	for k, v := range r.header {
+		if req.Header.Get(k) != v {
+			return false
+		}
+	}
+	if r.bodyMatch != nil && !r.bodyMatch(body) {
+		return false
+	}
+	// 预占调用记录，保证 Times 在并发下准确
+	r.calls = append(r.calls, nil)
+	return true
+}
+
+// matchMethodURL 匹配方法和URL
+func (r *MockRoute) matchMethodURL(method, rawURL string) bool {
+	if r.method != "" && r.method != "*" && r.method != strings.ToUpper(method) {
+		return false
+	}
+	target := rawURL
+	if i := strings.IndexByte(target, '?'); i >= 0 {
+		target = target[:i]
+	}
+	if strings.HasPrefix(r.pattern, "/") {
+		if i := strings.Index(target, "://"); i >= 0 {
+			target = target[i+3:]
+			if j := strings.IndexByte(target, '/'); j >= 0 {
+				target = target[j:]
+			} else {
+				target = "/"
+			}
+		}
+	}
+	if strings.HasSuffix(r.pattern, "*") && strings.HasPrefix(target, strings.TrimSuffix(r.pattern, "*")) {
+		return true
+	}
+	if target == r.pattern {
+		return true
+	}
+	ok, _ := path.Match(r.pattern, target)
+	return ok
+}
+
+// respond 生成响应
+func (r *MockRoute) respond(req *http.Request, call *MockCall) (*http.Response, error) {
+	r.mu.Lock()
+	// 替换 match 中预占的记录
+	for i := len(r.calls) - 1; i >= 0; i-- {
+		if r.calls[i] == nil {
+			r.calls[i] = call
+			break
+		}
+	}
+	index := len(r.calls) - 1
+	var reply mockReply
+	if len(r.replies) > 0 {
+		if index >= len(r.replies) {
+			index = len(r.replies) - 1
+		}
+		reply = r.replies[index]
+	} else {
+		reply = mockReply{status: http.StatusOK}
+	}
+	delay := r.delay
+	r.mu.Unlock()
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+
+	if reply.handler != nil {
+		return reply.handler(req)
+	}
+	if reply.err != nil {
+		return nil, reply.err
+	}
+
+	header := reply.header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", reply.status, http.StatusText(reply.status)),
+		StatusCode:    reply.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(reply.body)),
+		ContentLength: int64(len(reply.body)),
+		Request:       req,
+	}, nil
+}
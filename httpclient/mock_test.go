@@ -0,0 +1,287 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeT 记录 AssertExpectations 的错误
+type fakeT struct {
+	errors []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func TestMockRouteMatching(t *testing.T) {
+	client, mock := NewMockClient()
+	client.SetBaseURL("https://api.test")
+
+	mock.On("GET", "/users/*").Reply(200, "user")
+	mock.On("GET", "/items/?/detail").Reply(200, "item")
+	mock.On("POST", "https://api.test/orders").Reply(201, "order")
+	mock.On("GET", "/search").WithQuery("q", "go").Reply(200, "search go")
+	mock.On("GET", "/search").Reply(200, "search")
+	mock.On("GET", "/me").WithHeader("X-Token", "t1").Reply(200, "me")
+	mock.On("*", "/echo").WithBody(func(body []byte) bool { return strings.Contains(string(body), "ping") }).Reply(200, "pong")
+
+	tests := []struct {
+		method string
+		url    string
+		opts   *Options
+		body   interface{}
+		want   string
+		status int
+	}{
+		{"GET", "/users/42", nil, nil, "user", 200},
+		{"GET", "/users/42/posts", nil, nil, "user", 200},
+		{"GET", "/items/7/detail", nil, nil, "item", 200},
+		{"POST", "/orders", nil, "{}", "order", 201},
+		{"GET", "/search?q=go", nil, nil, "search go", 200},
+		{"GET", "/search?q=rust", nil, nil, "search", 200},
+		{"GET", "/me", &Options{Headers: map[string]string{"X-Token": "t1"}}, nil, "me", 200},
+		{"PUT", "/echo", nil, "ping", "pong", 200},
+	}
+	for _, tt := range tests {
+		var resp *Response
+		var err error
+		switch tt.method {
+		case "GET":
+			resp, err = client.Get(tt.url, tt.opts)
+		case "POST":
+			resp, err = client.Post(tt.url, tt.body, tt.opts)
+		case "PUT":
+			resp, err = client.Put(tt.url, tt.body, tt.opts)
+		}
+		if err != nil {
+			t.Errorf("%s %s: %v", tt.method, tt.url, err)
+			continue
+		}
+		if resp.StatusCode != tt.status || resp.Text() != tt.want {
+			t.Errorf("%s %s = %d %q, want %d %q", tt.method, tt.url, resp.StatusCode, resp.Text(), tt.status, tt.want)
+		}
+	}
+
+	// 方法、请求头、请求体不匹配
+	misses := []func() (*Response, error){
+		func() (*Response, error) { return client.Post("/users/1", nil, nil) },
+		func() (*Response, error) { return client.Get("/me", nil) },
+		func() (*Response, error) { return client.Put("/echo", "hello", nil) },
+		func() (*Response, error) { return client.Get("/items/77/detail", nil) },
+	}
+	for i, fn := range misses {
+		if _, err := fn(); !errors.Is(err, ErrMockNoRoute) {
+			t.Errorf("miss %d: err = %v, want ErrMockNoRoute", i, err)
+		}
+	}
+}
+
+func TestMockRoutePriorityAndTimes(t *testing.T) {
+	client, mock := NewMockClient()
+
+	// 先注册的优先，Times 用完后交给后面的路由
+	first := mock.On("GET", "https://api.test/token").Times(2).Reply(200, "first")
+	second := mock.On("GET", "https://api.test/token").Reply(200, "second")
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		resp, err := client.Get("https://api.test/token", nil)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		got = append(got, resp.Text())
+	}
+	if strings.Join(got, ",") != "first,first,second,second" {
+		t.Errorf("responses = %v", got)
+	}
+	if first.CallCount() != 2 || second.CallCount() != 2 {
+		t.Errorf("CallCount = %d, %d", first.CallCount(), second.CallCount())
+	}
+}
+
+func TestMockSequentialReplies(t *testing.T) {
+	client, mock := NewMockClient()
+	mock.On("GET", "/job").
+		Reply(202, "pending").
+		Reply(202, "running").
+		ReplyJSON(200, map[string]string{"state": "done"})
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		resp, err := client.Get("http://svc.test/job", nil)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		got = append(got, fmt.Sprintf("%d %s", resp.StatusCode, resp.Text()))
+	}
+	want := []string{"202 pending", "202 running", `200 {"state":"done"}`, `200 {"state":"done"}`}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("responses = %q, want %q", got, want)
+	}
+}
+
+func TestMockCallCounting(t *testing.T) {
+	client, mock := NewMockClient()
+	users := mock.On("GET", "/users/*").Reply(200, "ok")
+	create := mock.On("POST", "/users").Reply(201, "created")
+
+	client.Get("http://svc.test/users/1", nil)
+	client.Get("http://svc.test/users/2?full=1", nil)
+	client.Post("http://svc.test/users", map[string]string{"name": "bob"}, nil)
+
+	if n := users.CallCount(); n != 2 {
+		t.Errorf("users.CallCount = %d, want 2", n)
+	}
+	if n := create.CallCount(); n != 1 {
+		t.Errorf("create.CallCount = %d, want 1", n)
+	}
+	if n := mock.CallCount("GET", "/users/*"); n != 2 {
+		t.Errorf("mock.CallCount(GET) = %d, want 2", n)
+	}
+	if n := mock.CallCount("*", "/users*"); n != 3 {
+		t.Errorf("mock.CallCount(*) = %d, want 3", n)
+	}
+	if n := len(mock.Calls()); n != 3 {
+		t.Errorf("len(Calls) = %d, want 3", n)
+	}
+
+	last := users.LastCall()
+	if last == nil || last.URL != "http://svc.test/users/2?full=1" {
+		t.Errorf("LastCall = %+v", last)
+	}
+	if call := create.LastCall(); call == nil || string(call.Body) != `{"name":"bob"}` || call.Method != "POST" {
+		t.Errorf("create.LastCall = %+v", call)
+	}
+
+	mock.Reset()
+	if len(mock.Calls()) != 0 {
+		t.Error("Reset 后仍有请求记录")
+	}
+	if _, err := client.Get("http://svc.test/users/1", nil); !errors.Is(err, ErrMockNoRoute) {
+		t.Errorf("Reset 后 err = %v, want ErrMockNoRoute", err)
+	}
+}
+
+func TestMockConcurrentCalls(t *testing.T) {
+	client, mock := NewMockClient()
+	limited := mock.On("GET", "/n").Times(5).Reply(200, "limited")
+	rest := mock.On("GET", "/n").Reply(200, "rest")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Get("http://svc.test/n", nil); err != nil {
+				t.Errorf("Get: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if limited.CallCount() != 5 || rest.CallCount() != 15 {
+		t.Errorf("CallCount = %d, %d, want 5, 15", limited.CallCount(), rest.CallCount())
+	}
+	if len(mock.Calls()) != 20 {
+		t.Errorf("len(Calls) = %d", len(mock.Calls()))
+	}
+}
+
+func TestMockAssertExpectations(t *testing.T) {
+	client, mock := NewMockClient()
+	mock.On("GET", "/a").Expect(1)
+	mock.On("GET", "/b").Expect(2)
+	mock.On("GET", "/c") // 未设置期望，不检查
+
+	client.Get("http://svc.test/a", nil)
+	client.Get("http://svc.test/b", nil)
+
+	ft := &fakeT{}
+	if mock.AssertExpectations(ft) {
+		t.Error("AssertExpectations 应返回 false")
+	}
+	if len(ft.errors) != 1 || !strings.Contains(ft.errors[0], "/b") {
+		t.Errorf("errors = %q", ft.errors)
+	}
+
+	client.Get("http://svc.test/b", nil)
+	ft = &fakeT{}
+	if !mock.AssertExpectations(ft) || len(ft.errors) != 0 {
+		t.Errorf("AssertExpectations errors = %q", ft.errors)
+	}
+}
+
+func TestMockNoRoute(t *testing.T) {
+	client, mock := NewMockClient()
+	mock.On("GET", "/known")
+
+	_, err := client.Get("http://svc.test/unknown?x=1", nil)
+	if !errors.Is(err, ErrMockNoRoute) {
+		t.Fatalf("err = %v, want ErrMockNoRoute", err)
+	}
+	if !strings.Contains(err.Error(), "GET http://svc.test/unknown?x=1") {
+		t.Errorf("错误信息缺少请求: %v", err)
+	}
+	// 未匹配的请求也会被记录
+	if n := mock.CallCount("GET", "/unknown"); n != 1 {
+		t.Errorf("CallCount = %d, want 1", n)
+	}
+
+	// 未注册回复的路由默认返回 200
+	resp, err := client.Get("http://svc.test/known", nil)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Errorf("默认回复 = %v, %v", resp, err)
+	}
+}
+
+func TestMockFallback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("real " + r.URL.Path))
+	}))
+	defer srv.Close()
+
+	client, mock := NewMockClient()
+	mock.On("GET", "/mocked").Reply(200, "mocked")
+	mock.SetFallback(http.DefaultTransport)
+
+	resp, err := client.Get(srv.URL+"/mocked", nil)
+	if err != nil || resp.Text() != "mocked" {
+		t.Errorf("mocked = %v, %v", resp, err)
+	}
+	resp, err = client.Get(srv.URL+"/other", nil)
+	if err != nil || resp.Text() != "real /other" {
+		t.Errorf("fallback = %v, %v", resp, err)
+	}
+}
+
+func TestMockReplyErrorAndDelay(t *testing.T) {
+	client, mock := NewMockClient()
+	refused := errors.New("connection refused")
+	mock.On("GET", "/down").ReplyError(refused)
+	mock.On("GET", "/slow").Delay(time.Second).Reply(200, "slow")
+
+	if _, err := client.Get("http://svc.test/down", nil); !errors.Is(err, refused) {
+		t.Errorf("err = %v, want %v", err, refused)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.Get("http://svc.test/slow", &Options{Context: ctx})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want DeadlineExceeded", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("取消后未提前返回: %v", time.Since(start))
+	}
+}
@@ -0,0 +1,342 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit 限流配置
+type RateLimit struct {
+	RPS           float64       // 每秒请求数（0 不限制）
+	Burst         int           // 令牌桶容量（默认为 RPS 向上取整，最小1）
+	MaxConcurrent int           // 最大并发请求数（0 不限制）
+	MinDelay      time.Duration // 两次请求之间的最小随机延迟
+	MaxDelay      time.Duration // 两次请求之间的最大随机延迟
+}
+
+// AllHosts 作为 SetHostRateLimit 的 host 参数时，表示对每个未单独配置的host分别限流
+const AllHosts = "*"
+
+// SetRateLimit 设置全局限流（所有host共享）
+func (c *Client) SetRateLimit(limit RateLimit) *Client {
+	c.rateLimiter.setGlobal(limit)
+	return c
+}
+
+// SetHostRateLimit 设置指定host的限流（每个host独立计数）
+// 重定向和重新认证发出的请求同样按目标host消耗令牌，并发名额按原请求计算
+// host 为域名（不含端口），传入 AllHosts 表示默认的单host限流
+func (c *Client) SetHostRateLimit(host string, limit RateLimit) *Client {
+	c.rateLimiter.setHost(host, limit)
+	return c
+}
+
+// ClearRateLimit 清除所有限流配置
+func (c *Client) ClearRateLimit() *Client {
+	c.rateLimiter.clear()
+	return c
+}
+
+// limitRedirect 重定向的每一跳同样等待限流令牌
+func (c *Client) limitRedirect(check func(*http.Request, []*http.Request) error) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if check != nil {
+			if err := check(req, via); err != nil {
+				return err
+			}
+		} else if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return c.rateLimiter.wait(req.Context(), req.URL.Hostname())
+	}
+}
+
+// hostIdleTimeout 按 AllHosts 创建的host限流器空闲多久后回收
+const hostIdleTimeout = 10 * time.Minute
+
+// rateLimiter 客户端限流器（全局 + 按host）
+type rateLimiter struct {
+	mu          sync.Mutex
+	global      *limiter
+	hostConfigs map[string]RateLimit
+	hosts       map[string]*limiter
+	lastSweep   time.Time // 上次回收空闲限流器的时间
+}
+
+// newRateLimiter 创建限流器
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		hostConfigs: make(map[string]RateLimit),
+		hosts:       make(map[string]*limiter),
+	}
+}
+
+// setGlobal 设置全局限流
+func (r *rateLimiter) setGlobal(limit RateLimit) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.global = newLimiter(limit)
+}
+
+// setHost 设置host限流
+func (r *rateLimiter) setHost(host string, limit RateLimit) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	host = strings.ToLower(host)
+	r.hostConfigs[host] = limit
+	// 配置变更后重新创建对应的限流器
+	if host == AllHosts {
+		for h := range r.hosts {
+			if _, ok := r.hostConfigs[h]; !ok {
+				delete(r.hosts, h)
+			}
+		}
+	} else {
+		delete(r.hosts, host)
+	}
+}
+
+// clear 清除所有限流配置
+func (r *rateLimiter) clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.global = nil
+	r.hostConfigs = make(map[string]RateLimit)
+	r.hosts = make(map[string]*limiter)
+}
+
+// hostLimiter 获取host对应的限流器（未配置时返回nil）
+func (r *rateLimiter) hostLimiter(host string) *limiter {
+	host = strings.ToLower(host)
+	if l, ok := r.hosts[host]; ok {
+		return l
+	}
+	limit, ok := r.hostConfigs[host]
+	if !ok {
+		limit, ok = r.hostConfigs[AllHosts]
+		if !ok {
+			return nil
+		}
+		// AllHosts 会为每个host创建限流器，定期回收空闲的
+		r.sweepIdle()
+	}
+	l := newLimiter(limit)
+	r.hosts[host] = l
+	return l
+}
+
+// sweepIdle 回收按 AllHosts 创建且长时间空闲的限流器（需持有 r.mu）
+func (r *rateLimiter) sweepIdle() {
+	now := time.Now()
+	if now.Sub(r.lastSweep) < hostIdleTimeout/2 {
+		return
+	}
+	r.lastSweep = now
+	for host, l := range r.hosts {
+		if _, configured := r.hostConfigs[host]; configured {
+			continue
+		}
+		if l.idleSince(now) > hostIdleTimeout {
+			delete(r.hosts, host)
+		}
+	}
+}
+
+// wait 等待令牌和间隔，不占用并发名额（重定向、重新认证等同一次调用内的后续请求）
+func (r *rateLimiter) wait(ctx context.Context, host string) error {
+	r.mu.Lock()
+	global := r.global
+	hostL := r.hostLimiter(host)
+	r.mu.Unlock()
+
+	if err := hostL.wait(ctx); err != nil {
+		return err
+	}
+	return global.wait(ctx)
+}
+
+// acquire 等待获取请求许可，返回释放函数
+func (r *rateLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	r.mu.Lock()
+	global := r.global
+	hostL := r.hostLimiter(host)
+	r.mu.Unlock()
+
+	// 先等待host的名额，避免排队等待某个慢host时占用全局名额阻塞其他host
+	releaseHost, err := hostL.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	releaseGlobal, err := global.acquire(ctx)
+	if err != nil {
+		releaseHost()
+		return nil, err
+	}
+	return func() {
+		releaseHost()
+		releaseGlobal()
+	}, nil
+}
+
+// limiter 单个限流器：令牌桶 + 并发信号量 + 随机间隔
+type limiter struct {
+	mu       sync.Mutex
+	limit    RateLimit
+	tokens   float64
+	last     time.Time     // 上次补充令牌的时间
+	nextSlot time.Time     // 下一次允许请求的时间（随机间隔）
+	sem      chan struct{} // 并发信号量
+	active   int           // 正在等待或进行中的请求数
+	lastUsed time.Time     // 最近一次使用时间
+}
+
+// newLimiter 创建限流器
+func newLimiter(limit RateLimit) *limiter {
+	if limit.Burst <= 0 {
+		limit.Burst = int(math.Max(1, math.Ceil(limit.RPS)))
+	}
+	if limit.MaxDelay < limit.MinDelay {
+		limit.MaxDelay = limit.MinDelay
+	}
+	now := time.Now()
+	l := &limiter{
+		limit:    limit,
+		tokens:   float64(limit.Burst),
+		last:     now,
+		lastUsed: now,
+	}
+	if limit.MaxConcurrent > 0 {
+		l.sem = make(chan struct{}, limit.MaxConcurrent)
+	}
+	return l
+}
+
+// acquire 等待令牌、间隔和并发名额，返回释放函数
+func (l *limiter) acquire(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	l.mu.Lock()
+	l.active++
+	l.lastUsed = time.Now()
+	l.mu.Unlock()
+	done := func() {
+		l.mu.Lock()
+		l.active--
+		l.lastUsed = time.Now()
+		l.mu.Unlock()
+	}
+
+	// 先占用并发名额，避免排队期间消耗令牌
+	if l.sem != nil {
+		select {
+		case l.sem <- struct{}{}:
+		case <-ctx.Done():
+			done()
+			return nil, ctx.Err()
+		}
+	}
+	release := func() {
+		if l.sem != nil {
+			<-l.sem
+		}
+		done()
+	}
+
+	if err := l.wait(ctx); err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
+
+// wait 等待令牌和随机间隔（取消时撤销预约）
+func (l *limiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	l.lastUsed = time.Now()
+	l.mu.Unlock()
+
+	wait, cancel := l.reserve()
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		cancel()
+		return ctx.Err()
+	}
+}
+
+// reserve 预约一个请求时间点，返回需要等待的时间和取消预约函数
+func (l *limiter) reserve() (time.Duration, func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	at := now
+
+	// 令牌桶
+	tookToken := false
+	if l.limit.RPS > 0 {
+		elapsed := now.Sub(l.last).Seconds()
+		l.tokens = math.Min(float64(l.limit.Burst), l.tokens+elapsed*l.limit.RPS)
+		l.last = now
+		l.tokens--
+		tookToken = true
+		if l.tokens < 0 {
+			at = now.Add(time.Duration(-l.tokens / l.limit.RPS * float64(time.Second)))
+		}
+	}
+
+	// 随机间隔
+	prevSlot := l.nextSlot
+	var slot time.Time
+	if l.limit.MaxDelay > 0 {
+		if at.Before(l.nextSlot) {
+			at = l.nextSlot
+		}
+		delay := l.limit.MinDelay
+		if l.limit.MaxDelay > l.limit.MinDelay {
+			delay += time.Duration(rand.Int63n(int64(l.limit.MaxDelay - l.limit.MinDelay)))
+		}
+		slot = at.Add(delay)
+		l.nextSlot = slot
+	}
+
+	cancel := func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if tookToken {
+			l.tokens++
+		}
+		// 之后没有新的预约时撤销本次占用的间隔
+		if !slot.IsZero() && l.nextSlot.Equal(slot) {
+			l.nextSlot = prevSlot
+		}
+	}
+	return at.Sub(now), cancel
+}
+
+// idleSince 空闲时长（有请求在等待或进行中时为0）
+func (l *limiter) idleSince(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.active > 0 {
+		return 0
+	}
+	return now.Sub(l.lastUsed)
+}
@@ -0,0 +1,178 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// elapsed 执行 fn 的耗时
+func elapsed(fn func()) time.Duration {
+	start := time.Now()
+	fn()
+	return time.Since(start)
+}
+
+func TestLimiterBurst(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    RateLimit
+		requests int
+		min, max time.Duration
+	}{
+		{"突发容量内不等待", RateLimit{RPS: 20, Burst: 3}, 3, 0, 30 * time.Millisecond},
+		{"超出突发容量按速率等待", RateLimit{RPS: 20, Burst: 3}, 5, 90 * time.Millisecond, 200 * time.Millisecond},
+		{"默认容量为RPS向上取整", RateLimit{RPS: 1.5}, 2, 0, 30 * time.Millisecond},
+		{"固定间隔", RateLimit{MinDelay: 30 * time.Millisecond}, 3, 55 * time.Millisecond, 150 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newLimiter(tt.limit)
+			d := elapsed(func() {
+				for i := 0; i < tt.requests; i++ {
+					release, err := l.acquire(context.Background())
+					if err != nil {
+						t.Fatal(err)
+					}
+					release()
+				}
+			})
+			if d < tt.min || d > tt.max {
+				t.Errorf("耗时 %v, want [%v, %v]", d, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestLimiterCancelRestoresToken(t *testing.T) {
+	l := newLimiter(RateLimit{RPS: 5, Burst: 1})
+	release, _ := l.acquire(context.Background())
+	release()
+
+	// 等待中取消，令牌归还
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v", err)
+	}
+	if d := elapsed(func() { l.acquire(context.Background()) }); d > 250*time.Millisecond {
+		t.Errorf("取消的预约未归还令牌: 等待 %v", d)
+	}
+}
+
+func TestLimiterMaxConcurrent(t *testing.T) {
+	l := newLimiter(RateLimit{MaxConcurrent: 2})
+	var running, peak int32
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, _ := l.acquire(context.Background())
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			release()
+		}()
+	}
+	wg.Wait()
+	if peak != 2 {
+		t.Errorf("最大并发 = %d, want 2", peak)
+	}
+}
+
+func TestHostRateLimit(t *testing.T) {
+	client, mock := NewMockClient()
+	mock.On("GET", "*").Reply(200, "ok")
+	client.SetHostRateLimit("slow.test", RateLimit{RPS: 10, Burst: 1})
+
+	get := func(url string, n int) time.Duration {
+		return elapsed(func() {
+			for i := 0; i < n; i++ {
+				if _, err := client.Get(url, nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+
+	if d := get("http://fast.test/", 5); d > 50*time.Millisecond {
+		t.Errorf("未限流的host等待了 %v", d)
+	}
+	if d := get("http://SLOW.test:8080/", 3); d < 180*time.Millisecond {
+		t.Errorf("限流的host只等待了 %v", d)
+	}
+
+	// AllHosts 每个host独立计数
+	client.ClearRateLimit()
+	client.SetHostRateLimit(AllHosts, RateLimit{RPS: 10, Burst: 1})
+	d := elapsed(func() {
+		for _, host := range []string{"a.test", "b.test", "c.test"} {
+			client.Get("http://"+host+"/", nil)
+		}
+	})
+	if d > 50*time.Millisecond {
+		t.Errorf("不同host互相限流: %v", d)
+	}
+	if d := get("http://a.test/", 1); d < 80*time.Millisecond {
+		t.Errorf("同一host未限流: %v", d)
+	}
+
+	// 全局限流所有host共享
+	client.ClearRateLimit()
+	client.SetRateLimit(RateLimit{RPS: 10, Burst: 1})
+	d = elapsed(func() {
+		for _, host := range []string{"a.test", "b.test", "c.test"} {
+			client.Get("http://"+host+"/", nil)
+		}
+	})
+	if d < 180*time.Millisecond {
+		t.Errorf("全局限流未生效: %v", d)
+	}
+}
+
+func TestRateLimitRedirectHops(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		switch r.URL.Path {
+		case "/a":
+			http.Redirect(w, r, "/b", http.StatusFound)
+		case "/b":
+			http.Redirect(w, r, "/c", http.StatusFound)
+		default:
+			w.Write([]byte("done"))
+		}
+	}))
+	defer srv.Close()
+
+	client := New().SetHostRateLimit(AllHosts, RateLimit{RPS: 20, Burst: 1})
+	var resp *Response
+	var err error
+	d := elapsed(func() { resp, err = client.Get(srv.URL+"/a", nil) })
+	if err != nil || resp.Text() != "done" {
+		t.Fatalf("Get = %v, %v", resp, err)
+	}
+	// 3 次请求各消耗一个令牌，后两跳各等待 50ms
+	if hits != 3 || d < 90*time.Millisecond {
+		t.Errorf("hits=%d 耗时=%v，重定向未经过限流", hits, d)
+	}
+
+	// 等待限流时取消
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.Get(srv.URL+"/a", &Options{Context: ctx}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want DeadlineExceeded", err)
+	}
+}
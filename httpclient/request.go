@@ -3,6 +3,7 @@ package httpclient
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -22,6 +23,10 @@ type Options struct {
 	Cookies        map[string]string // Cookie
 	Timeout        time.Duration     // 超时时间
 	AllowRedirects *bool             // 是否允许重定向
+	Context        context.Context   // 请求上下文（用于取消请求和限流等待）
+	Compression    Compression       // 请求体压缩（覆盖客户端设置，CompressIdentity 不压缩）
+	MaxBodySize    int64             // 响应体大小限制（覆盖客户端设置，-1 不限制）
+	DiscardBody    bool              // 不读取响应体（只需要状态码和响应头时使用）
 }
 
 // Get 发送GET请求
@@ -77,6 +82,48 @@ func (c *Client) PostJSON(urlStr string, data interface{}, opts *Options) (*Resp
 	return c.doRequest("POST", urlStr, jsonBytes, opts)
 }
 
+// PostXML 发送XML数据
+func (c *Client) PostXML(urlStr string, data interface{}, opts *Options) (*Response, error) {
+	return c.postEncoded(urlStr, ContentTypeXML, data, opts)
+}
+
+// PostMsgpack 发送MessagePack数据
+func (c *Client) PostMsgpack(urlStr string, data interface{}, opts *Options) (*Response, error) {
+	return c.postEncoded(urlStr, ContentTypeMsgpack, data, opts)
+}
+
+// PostProtobuf 发送Protobuf数据（data 必须实现 proto.Message）
+func (c *Client) PostProtobuf(urlStr string, data interface{}, opts *Options) (*Response, error) {
+	return c.postEncoded(urlStr, ContentTypeProtobuf, data, opts)
+}
+
+// PostFormStruct 发送表单数据（结构体字段名使用 form 标签）
+func (c *Client) PostFormStruct(urlStr string, data interface{}, opts *Options) (*Response, error) {
+	return c.postEncoded(urlStr, ContentTypeForm, data, opts)
+}
+
+// postEncoded 使用指定 Content-Type 的编解码器编码后发送
+func (c *Client) postEncoded(urlStr, contentType string, data interface{}, opts *Options) (*Response, error) {
+	codec, ok := c.GetCodec(contentType)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoCodec, contentType)
+	}
+	body, err := codec.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求体失败: %w", err)
+	}
+
+	if opts == nil {
+		opts = &Options{}
+	}
+	if opts.Headers == nil {
+		opts.Headers = make(map[string]string)
+	}
+	opts.Headers["Content-Type"] = codec.ContentType()
+
+	return c.doRequest("POST", urlStr, body, opts)
+}
+
 // PostForm 发送表单数据
 func (c *Client) PostForm(urlStr string, data map[string]string, opts *Options) (*Response, error) {
 	formData := make(url.Values)
@@ -187,11 +234,67 @@ func (c *Client) PostFile(urlStr string, fieldName string, filePath string, opts
 	}, opts)
 }
 
+// setHeadersAndCookies 设置默认和请求级的headers、cookies
+func (c *Client) setHeadersAndCookies(req *http.Request, headers, cookies map[string]string) {
+	// 设置默认headers
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+
+	// 轮换浏览器请求头（先清除默认请求头中的浏览器指纹，避免 Firefox 请求带上 Chrome 的 Sec-Ch-Ua）
+	if c.browserRotation != nil {
+		for _, key := range browserHeaderKeys {
+			req.Header.Del(key)
+		}
+		for k, v := range c.browserRotation.Generate().Headers() {
+			req.Header.Set(k, v)
+		}
+	}
+
+	// 设置请求headers
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	// 设置cookies
+	c.addClientCookies(req)
+	for k, v := range cookies {
+		req.AddCookie(&http.Cookie{Name: k, Value: v})
+	}
+}
+
+// replayable 请求体是否可以重放（无请求体或有 GetBody）
+func replayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// addClientCookies 添加客户端保存的Cookie
+func (c *Client) addClientCookies(req *http.Request) {
+	c.cookieMu.RLock()
+	defer c.cookieMu.RUnlock()
+	for k, v := range c.cookies {
+		req.AddCookie(&http.Cookie{Name: k, Value: v})
+	}
+}
+
+// saveCookies 保存响应下发的Cookie
+func (c *Client) saveCookies(cookies []*http.Cookie) {
+	if len(cookies) == 0 {
+		return
+	}
+	c.cookieMu.Lock()
+	defer c.cookieMu.Unlock()
+	for _, cookie := range cookies {
+		c.cookies[cookie.Name] = cookie.Value
+	}
+}
+
 // doRequest 执行HTTP请求
 func (c *Client) doRequest(method, urlStr string, body interface{}, opts *Options) (*Response, error) {
 	if opts == nil {
 		opts = &Options{}
 	}
+	urlStr = c.resolveURL(urlStr)
 
 	// 构建URL参数
 	if opts.Params != nil {
@@ -209,6 +312,7 @@ func (c *Client) doRequest(method, urlStr string, body interface{}, opts *Option
 
 	// 构建请求体
 	var bodyReader io.Reader
+	var bodyContentType string
 	if body != nil {
 		switch v := body.(type) {
 		case []byte:
@@ -218,96 +322,217 @@ func (c *Client) doRequest(method, urlStr string, body interface{}, opts *Option
 		case io.Reader:
 			bodyReader = v
 		default:
-			// 尝试JSON序列化
-			jsonBytes, err := json.Marshal(v)
+			// 按 Content-Type 选择编解码器序列化（默认JSON）
+			contentType := headerValue(opts.Headers, "Content-Type")
+			if contentType == "" {
+				contentType = headerValue(c.headers, "Content-Type")
+			}
+			data, ct, err := c.encodeBody(v, contentType)
 			if err != nil {
 				return nil, fmt.Errorf("序列化请求体失败: %w", err)
 			}
-			bodyReader = bytes.NewReader(jsonBytes)
+			bodyReader = bytes.NewReader(data)
+			bodyContentType = ct
 		}
 	}
 
-	// 创建请求
-	req, err := http.NewRequest(method, urlStr, bodyReader)
+	// 压缩请求体
+	var contentEncoding Compression
+	if bodyReader != nil {
+		if algo := c.requestCompression(opts); algo != CompressNone {
+			compressed, ok, err := c.compressBody(bodyReader, algo)
+			if err != nil {
+				return nil, err
+			}
+			bodyReader = compressed
+			if ok {
+				contentEncoding = algo
+			}
+		}
+	}
+
+	// 创建请求（挂载计时器）
+	timer := newTimingCollector()
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	req, err := http.NewRequestWithContext(timer.withContext(ctx), method, urlStr, bodyReader)
 	if err != nil {
 		return nil, fmt.Errorf("创建请求失败: %w", err)
 	}
 
-	// 设置默认headers
-	for k, v := range c.headers {
-		req.Header.Set(k, v)
+	// 设置headers和cookies
+	c.setHeadersAndCookies(req, opts.Headers, opts.Cookies)
+	if bodyContentType != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", bodyContentType)
+	}
+	if contentEncoding != CompressNone {
+		req.Header.Set("Content-Encoding", string(contentEncoding))
 	}
 
-	// 设置请求headers
-	if opts.Headers != nil {
-		for k, v := range opts.Headers {
-			req.Header.Set(k, v)
-		}
+	// HTTP缓存：查询缓存（过期缓存会添加条件请求头，设置了认证器时跳过）
+	requestTime := time.Now()
+	var fresh, stale *CachedResponse
+	var cacheKey string
+	if !opts.DiscardBody {
+		fresh, stale, cacheKey = c.cacheLookup(req)
 	}
 
-	// 设置cookies
-	for k, v := range c.cookies {
-		req.AddCookie(&http.Cookie{Name: k, Value: v})
+	// HAR录制：在发送前保存请求体副本
+	var harEx *harExchange
+	if c.harRecorder != nil {
+		harEx = &harExchange{
+			req:     req,
+			reqBody: snapshotRequestBody(req),
+			start:   timer.start,
+		}
 	}
-	if opts.Cookies != nil {
-		for k, v := range opts.Cookies {
-			req.AddCookie(&http.Cookie{Name: k, Value: v})
+
+	// 请求结束：统计耗时、调用日志钩子、录制HAR
+	// wire 统计实际传输的响应体大小（标准库自动解压时未知）
+	var wire *countingReader
+	finish := func(resp *http.Response, body []byte, err error) Timings {
+		timings := c.finishTiming(timer, req, resp, len(body), err)
+		wireSize := 0
+		if wire != nil {
+			wireSize = wire.n
+			if resp.Uncompressed {
+				wireSize = -1
+			}
 		}
+		c.recordHAR(harEx, resp, body, wireSize, timings, err)
+		return timings
 	}
 
-	// 保存原始配置
-	originalTimeout := c.httpClient.Timeout
-	originalRedirect := c.httpClient.CheckRedirect
+	// 命中新鲜缓存，直接返回
+	if fresh != nil {
+		cachedResp := fresh.httpResponse(req, nil)
+		body := cloneBytes(fresh.Body)
+		timings := finish(cachedResp, body, nil)
+		return &Response{
+			StatusCode:  fresh.StatusCode,
+			Status:      fresh.Status,
+			Headers:     cachedResp.Header,
+			Body:        body,
+			Request:     req,
+			Timings:     timings,
+			CacheStatus: CacheHit,
+			codecs:      c.codecs,
+		}, nil
+	}
 
-	// 临时修改配置
-	if opts.Timeout > 0 {
-		c.httpClient.Timeout = opts.Timeout
+	// 限流等待
+	waitStart := time.Now()
+	release, err := c.rateLimiter.acquire(ctx, req.URL.Hostname())
+	if err != nil {
+		finish(nil, nil, err)
+		return nil, fmt.Errorf("等待限流失败: %w", err)
+	}
+	defer release()
+	timer.setBlocked(time.Since(waitStart))
+
+	// 认证（在限流之后，保证签名时间戳准确）
+	if err := c.applyAuth(req); err != nil {
+		finish(nil, nil, err)
+		return nil, fmt.Errorf("认证失败: %w", err)
+	}
+	var authHeader http.Header
+	if c.auth != nil {
+		authHeader = req.Header.Clone()
 	}
 
-	if opts.AllowRedirects != nil && !*opts.AllowRedirects {
-		c.httpClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+	// 请求级配置使用副本，不修改共享的 httpClient（并发请求、SSE 后台重连会同时读取）
+	// 共享连接池的会话每次使用原客户端当前的 Transport（原客户端切换代理时会重建）
+	reqClient := *c.httpClient
+	reqClient.Transport = c.activeTransport()
+	httpClient := &reqClient
+	if opts.Timeout > 0 {
+		reqClient.Timeout = opts.Timeout
+	}
+	noRedirect := opts.AllowRedirects != nil && !*opts.AllowRedirects
+	if noRedirect {
+		reqClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
 			return http.ErrUseLastResponse
 		}
+	} else {
+		reqClient.CheckRedirect = c.limitRedirect(c.httpClient.CheckRedirect)
 	}
 
 	// 发送请求
-	resp, err := c.httpClient.Do(req)
+	resp, err := httpClient.Do(req)
 
-	// 恢复配置
-	c.httpClient.Timeout = originalTimeout
-	c.httpClient.CheckRedirect = originalRedirect
+	// 需要重新认证时重放一次（Digest 质询、Token 过期）
+	if err == nil && c.auth != nil {
+		resp, req, err = c.retryAuth(httpClient, req, resp, authHeader)
+		if harEx != nil {
+			harEx.req = req
+		}
+	}
+
+	// 307/308 需要重发请求体，流式请求体无法重放时标准库会直接返回重定向响应
+	if err == nil && !noRedirect && !replayable(req) && resp.Header.Get("Location") != "" &&
+		(resp.StatusCode == http.StatusTemporaryRedirect || resp.StatusCode == http.StatusPermanentRedirect) {
+		resp.Body.Close()
+		err = fmt.Errorf("%w: %s 重定向", ErrBodyNotReplayable, resp.Status)
+	}
 
 	if err != nil {
+		finish(nil, nil, err)
 		return nil, fmt.Errorf("请求失败: %w", err)
 	}
 	defer resp.Body.Close()
+	wire = &countingReader{r: resp.Body}
+
+	// 不读取响应体
+	if opts.DiscardBody {
+		discardBody(wire)
+		timings := finish(resp, nil, nil)
+		c.saveCookies(resp.Cookies())
+		return &Response{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Headers:    resp.Header,
+			Cookies:    resp.Cookies(),
+			Request:    req,
+			Timings:    timings,
+			codecs:     c.codecs,
+		}, nil
+	}
 
-	// 读取响应体（处理gzip压缩）
-	var reader io.Reader = resp.Body
+	// 读取响应体（处理gzip压缩，大小限制按解压后计算）
+	var reader io.Reader = wire
 	if resp.Header.Get("Content-Encoding") == "gzip" {
-		gzReader, err := gzip.NewReader(resp.Body)
+		gzReader, err := gzip.NewReader(wire)
 		if err != nil {
+			finish(resp, nil, err)
 			return nil, fmt.Errorf("创建gzip解压器失败: %w", err)
 		}
 		defer gzReader.Close()
 		reader = gzReader
 	}
-	respBody, err := io.ReadAll(reader)
+	respBody, err := readBody(resp, reader, c.bodyLimit(opts))
+	timings := finish(resp, respBody, err)
 	if err != nil {
 		return nil, fmt.Errorf("读取响应失败: %w", err)
 	}
 
+	// 更新缓存（304 时换成缓存的响应）
+	var cacheStatus CacheStatus
+	resp, respBody, cacheStatus = c.cacheUpdate(req, cacheKey, resp, respBody, stale, requestTime)
+
 	// 更新cookies
-	for _, cookie := range resp.Cookies() {
-		c.cookies[cookie.Name] = cookie.Value
-	}
+	c.saveCookies(resp.Cookies())
 
 	return &Response{
-		StatusCode: resp.StatusCode,
-		Status:     resp.Status,
-		Headers:    resp.Header,
-		Cookies:    resp.Cookies(),
-		Body:       respBody,
-		Request:    req,
+		StatusCode:  resp.StatusCode,
+		Status:      resp.Status,
+		Headers:     resp.Header,
+		Cookies:     resp.Cookies(),
+		Body:        respBody,
+		Request:     req,
+		Timings:     timings,
+		CacheStatus: cacheStatus,
+		codecs:      c.codecs,
 	}, nil
 }
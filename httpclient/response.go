@@ -2,17 +2,22 @@ package httpclient
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"net/http"
 )
 
 // Response HTTP响应
 type Response struct {
-	StatusCode int               // 状态码
-	Status     string            // 状态描述
-	Headers    http.Header       // 响应头
-	Cookies    []*http.Cookie    // 响应Cookie
-	Body       []byte            // 响应体
-	Request    *http.Request     // 原始请求
+	StatusCode  int            // 状态码
+	Status      string         // 状态描述
+	Headers     http.Header    // 响应头
+	Cookies     []*http.Cookie // 响应Cookie
+	Body        []byte         // 响应体
+	Request     *http.Request  // 原始请求
+	Timings     Timings        // 请求耗时
+	CacheStatus CacheStatus    // 缓存状态
+
+	codecs map[string]Codec // 客户端的编解码器
 }
 
 // Text 获取响应文本
@@ -30,6 +35,34 @@ func (r *Response) JSON(v interface{}) error {
 	return json.Unmarshal(r.Body, v)
 }
 
+// XML 解析XML响应到目标结构
+func (r *Response) XML(v interface{}) error {
+	return xml.Unmarshal(r.Body, v)
+}
+
+// Msgpack 解析MessagePack响应到目标结构
+func (r *Response) Msgpack(v interface{}) error {
+	return MsgpackCodec{}.Unmarshal(r.Body, v)
+}
+
+// Protobuf 解析Protobuf响应到目标消息（v 必须实现 proto.Message）
+func (r *Response) Protobuf(v interface{}) error {
+	return ProtobufCodec{}.Unmarshal(r.Body, v)
+}
+
+// Decode 按响应的 Content-Type 选择编解码器解析（没有匹配时按JSON解析）
+func (r *Response) Decode(v interface{}) error {
+	codecs := r.codecs
+	if codecs == nil {
+		codecs = defaultCodecs()
+	}
+	codec, ok := lookupCodec(codecs, r.ContentType())
+	if !ok {
+		return json.Unmarshal(r.Body, v)
+	}
+	return codec.Unmarshal(r.Body, v)
+}
+
 // JSONMap 解析JSON响应为map
 func (r *Response) JSONMap() (map[string]interface{}, error) {
 	var result map[string]interface{}
@@ -112,3 +145,7 @@ func (r *Response) Location() string {
 	return r.Headers.Get("Location")
 }
 
+// FromCache 响应是否来自缓存（命中或经304确认）
+func (r *Response) FromCache() bool {
+	return r.CacheStatus == CacheHit || r.CacheStatus == CacheRevalidated
+}
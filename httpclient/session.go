@@ -0,0 +1,146 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+)
+
+// SetBaseURL 设置基础URL，请求传入相对路径时自动拼接
+// 例如 SetBaseURL("https://api.com/v1") 后 Get("/users") 请求 https://api.com/v1/users
+func (c *Client) SetBaseURL(baseURL string) *Client {
+	c.baseURL = baseURL
+	return c
+}
+
+// GetBaseURL 获取基础URL
+func (c *Client) GetBaseURL() string {
+	return c.baseURL
+}
+
+// resolveURL 拼接基础URL（完整URL原样返回）
+func (c *Client) resolveURL(urlStr string) string {
+	if c.baseURL == "" {
+		return urlStr
+	}
+	return joinURL(c.baseURL, urlStr)
+}
+
+// Clone 复制客户端（独立的连接池）
+// 复制请求头、Cookie、代理、超时等配置；限流器、HAR录制器与原客户端共享
+// 缓存不共享（MemoryCache 新建同样大小的缓存，SetSharedCache 设置的共享缓存除外）
+// 认证器实现了 Clone() Authenticator 时（BearerAuth、DigestAuth）复制一份独立状态，否则共享
+func (c *Client) Clone() *Client {
+	n := c.clone()
+	n.transport = c.transportClient().transport.Clone()
+	n.bindTransport()
+	n.httpClient.Transport = n.activeTransport()
+	return n
+}
+
+// WithSession 创建共享连接池的新会话（例如同一服务下的多个账号）
+// 请求头、Cookie、认证器状态独立（复制规则同 Clone）；代理和DNS设置跟随原客户端（包括之后的修改），
+// 调用 SetProxy/SetVerify/SetDNSOverride/SetResolver 后改用独立的连接池
+func (c *Client) WithSession() *Client {
+	n := c.clone()
+	n.transportOwner = c.transportClient()
+	n.httpClient.Transport = n.activeTransport()
+	return n
+}
+
+// clone 复制客户端配置（不含 Transport，代理和DNS设置取自当前生效的 Transport 所属客户端）
+func (c *Client) clone() *Client {
+	jar, _ := cookiejar.New(nil)
+	c.copyJarCookies(jar)
+	t := c.transportClient()
+
+	n := &Client{
+		headers:         c.GetHeaders(),
+		cookies:         c.GetCookies(),
+		timeout:         c.timeout,
+		maxRedirects:    c.maxRedirects,
+		verify:          t.verify,
+		proxyURL:        t.proxyURL,
+		proxyType:       t.proxyType,
+		jar:             jar,
+		baseURL:         c.baseURL,
+		harRecorder:     c.harRecorder,
+		logHook:         c.logHook,
+		rateLimiter:     c.rateLimiter,
+		cache:           c.sessionCache(),
+		cacheShared:     c.cacheShared,
+		cacheMode:       c.cacheMode,
+		auth:            cloneAuth(c.auth),
+		codecs:          make(map[string]Codec, len(c.codecs)),
+		bodyCodec:       c.bodyCodec,
+		compression:     c.compression,
+		compressMinSize: c.compressMinSize,
+		maxBodySize:     c.maxBodySize,
+		browserRotation: c.browserRotation,
+		dns:             t.dns.clone(),
+		roundTripper:    c.roundTripper,
+	}
+	for k, v := range c.codecs {
+		n.codecs[k] = v
+	}
+	n.httpClient = &http.Client{
+		Jar:           jar,
+		Timeout:       c.httpClient.Timeout,
+		CheckRedirect: c.httpClient.CheckRedirect,
+	}
+	return n
+}
+
+// copyJarCookies 复制 cookiejar 中基础URL可用的Cookie
+// cookiejar 无法枚举全部Cookie，未设置基础URL时不复制
+func (c *Client) copyJarCookies(jar *cookiejar.Jar) {
+	if c.jar == nil || c.baseURL == "" {
+		return
+	}
+	u, err := url.Parse(c.baseURL)
+	if err != nil || u.Host == "" {
+		return
+	}
+	cookies := c.jar.Cookies(u)
+	if len(cookies) == 0 {
+		return
+	}
+	// Cookies 只返回名称和值，按整个站点重新写入
+	root := &url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/"}
+	for _, cookie := range cookies {
+		cookie.Path = "/"
+	}
+	jar.SetCookies(root, cookies)
+}
+
+// cloneAuth 复制有状态的认证器，不支持复制的原样共享
+func cloneAuth(auth Authenticator) Authenticator {
+	if cloner, ok := auth.(interface{ Clone() Authenticator }); ok {
+		return cloner.Clone()
+	}
+	return auth
+}
+
+// transportClient 当前 Transport 所属的客户端（代理、证书验证、DNS设置以它为准）
+func (c *Client) transportClient() *Client {
+	if c.transportOwner != nil {
+		return c.transportOwner
+	}
+	return c
+}
+
+// ownTransport 共享连接池的客户端在修改传输层配置前，按原客户端当前的设置复制一份独立的 Transport
+func (c *Client) ownTransport() {
+	owner := c.transportOwner
+	if owner == nil {
+		return
+	}
+	c.verify = owner.verify
+	c.proxyURL = owner.proxyURL
+	c.proxyType = owner.proxyType
+	c.dns = owner.dns.clone()
+	c.transport = owner.transport.Clone()
+	c.transportOwner = nil
+	c.bindTransport()
+	c.httpClient.Transport = c.activeTransport()
+}
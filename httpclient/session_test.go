@@ -0,0 +1,74 @@
+package httpclient
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSessionFollowsParentProxy(t *testing.T) {
+	var direct int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&direct, 1)
+		w.Write([]byte("direct"))
+	}))
+	defer target.Close()
+
+	// HTTP代理替身：转发请求按绝对URL到达，直接应答
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("proxy " + r.URL.Host))
+	}))
+	defer proxy.Close()
+
+	// 未监听的地址，作为无法连接的SOCKS5代理
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	deadAddr := ln.Addr().String()
+	ln.Close()
+
+	parent := New().SetTimeout(time.Second)
+	session := parent.WithSession()
+
+	get := func(c *Client) (string, error) {
+		resp, err := c.Get(target.URL, nil)
+		if err != nil {
+			return "", err
+		}
+		return resp.Text(), nil
+	}
+
+	parent.SetHTTPProxy(strings.TrimPrefix(proxy.URL, "http://"))
+	if text, err := get(session); err != nil || !strings.HasPrefix(text, "proxy ") {
+		t.Fatalf("会话未跟随原客户端的HTTP代理: %q %v", text, err)
+	}
+
+	// HTTP 切换到 SOCKS5 会重建 Transport，会话仍需跟随，不能直连
+	parent.SetSocks5Proxy(deadAddr)
+	if _, err := get(session); err == nil {
+		t.Error("会话绕过了原客户端的SOCKS5代理")
+	}
+	if direct != 0 {
+		t.Fatalf("目标服务器收到 %d 次直连请求", direct)
+	}
+
+	// 会话改用独立连接池时继承原客户端当前的代理
+	session.SetVerify(false)
+	if session.transportOwner != nil || session.proxyType != "socks5" {
+		t.Fatalf("独立后的会话代理 = %s %s", session.proxyType, session.proxyURL)
+	}
+	if _, err := get(session); err == nil || direct != 0 {
+		t.Error("独立后的会话未使用SOCKS5代理")
+	}
+
+	// 原客户端清除代理后，独立的会话不受影响；新会话跟随
+	parent.ClearProxy()
+	if _, err := get(session); err == nil {
+		t.Error("独立的会话跟随了原客户端的修改")
+	}
+	if text, err := get(parent.WithSession()); err != nil || text != "direct" {
+		t.Errorf("新会话 = %q %v", text, err)
+	}
+}
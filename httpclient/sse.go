@@ -0,0 +1,384 @@
+package httpclient
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrSSEContentType 响应不是 text/event-stream
+var ErrSSEContentType = errors.New("响应不是 text/event-stream")
+
+// SSEEvent 服务器推送事件
+type SSEEvent struct {
+	ID    string        // 事件ID（id 字段）
+	Event string        // 事件类型（event 字段，默认 message）
+	Data  string        // 事件数据（多行 data 以换行拼接）
+	Retry time.Duration // 服务器建议的重连间隔（retry 字段）
+}
+
+// SSEOptions SSE 选项
+type SSEOptions struct {
+	Method      string            // 请求方法（默认GET）
+	Body        []byte            // 请求体（例如POST流式接口）
+	Headers     map[string]string // 额外请求头
+	Cookies     map[string]string // 额外Cookie
+	LastEventID string            // 初始 Last-Event-ID
+	RetryDelay  time.Duration     // 重连间隔（默认3秒，服务器 retry 字段会覆盖）
+	MaxRetries  int               // 最大连续重连次数（0 不限制，-1 不重连）
+	BufferSize  int               // 事件通道缓冲大小（默认16）
+}
+
+// SSEStream SSE事件流
+type SSEStream struct {
+	events chan *SSEEvent
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu          sync.Mutex
+	err         error
+	lastEventID string
+}
+
+// SSE 订阅服务器推送事件（text/event-stream），断线后使用 Last-Event-ID 自动重连
+func (c *Client) SSE(urlStr string, opts *SSEOptions) (*SSEStream, error) {
+	return c.SSEContext(context.Background(), urlStr, opts)
+}
+
+// SSEContext 订阅服务器推送事件，ctx 取消时停止
+// 首次连接失败直接返回错误，之后的断线在后台自动重连
+// 每次连接（包括重连）按限流设置消耗令牌，长连接不占用并发名额
+func (c *Client) SSEContext(ctx context.Context, urlStr string, opts *SSEOptions) (*SSEStream, error) {
+	// 复制一份再填充默认值，不修改调用方的选项
+	o := SSEOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	opts = &o
+	if opts.Method == "" {
+		opts.Method = http.MethodGet
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = 3 * time.Second
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 16
+	}
+	urlStr = c.resolveURL(urlStr)
+
+	ctx, cancel := context.WithCancel(ctx)
+	stream := &SSEStream{
+		events:      make(chan *SSEEvent, opts.BufferSize),
+		cancel:      cancel,
+		done:        make(chan struct{}),
+		lastEventID: opts.LastEventID,
+	}
+
+	// 事件流是长连接，不能使用客户端的整体超时；重连沿用首次连接时的传输层
+	streamClient := &http.Client{
+		Transport:     c.activeTransport(),
+		Jar:           c.httpClient.Jar,
+		CheckRedirect: c.limitRedirect(c.httpClient.CheckRedirect),
+	}
+
+	body, err := c.sseConnect(ctx, streamClient, urlStr, opts, stream.LastEventID())
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	go stream.run(ctx, c, streamClient, urlStr, opts, body)
+	return stream, nil
+}
+
+// Events 事件通道（流结束时关闭）
+func (s *SSEStream) Events() <-chan *SSEEvent {
+	return s.events
+}
+
+// Err 流结束的原因（正常关闭时为 nil）
+func (s *SSEStream) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// LastEventID 最后收到的事件ID
+func (s *SSEStream) LastEventID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastEventID
+}
+
+// Close 停止接收并关闭连接
+func (s *SSEStream) Close() {
+	s.cancel()
+	<-s.done
+}
+
+// run 读取事件，断线后重连
+func (s *SSEStream) run(ctx context.Context, c *Client, streamClient *http.Client, urlStr string, opts *SSEOptions, body io.ReadCloser) {
+	defer close(s.done)
+	defer close(s.events)
+
+	retryDelay := opts.RetryDelay
+	failures := 0
+
+	for {
+		received, retry, err := s.read(ctx, body)
+		body.Close()
+		if retry > 0 {
+			retryDelay = retry
+		}
+		if received {
+			failures = 0
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		// 重连
+		for {
+			if opts.MaxRetries < 0 || (opts.MaxRetries > 0 && failures >= opts.MaxRetries) {
+				if err == nil {
+					err = io.EOF
+				}
+				s.setErr(fmt.Errorf("SSE连接断开，已重连 %d 次: %w", failures, err))
+				return
+			}
+			failures++
+
+			timer := time.NewTimer(retryDelay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			body, err = c.sseConnect(ctx, streamClient, urlStr, opts, s.LastEventID())
+			if err == nil {
+				break
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			// 服务器明确拒绝（非2xx、Content-Type 错误）时不再重连，204 视为正常结束
+			var statusErr *sseStatusError
+			if errors.As(err, &statusErr) {
+				if statusErr.StatusCode != http.StatusNoContent {
+					s.setErr(err)
+				}
+				return
+			}
+			if errors.Is(err, ErrSSEContentType) {
+				s.setErr(err)
+				return
+			}
+		}
+	}
+}
+
+// read 解析事件流直到连接断开
+// 返回是否收到过事件、服务器指定的重连间隔和读取错误
+func (s *SSEStream) read(ctx context.Context, body io.Reader) (received bool, retry time.Duration, err error) {
+	reader := &sseLineReader{r: bufio.NewReader(body)}
+
+	var event SSEEvent
+	var data bytes.Buffer
+	hasData := false
+	first := true
+
+	for {
+		line, err := reader.readLine()
+		if err != nil {
+			return received, retry, err
+		}
+		// 流开头的 UTF-8 BOM 不属于字段名
+		if first {
+			line = strings.TrimPrefix(line, "\ufeff")
+			first = false
+		}
+
+		// 空行：分发事件
+		if line == "" {
+			if hasData {
+				event.Data = strings.TrimSuffix(data.String(), "\n")
+				if event.Event == "" {
+					event.Event = "message"
+				}
+				event.ID = s.LastEventID()
+				event.Retry = retry
+
+				ev := event
+				select {
+				case s.events <- &ev:
+					received = true
+				case <-ctx.Done():
+					return received, retry, ctx.Err()
+				}
+			}
+			event = SSEEvent{}
+			data.Reset()
+			hasData = false
+			continue
+		}
+
+		// 注释行
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value := line, ""
+		if idx := strings.Index(line, ":"); idx >= 0 {
+			field = line[:idx]
+			value = strings.TrimPrefix(line[idx+1:], " ")
+		}
+
+		switch field {
+		case "event":
+			event.Event = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+			hasData = true
+		case "id":
+			if !strings.Contains(value, "\x00") {
+				s.mu.Lock()
+				s.lastEventID = value
+				s.mu.Unlock()
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms >= 0 {
+				retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
+
+// sseLineReader 按 CRLF、LF 或单独的 CR 分行
+type sseLineReader struct {
+	r      *bufio.Reader
+	line   []byte
+	skipLF bool // 上一行以 CR 结尾，紧跟的 LF 属于同一个换行
+}
+
+// readLine 读取一行（不含换行符），连接断开前未结束的最后一行也会返回
+func (l *sseLineReader) readLine() (string, error) {
+	l.line = l.line[:0]
+	for {
+		b, err := l.r.ReadByte()
+		if err != nil {
+			if len(l.line) > 0 {
+				return string(l.line), nil
+			}
+			return "", err
+		}
+		if l.skipLF {
+			l.skipLF = false
+			if b == '\n' {
+				continue
+			}
+		}
+		switch b {
+		case '\n':
+			return string(l.line), nil
+		case '\r':
+			// 不等待下一个字节，避免以 CR 结尾的空行延迟分发事件
+			l.skipLF = true
+			return string(l.line), nil
+		}
+		l.line = append(l.line, b)
+	}
+}
+
+// setErr 记录结束原因
+func (s *SSEStream) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+// sseStatusError 服务器返回了不可重连的状态码
+type sseStatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *sseStatusError) Error() string {
+	return fmt.Sprintf("SSE请求失败: %s", e.Status)
+}
+
+// sseConnect 发起SSE请求，返回响应体
+func (c *Client) sseConnect(ctx context.Context, streamClient *http.Client, urlStr string, opts *SSEOptions, lastEventID string) (io.ReadCloser, error) {
+	var bodyReader io.Reader
+	if opts.Body != nil {
+		bodyReader = bytes.NewReader(opts.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, opts.Method, urlStr, bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+
+	c.setHeadersAndCookies(req, opts.Headers, opts.Cookies)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	if err := c.applyAuth(req); err != nil {
+		return nil, fmt.Errorf("认证失败: %w", err)
+	}
+	if err := c.rateLimiter.wait(ctx, req.URL.Hostname()); err != nil {
+		return nil, fmt.Errorf("等待限流失败: %w", err)
+	}
+
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+
+	c.saveCookies(resp.Cookies())
+
+	// 204 表示服务器要求停止重连
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || resp.StatusCode == http.StatusNoContent {
+		resp.Body.Close()
+		return nil, &sseStatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/event-stream" {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: %s", ErrSSEContentType, resp.Header.Get("Content-Type"))
+	}
+
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		gzReader, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("创建gzip解压器失败: %w", err)
+		}
+		return &gzipReadCloser{Reader: gzReader, body: resp.Body}, nil
+	}
+	return resp.Body, nil
+}
+
+// gzipReadCloser 关闭时同时关闭底层响应体
+type gzipReadCloser struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (g *gzipReadCloser) Close() error {
+	g.Reader.Close()
+	return g.body.Close()
+}
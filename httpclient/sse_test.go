@@ -0,0 +1,256 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestSSEReconnectWhileBusy 后台重连写入Cookie时，客户端同时在发送其他请求（需配合 -race 运行）
+func TestSSEReconnectWhileBusy(t *testing.T) {
+	const connections = 4
+	var conns int32
+	lastIDs := make(chan string, connections)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&conns, 1)
+		lastIDs <- r.Header.Get("Last-Event-ID")
+
+		http.SetCookie(w, &http.Cookie{Name: "sse", Value: strconv.Itoa(int(n))})
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "id: %d\ndata: msg %d\n\n", n, n)
+		w.(http.Flusher).Flush()
+
+		// 最后一次连接保持到客户端关闭，之前的直接断开触发重连
+		if n >= connections {
+			<-r.Context().Done()
+		}
+	})
+	var busyCount int32
+	mux.HandleFunc("/busy", func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&busyCount, 1)
+		http.SetCookie(w, &http.Cookie{Name: "busy", Value: strconv.Itoa(int(n))})
+		w.Write([]byte("ok"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client := New().SetBaseURL(srv.URL)
+	stream, err := client.SSE("/events", &SSEOptions{RetryDelay: 5 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("SSE: %v", err)
+	}
+	defer stream.Close()
+
+	// 重连期间客户端持续发送请求并读写Cookie
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if _, err := client.Get("/busy", nil); err != nil {
+					t.Errorf("Get: %v", err)
+					return
+				}
+				client.AddCookie("worker"+strconv.Itoa(i), "1")
+				client.GetCookies()
+			}
+		}(i)
+	}
+
+	timeout := time.After(5 * time.Second)
+	for i := 1; i <= connections; i++ {
+		select {
+		case ev, ok := <-stream.Events():
+			if !ok {
+				t.Fatalf("事件流提前结束: %v", stream.Err())
+			}
+			if want := fmt.Sprintf("msg %d", i); ev.Data != want || ev.ID != strconv.Itoa(i) {
+				t.Fatalf("事件 %d = %+v, want data %q", i, ev, want)
+			}
+		case <-timeout:
+			t.Fatal("等待事件超时")
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	// 重连时带上最后收到的事件ID
+	for i := 0; i < connections; i++ {
+		want := ""
+		if i > 0 {
+			want = strconv.Itoa(i)
+		}
+		if got := <-lastIDs; got != want {
+			t.Errorf("第 %d 次连接 Last-Event-ID = %q, want %q", i+1, got, want)
+		}
+	}
+
+	if got := client.GetCookies()["sse"]; got != strconv.Itoa(connections) {
+		t.Errorf("Cookie sse = %q, want %d", got, connections)
+	}
+	if stream.LastEventID() != strconv.Itoa(connections) {
+		t.Errorf("LastEventID = %q", stream.LastEventID())
+	}
+}
+
+func TestSSEOptionsNotModified(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: hello\n\n"))
+	}))
+	defer srv.Close()
+
+	opts := &SSEOptions{MaxRetries: -1}
+	stream, err := New().SSE(srv.URL, opts)
+	if err != nil {
+		t.Fatalf("SSE: %v", err)
+	}
+	defer stream.Close()
+
+	if ev := <-stream.Events(); ev == nil || ev.Data != "hello" {
+		t.Errorf("事件 = %+v", ev)
+	}
+	if opts.Method != "" || opts.RetryDelay != 0 || opts.BufferSize != 0 {
+		t.Errorf("调用方的选项被修改: %+v", opts)
+	}
+}
+
+func TestSSENonEventStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+
+	if _, err := New().SSE(srv.URL, nil); err == nil {
+		t.Fatal("非 text/event-stream 响应应返回错误")
+	}
+}
+
+func TestSSELineEndings(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream string
+		want   []string
+	}{
+		{"LF", "data: a\ndata: b\n\nevent: x\ndata: c\n\n", []string{"message:a\nb", "x:c"}},
+		{"CRLF", "data: a\r\ndata: b\r\n\r\nevent: x\r\ndata: c\r\n\r\n", []string{"message:a\nb", "x:c"}},
+		{"CR", "data: a\rdata: b\r\revent: x\rdata: c\r\r", []string{"message:a\nb", "x:c"}},
+		{"混合换行", "data: a\r\ndata: b\n\r\nevent: x\rdata: c\n\n", []string{"message:a\nb", "x:c"}},
+		{"BOM", "\ufeffdata: a\n\n", []string{"message:a"}},
+		{"只去掉开头的BOM", "data: a\n\n\ufeffdata: b\n\n", []string{"message:a"}},
+		{"未结束的事件丢弃", "data: a\n\ndata: b", []string{"message:a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				io.WriteString(w, tt.stream)
+			}))
+			defer srv.Close()
+
+			stream, err := New().SSE(srv.URL, &SSEOptions{MaxRetries: -1})
+			if err != nil {
+				t.Fatalf("SSE: %v", err)
+			}
+			var got []string
+			for ev := range stream.Events() {
+				got = append(got, ev.Event+":"+ev.Data)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("事件 = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSSECRDispatch 以 CR 结尾的空行立即分发事件，不等待后续数据
+func TestSSECRDispatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: a\r\r")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	stream, err := New().SSE(srv.URL, nil)
+	if err != nil {
+		t.Fatalf("SSE: %v", err)
+	}
+	defer stream.Close()
+	select {
+	case ev := <-stream.Events():
+		if ev.Data != "a" {
+			t.Errorf("事件 = %+v", ev)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("以 CR 结尾的事件未分发")
+	}
+}
+
+func TestSSERateLimited(t *testing.T) {
+	var conns int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 第3次连接后返回 204 停止重连
+		if n := atomic.AddInt32(&conns, 1); n > 3 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: x\n\n")
+	}))
+	defer srv.Close()
+
+	client := New().SetHostRateLimit(AllHosts, RateLimit{RPS: 20, Burst: 1, MaxConcurrent: 1})
+	stream, err := client.SSE(srv.URL, &SSEOptions{RetryDelay: time.Millisecond})
+	if err != nil {
+		t.Fatalf("SSE: %v", err)
+	}
+	d := elapsed(func() {
+		for range stream.Events() {
+		}
+	})
+	// 4 次连接各消耗一个令牌，三次重连各等待 50ms
+	if conns != 4 || d < 140*time.Millisecond {
+		t.Errorf("conns=%d 耗时=%v，重连未经过限流", conns, d)
+	}
+
+	// 保持中的事件流不占用并发名额
+	hold := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/other" {
+			w.Write([]byte("ok"))
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer hold.Close()
+	held, err := client.SSE(hold.URL, nil)
+	if err != nil {
+		t.Fatalf("SSE: %v", err)
+	}
+	defer held.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := client.Get(hold.URL+"/other", &Options{Context: ctx}); err != nil {
+		t.Errorf("事件流占用了并发名额: %v", err)
+	}
+}
@@ -0,0 +1,239 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Timings 请求各阶段耗时
+type Timings struct {
+	Blocked          time.Duration // 限流排队等待
+	DNSLookup        time.Duration // DNS解析
+	TCPConnect       time.Duration // TCP连接（使用代理时为连接代理服务器）
+	ProxyConnect     time.Duration // 代理握手（HTTP CONNECT 隧道或 SOCKS5 连接）
+	TLSHandshake     time.Duration // TLS握手
+	ServerProcessing time.Duration // 请求发送完成到收到首字节
+	ContentTransfer  time.Duration // 收到首字节到读取完响应体
+	TTFB             time.Duration // 请求开始到收到首字节（含限流等待）
+	Total            time.Duration // 总耗时
+	ConnReused       bool          // 是否复用了连接
+	RemoteAddr       string        // 对端地址（使用代理时为代理地址）
+}
+
+// RequestLog 请求日志信息（传递给日志钩子）
+type RequestLog struct {
+	Method     string  // 请求方法
+	URL        string  // 请求地址
+	StatusCode int     // 状态码（请求失败时为0）
+	BodySize   int     // 响应体大小
+	Timings    Timings // 耗时信息
+	Err        error   // 请求错误
+}
+
+// LogHookFn 请求完成时的日志钩子
+type LogHookFn func(log *RequestLog)
+
+// SetLogHook 设置日志钩子（每次请求完成或失败后调用）
+func (c *Client) SetLogHook(fn LogHookFn) *Client {
+	c.logHook = fn
+	return c
+}
+
+// timingCtxKey context中保存计时器的key
+type timingCtxKey struct{}
+
+// timingCollector 通过 httptrace 收集请求耗时
+type timingCollector struct {
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	proxyStart   time.Time
+	proxyDone    time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	end          time.Time
+	blocked      time.Duration
+	reused       bool
+	remoteAddr   string
+}
+
+// newTimingCollector 创建计时器
+func newTimingCollector() *timingCollector {
+	return &timingCollector{start: time.Now()}
+}
+
+// withContext 将计时器挂载到context
+func (t *timingCollector) withContext(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, timingCtxKey{}, t)
+	return httptrace.WithClientTrace(ctx, t.trace())
+}
+
+// trace 构建 httptrace 回调
+func (t *timingCollector) trace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(hostPort string) {
+			// 每次重定向都会重新获取连接，只保留最后一跳的连接耗时
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.dnsStart, t.dnsDone = time.Time{}, time.Time{}
+			t.connectStart, t.connectDone = time.Time{}, time.Time{}
+			t.proxyStart, t.proxyDone = time.Time{}, time.Time{}
+			t.tlsStart, t.tlsDone = time.Time{}, time.Time{}
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mark(&t.dnsStart)
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mark(&t.dnsDone)
+		},
+		ConnectStart: func(network, addr string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			// 多地址拨号时只记录第一次开始
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(network, addr string, err error) {
+			if err == nil {
+				t.mark(&t.connectDone)
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.mark(&t.tlsStart)
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mark(&t.tlsDone)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.reused = info.Reused
+			if info.Conn != nil {
+				t.remoteAddr = info.Conn.RemoteAddr().String()
+			}
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			t.mark(&t.wroteRequest)
+		},
+		GotFirstResponseByte: func() {
+			t.mark(&t.firstByte)
+		},
+	}
+}
+
+// mark 记录时间点
+func (t *timingCollector) mark(field *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	*field = time.Now()
+}
+
+// setBlocked 记录限流等待时间
+func (t *timingCollector) setBlocked(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.blocked = d
+}
+
+// finish 结束计时
+func (t *timingCollector) finish() {
+	t.mark(&t.end)
+}
+
+// traceProxyDial 记录自定义代理拨号耗时（SOCKS5），返回结束回调
+func traceProxyDial(ctx context.Context) func() {
+	t, ok := ctx.Value(timingCtxKey{}).(*timingCollector)
+	if !ok {
+		return func() {}
+	}
+	t.mark(&t.proxyStart)
+	return func() {
+		t.mark(&t.proxyDone)
+	}
+}
+
+// timings 计算各阶段耗时
+// httpProxied 表示最终请求是否经过HTTP代理的CONNECT隧道
+func (t *timingCollector) timings(httpProxied bool) Timings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	result := Timings{
+		Blocked:      t.blocked,
+		DNSLookup:    sub(t.dnsDone, t.dnsStart),
+		TCPConnect:   sub(t.connectDone, t.connectStart),
+		TLSHandshake: sub(t.tlsDone, t.tlsStart),
+		TTFB:         sub(t.firstByte, t.start),
+		Total:        sub(t.end, t.start),
+		ConnReused:   t.reused,
+		RemoteAddr:   t.remoteAddr,
+	}
+
+	if !t.proxyStart.IsZero() {
+		result.ProxyConnect = sub(t.proxyDone, t.proxyStart)
+	} else if httpProxied && !t.tlsStart.IsZero() {
+		// HTTP代理：TCP连接建立后到TLS握手开始之间是 CONNECT 隧道握手
+		result.ProxyConnect = sub(t.tlsStart, t.connectDone)
+	}
+
+	if !t.wroteRequest.IsZero() {
+		result.ServerProcessing = sub(t.firstByte, t.wroteRequest)
+	}
+	if !t.firstByte.IsZero() {
+		result.ContentTransfer = sub(t.end, t.firstByte)
+	}
+
+	return result
+}
+
+// sub 计算时间差（任一时间点缺失时返回0）
+func sub(end, start time.Time) time.Duration {
+	if end.IsZero() || start.IsZero() || end.Before(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// isHTTPProxied 判断请求是否通过HTTP代理建立隧道
+func (c *Client) isHTTPProxied(u *url.URL) bool {
+	t := c.transportClient()
+	return t.proxyURL != "" && t.proxyType != "socks5" && u != nil && u.Scheme == "https"
+}
+
+// finishTiming 结束计时并调用日志钩子
+func (c *Client) finishTiming(t *timingCollector, req *http.Request, resp *http.Response, bodySize int, err error) Timings {
+	t.finish()
+
+	finalURL := req.URL
+	if resp != nil && resp.Request != nil {
+		finalURL = resp.Request.URL
+	}
+	timings := t.timings(c.isHTTPProxied(finalURL))
+
+	if c.logHook != nil {
+		log := &RequestLog{
+			Method:   req.Method,
+			URL:      req.URL.String(),
+			BodySize: bodySize,
+			Timings:  timings,
+			Err:      err,
+		}
+		if resp != nil {
+			log.StatusCode = resp.StatusCode
+		}
+		c.logHook(log)
+	}
+
+	return timings
+}
@@ -0,0 +1,135 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimingsCalculation(t *testing.T) {
+	base := time.Now()
+	at := func(ms int) time.Time { return base.Add(time.Duration(ms) * time.Millisecond) }
+
+	tests := []struct {
+		name        string
+		collector   *timingCollector
+		httpProxied bool
+		want        Timings
+	}{
+		{
+			name: "直连HTTPS",
+			collector: &timingCollector{
+				start: at(0), blocked: 5 * time.Millisecond,
+				dnsStart: at(5), dnsDone: at(10),
+				connectStart: at(10), connectDone: at(20),
+				tlsStart: at(20), tlsDone: at(35),
+				wroteRequest: at(36), firstByte: at(50), end: at(60),
+			},
+			want: Timings{
+				Blocked: 5 * time.Millisecond, DNSLookup: 5 * time.Millisecond, TCPConnect: 10 * time.Millisecond,
+				TLSHandshake: 15 * time.Millisecond, ServerProcessing: 14 * time.Millisecond,
+				ContentTransfer: 10 * time.Millisecond, TTFB: 50 * time.Millisecond, Total: 60 * time.Millisecond,
+			},
+		},
+		{
+			name: "HTTP代理CONNECT隧道",
+			collector: &timingCollector{
+				start:        at(0),
+				connectStart: at(0), connectDone: at(10),
+				tlsStart: at(25), tlsDone: at(40),
+				wroteRequest: at(40), firstByte: at(45), end: at(45),
+			},
+			httpProxied: true,
+			want: Timings{
+				TCPConnect: 10 * time.Millisecond, ProxyConnect: 15 * time.Millisecond, TLSHandshake: 15 * time.Millisecond,
+				ServerProcessing: 5 * time.Millisecond, TTFB: 45 * time.Millisecond, Total: 45 * time.Millisecond,
+			},
+		},
+		{
+			name: "SOCKS5拨号",
+			collector: &timingCollector{
+				start:      at(0),
+				proxyStart: at(0), proxyDone: at(30),
+				wroteRequest: at(30), firstByte: at(40), end: at(50),
+			},
+			want: Timings{
+				ProxyConnect: 30 * time.Millisecond, ServerProcessing: 10 * time.Millisecond,
+				ContentTransfer: 10 * time.Millisecond, TTFB: 40 * time.Millisecond, Total: 50 * time.Millisecond,
+			},
+		},
+		{
+			name: "复用连接且请求失败",
+			collector: &timingCollector{
+				start: at(0), end: at(8), reused: true, remoteAddr: "127.0.0.1:80",
+			},
+			want: Timings{Total: 8 * time.Millisecond, ConnReused: true, RemoteAddr: "127.0.0.1:80"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.collector.timings(tt.httpProxied); got != tt.want {
+				t.Errorf("timings =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestTimings(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	var logs []*RequestLog
+	client := New().SetVerify(false).SetLogHook(func(log *RequestLog) {
+		logs = append(logs, log)
+	})
+
+	first, err := client.Get(srv.URL+"/a", nil)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	ft := first.Timings
+	if ft.ConnReused || ft.TCPConnect <= 0 || ft.TLSHandshake <= 0 {
+		t.Errorf("首次请求 = %+v", ft)
+	}
+	if ft.ServerProcessing < 20*time.Millisecond || ft.TTFB < ft.ServerProcessing || ft.Total < ft.TTFB {
+		t.Errorf("首次请求耗时不合理 = %+v", ft)
+	}
+	if ft.RemoteAddr != srv.Listener.Addr().String() {
+		t.Errorf("RemoteAddr = %q, want %q", ft.RemoteAddr, srv.Listener.Addr())
+	}
+
+	// 第二次复用连接，没有连接阶段
+	second, err := client.Get(srv.URL+"/b", nil)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if st := second.Timings; !st.ConnReused || st.TCPConnect != 0 || st.TLSHandshake != 0 || st.DNSLookup != 0 {
+		t.Errorf("复用连接 = %+v", st)
+	}
+
+	// 请求失败也会调用日志钩子
+	srv.Close()
+	client.SetTimeout(time.Second)
+	_, err = client.Get(srv.URL+"/c", nil)
+	if err == nil {
+		t.Fatal("服务器关闭后请求应失败")
+	}
+
+	if len(logs) != 3 {
+		t.Fatalf("日志钩子调用 %d 次, want 3", len(logs))
+	}
+	if logs[0].StatusCode != 200 || logs[0].BodySize != 5 || logs[0].Method != "GET" || !strings.HasSuffix(logs[0].URL, "/a") {
+		t.Errorf("logs[0] = %+v", logs[0])
+	}
+	if logs[0].Timings != first.Timings {
+		t.Errorf("日志与响应的耗时不一致")
+	}
+	if logs[2].StatusCode != 0 || logs[2].Err == nil {
+		t.Errorf("logs[2] = %+v", logs[2])
+	}
+}
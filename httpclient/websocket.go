@@ -0,0 +1,680 @@
+package httpclient
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// WebSocket 消息类型（RFC 6455 opcode）
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+	CloseMessage  = 8
+	PingMessage   = 9
+	PongMessage   = 10
+)
+
+// WebSocket 关闭码
+const (
+	CloseNormalClosure    = 1000
+	CloseGoingAway        = 1001
+	CloseProtocolError    = 1002
+	CloseNoStatusReceived = 1005
+	CloseMessageTooBig    = 1009
+)
+
+// websocket 握手使用的固定GUID
+const wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+var (
+	ErrWSHandshake = errors.New("WebSocket握手失败")
+	ErrWSClosed    = errors.New("WebSocket连接已关闭")
+)
+
+// WSCloseError 收到对端关闭帧
+type WSCloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *WSCloseError) Error() string {
+	return fmt.Sprintf("WebSocket已关闭: %d %s", e.Code, e.Reason)
+}
+
+// WSOptions WebSocket连接选项
+type WSOptions struct {
+	Headers          map[string]string // 额外的握手请求头（如 Origin）
+	Cookies          map[string]string // 额外的Cookie
+	Subprotocols     []string          // 子协议
+	HandshakeTimeout time.Duration     // 握手超时（默认使用客户端超时）
+	PingInterval     time.Duration     // 心跳间隔（0 不发送心跳），超过2个间隔未收到数据视为断开
+	MaxMessageSize   int64             // 最大消息大小（默认32MB）
+	WriteTimeout     time.Duration     // 单次写入超时（默认10秒），超时后关闭连接
+}
+
+// WSConn WebSocket连接
+// 读操作只能在一个goroutine中进行，写操作是并发安全的
+type WSConn struct {
+	conn           net.Conn
+	br             *bufio.Reader
+	writeMu        sync.Mutex
+	closeOnce      sync.Once
+	closed         chan struct{}
+	pingInterval   time.Duration
+	maxMessageSize int64
+	writeTimeout   time.Duration
+	subprotocol    string
+	response       *http.Response
+	pongHandler    func(data string)
+}
+
+// WebSocket 使用客户端的Cookie、请求头、代理和TLS配置建立WebSocket连接
+func (c *Client) WebSocket(urlStr string) (*WSConn, error) {
+	return c.WebSocketContext(context.Background(), urlStr, nil)
+}
+
+// WebSocketContext 建立WebSocket连接，ctx 取消时关闭连接
+func (c *Client) WebSocketContext(ctx context.Context, urlStr string, opts *WSOptions) (*WSConn, error) {
+	// 复制一份再填充默认值，不修改调用方的选项
+	o := WSOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	opts = &o
+	if opts.MaxMessageSize <= 0 {
+		opts.MaxMessageSize = 32 << 20
+	}
+	if opts.WriteTimeout <= 0 {
+		opts.WriteTimeout = 10 * time.Second
+	}
+	timeout := opts.HandshakeTimeout
+	if timeout <= 0 {
+		timeout = c.timeout
+	}
+
+	u, err := url.Parse(c.resolveURL(urlStr))
+	if err != nil {
+		return nil, fmt.Errorf("解析URL失败: %w", err)
+	}
+	// 兼容 http(s):// 写法
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	case "ws", "wss":
+	default:
+		return nil, fmt.Errorf("不支持的WebSocket协议: %s", u.Scheme)
+	}
+
+	handshakeCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		handshakeCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	conn, err := c.dialWebSocket(handshakeCtx, u)
+	if err != nil {
+		return nil, err
+	}
+
+	ws, err := c.wsHandshake(handshakeCtx, conn, u, opts)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	// ctx 取消时关闭连接
+	go func() {
+		select {
+		case <-ctx.Done():
+			ws.CloseWithCode(CloseGoingAway, "")
+		case <-ws.closed:
+		}
+	}()
+
+	if ws.pingInterval > 0 {
+		go ws.keepalive()
+	}
+
+	return ws, nil
+}
+
+// dialWebSocket 建立底层连接（直连、HTTP CONNECT 或 SOCKS5 代理，wss 时进行TLS握手）
+func (c *Client) dialWebSocket(ctx context.Context, u *url.URL) (net.Conn, error) {
+	host := u.Hostname()
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "wss" {
+			port = "443"
+		}
+	}
+	addr := net.JoinHostPort(host, port)
+
+	// 共享连接池的会话使用原客户端当前的代理和DNS设置
+	t := c.transportClient()
+	var conn net.Conn
+	var err error
+
+	switch {
+	case t.proxyURL != "" && t.proxyType == "socks5":
+		conn, err = t.dialResolvedVia(ctx, addr, t.dialSocks5)
+	case t.proxyURL != "":
+		conn, err = t.dialResolvedVia(ctx, addr, t.dialHTTPConnect)
+	default:
+		conn, err = t.dialDirect(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("连接失败: %w", err)
+	}
+
+	if u.Scheme == "wss" {
+		cfg := &tls.Config{}
+		if t.transport.TLSClientConfig != nil {
+			cfg = t.transport.TLSClientConfig.Clone()
+		}
+		if cfg.ServerName == "" {
+			cfg.ServerName = host
+		}
+		cfg.InsecureSkipVerify = !t.verify
+		// WebSocket 只能在 HTTP/1.1 上升级
+		cfg.NextProtos = []string{"http/1.1"}
+
+		tlsConn := tls.Client(conn, cfg)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("TLS握手失败: %w", err)
+		}
+		conn = tlsConn
+	}
+	return conn, nil
+}
+
+// dialDirect 直连（应用DNS覆盖和自定义解析）
+func (c *Client) dialDirect(ctx context.Context, network, addr string) (net.Conn, error) {
+	return c.dialResolved(ctx, network, addr)
+}
+
+// dialSocks5 通过SOCKS5代理连接
+func (c *Client) dialSocks5(ctx context.Context, addr string) (net.Conn, error) {
+	proxyURL, err := url.Parse(c.proxyURL)
+	if err != nil {
+		return nil, err
+	}
+	var auth *proxy.Auth
+	if proxyURL.User != nil {
+		auth = &proxy.Auth{User: proxyURL.User.Username()}
+		auth.Password, _ = proxyURL.User.Password()
+	}
+	dialer, err := proxy.SOCKS5("tcp", proxyURL.Host, auth, proxy.Direct)
+	if err != nil {
+		return nil, err
+	}
+	if cd, ok := dialer.(proxy.ContextDialer); ok {
+		return cd.DialContext(ctx, "tcp", addr)
+	}
+	return dialer.Dial("tcp", addr)
+}
+
+// dialHTTPConnect 通过HTTP代理的 CONNECT 隧道连接（https:// 代理先与代理建立TLS连接）
+func (c *Client) dialHTTPConnect(ctx context.Context, addr string) (net.Conn, error) {
+	proxyURL, err := url.Parse(c.proxyURL)
+	if err != nil {
+		return nil, err
+	}
+	proxyAddr := proxyURL.Host
+	if proxyURL.Port() == "" {
+		port := "80"
+		if proxyURL.Scheme == "https" {
+			port = "443"
+		}
+		proxyAddr = net.JoinHostPort(proxyURL.Hostname(), port)
+	}
+
+	conn, err := c.dialDirect(ctx, "tcp", proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL.Scheme == "https" {
+		cfg := &tls.Config{}
+		if c.transport != nil && c.transport.TLSClientConfig != nil {
+			cfg = c.transport.TLSClientConfig.Clone()
+		}
+		cfg.ServerName = proxyURL.Hostname()
+		cfg.InsecureSkipVerify = !c.verify
+		cfg.NextProtos = []string{"http/1.1"}
+		tlsConn := tls.Client(conn, cfg)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("代理TLS握手失败: %w", err)
+		}
+		conn = tlsConn
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		auth := base64.StdEncoding.EncodeToString([]byte(proxyURL.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+auth)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	// 隧道建立前代理不会发送多余数据，可以直接丢弃 bufio 缓冲
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("代理CONNECT失败: %s", resp.Status)
+	}
+	return conn, nil
+}
+
+// wsHandshake 发送升级请求并校验响应
+func (c *Client) wsHandshake(ctx context.Context, conn net.Conn, u *url.URL, opts *WSOptions) (*WSConn, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	keyBytes := make([]byte, 16)
+	rand.Read(keyBytes)
+	key := base64.StdEncoding.EncodeToString(keyBytes)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+
+	// 客户端默认请求头和Cookie
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+	for k, v := range opts.Headers {
+		req.Header.Set(k, v)
+	}
+	for _, cookie := range c.jar.Cookies(httpURL(u)) {
+		req.AddCookie(cookie)
+	}
+	c.addClientCookies(req)
+	for k, v := range opts.Cookies {
+		req.AddCookie(&http.Cookie{Name: k, Value: v})
+	}
+
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if len(opts.Subprotocols) > 0 {
+		req.Header.Set("Sec-WebSocket-Protocol", strings.Join(opts.Subprotocols, ", "))
+	}
+
+	if err := c.applyAuth(req); err != nil {
+		return nil, fmt.Errorf("认证失败: %w", err)
+	}
+
+	if err := req.Write(conn); err != nil {
+		return nil, fmt.Errorf("发送握手请求失败: %w", err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, fmt.Errorf("读取握手响应失败: %w", err)
+	}
+
+	// 保存服务器下发的Cookie
+	c.saveCookies(resp.Cookies())
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: %s %s", ErrWSHandshake, resp.Status, string(body))
+	}
+	if !strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") {
+		return nil, fmt.Errorf("%w: 缺少 Upgrade: websocket", ErrWSHandshake)
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != wsAcceptKey(key) {
+		return nil, fmt.Errorf("%w: Sec-WebSocket-Accept 校验失败", ErrWSHandshake)
+	}
+
+	return &WSConn{
+		conn:           conn,
+		br:             br,
+		closed:         make(chan struct{}),
+		pingInterval:   opts.PingInterval,
+		maxMessageSize: opts.MaxMessageSize,
+		writeTimeout:   opts.WriteTimeout,
+		subprotocol:    resp.Header.Get("Sec-WebSocket-Protocol"),
+		response:       resp,
+	}, nil
+}
+
+// httpURL 将 ws/wss 地址转换为 http/https（用于查询 cookiejar）
+func httpURL(u *url.URL) *url.URL {
+	result := *u
+	if u.Scheme == "wss" {
+		result.Scheme = "https"
+	} else {
+		result.Scheme = "http"
+	}
+	return &result
+}
+
+// wsAcceptKey 计算 Sec-WebSocket-Accept
+func wsAcceptKey(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + wsGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// Subprotocol 获取服务器选择的子协议
+func (w *WSConn) Subprotocol() string {
+	return w.subprotocol
+}
+
+// Response 获取握手响应
+func (w *WSConn) Response() *http.Response {
+	return w.response
+}
+
+// LocalAddr 本地地址
+func (w *WSConn) LocalAddr() net.Addr {
+	return w.conn.LocalAddr()
+}
+
+// RemoteAddr 对端地址
+func (w *WSConn) RemoteAddr() net.Addr {
+	return w.conn.RemoteAddr()
+}
+
+// SetPongHandler 设置收到 Pong 时的回调
+func (w *WSConn) SetPongHandler(fn func(data string)) {
+	w.pongHandler = fn
+}
+
+// ReadMessage 读取一条完整消息（自动处理分片、Ping、Pong 和关闭帧）
+func (w *WSConn) ReadMessage() (messageType int, data []byte, err error) {
+	var message []byte
+	messageType = 0
+
+	for {
+		if w.pingInterval > 0 {
+			w.conn.SetReadDeadline(time.Now().Add(2 * w.pingInterval))
+		}
+
+		fin, opcode, payload, err := w.readFrame()
+		if err != nil {
+			select {
+			case <-w.closed:
+				// 本地主动关闭（Close 或 ctx 取消）
+				return 0, nil, ErrWSClosed
+			default:
+			}
+			w.closeConn()
+			return 0, nil, err
+		}
+
+		switch opcode {
+		case PingMessage:
+			w.writeFrame(PongMessage, payload)
+			continue
+		case PongMessage:
+			if w.pongHandler != nil {
+				w.pongHandler(string(payload))
+			}
+			continue
+		case CloseMessage:
+			closeErr := &WSCloseError{Code: CloseNoStatusReceived}
+			if len(payload) >= 2 {
+				closeErr.Code = int(binary.BigEndian.Uint16(payload[:2]))
+				closeErr.Reason = string(payload[2:])
+			}
+			// 回复关闭帧后关闭连接
+			w.writeFrame(CloseMessage, payload[:min(len(payload), 2)])
+			w.closeConn()
+			return 0, nil, closeErr
+		case TextMessage, BinaryMessage:
+			if messageType != 0 {
+				w.CloseWithCode(CloseProtocolError, "")
+				return 0, nil, errors.New("WebSocket协议错误: 分片消息未结束")
+			}
+			messageType = opcode
+		case 0:
+			// 续帧
+			if messageType == 0 {
+				w.CloseWithCode(CloseProtocolError, "")
+				return 0, nil, errors.New("WebSocket协议错误: 意外的续帧")
+			}
+		default:
+			w.CloseWithCode(CloseProtocolError, "")
+			return 0, nil, fmt.Errorf("WebSocket协议错误: 未知的opcode %d", opcode)
+		}
+
+		if int64(len(message)+len(payload)) > w.maxMessageSize {
+			w.CloseWithCode(CloseMessageTooBig, "")
+			return 0, nil, fmt.Errorf("WebSocket消息超过最大长度 %d", w.maxMessageSize)
+		}
+		message = append(message, payload...)
+
+		if fin {
+			return messageType, message, nil
+		}
+	}
+}
+
+// ReadText 读取文本消息
+func (w *WSConn) ReadText() (string, error) {
+	_, data, err := w.ReadMessage()
+	return string(data), err
+}
+
+// ReadJSON 读取消息并解析JSON
+func (w *WSConn) ReadJSON(v interface{}) error {
+	_, data, err := w.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// WriteMessage 发送消息
+func (w *WSConn) WriteMessage(messageType int, data []byte) error {
+	return w.writeFrame(messageType, data)
+}
+
+// WriteText 发送文本消息
+func (w *WSConn) WriteText(text string) error {
+	return w.writeFrame(TextMessage, []byte(text))
+}
+
+// WriteJSON 发送JSON消息
+func (w *WSConn) WriteJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("JSON序列化失败: %w", err)
+	}
+	return w.writeFrame(TextMessage, data)
+}
+
+// Ping 发送 Ping
+func (w *WSConn) Ping(data []byte) error {
+	return w.writeFrame(PingMessage, data)
+}
+
+// Close 正常关闭连接
+func (w *WSConn) Close() error {
+	return w.CloseWithCode(CloseNormalClosure, "")
+}
+
+// CloseWithCode 发送关闭帧并关闭连接
+func (w *WSConn) CloseWithCode(code int, reason string) error {
+	payload := make([]byte, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	copy(payload[2:], reason)
+
+	err := w.writeFrame(CloseMessage, payload)
+	w.closeConn()
+	if errors.Is(err, ErrWSClosed) {
+		return nil
+	}
+	return err
+}
+
+// Done 连接关闭时关闭的通道
+func (w *WSConn) Done() <-chan struct{} {
+	return w.closed
+}
+
+// closeConn 关闭底层连接
+func (w *WSConn) closeConn() {
+	w.closeOnce.Do(func() {
+		close(w.closed)
+		w.conn.Close()
+	})
+}
+
+// keepalive 定时发送 Ping
+func (w *WSConn) keepalive() {
+	ticker := time.NewTicker(w.pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := w.Ping(nil); err != nil {
+				w.closeConn()
+				return
+			}
+		case <-w.closed:
+			return
+		}
+	}
+}
+
+// readFrame 读取一帧
+func (w *WSConn) readFrame() (fin bool, opcode int, payload []byte, err error) {
+	var header [2]byte
+	if _, err = io.ReadFull(w.br, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin = header[0]&0x80 != 0
+	opcode = int(header[0] & 0x0f)
+	masked := header[1]&0x80 != 0
+	length := int64(header[1] & 0x7f)
+
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(w.br, ext[:]); err != nil {
+			return
+		}
+		length = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(w.br, ext[:]); err != nil {
+			return
+		}
+		length = int64(binary.BigEndian.Uint64(ext[:]))
+	}
+	if length < 0 || length > w.maxMessageSize {
+		return false, 0, nil, fmt.Errorf("WebSocket帧超过最大长度 %d", w.maxMessageSize)
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(w.br, mask[:]); err != nil {
+			return
+		}
+	}
+
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(w.br, payload); err != nil {
+		return
+	}
+	if masked {
+		maskBytes(mask, payload)
+	}
+	return fin, opcode, payload, nil
+}
+
+// writeFrame 写入一帧（客户端发送的帧必须掩码）
+func (w *WSConn) writeFrame(opcode int, payload []byte) error {
+	select {
+	case <-w.closed:
+		return ErrWSClosed
+	default:
+	}
+
+	buf := make([]byte, 0, 14+len(payload))
+	buf = append(buf, 0x80|byte(opcode))
+
+	length := len(payload)
+	switch {
+	case length <= 125:
+		buf = append(buf, 0x80|byte(length))
+	case length <= 0xffff:
+		buf = append(buf, 0x80|126)
+		buf = binary.BigEndian.AppendUint16(buf, uint16(length))
+	default:
+		buf = append(buf, 0x80|127)
+		buf = binary.BigEndian.AppendUint64(buf, uint64(length))
+	}
+
+	var mask [4]byte
+	rand.Read(mask[:])
+	buf = append(buf, mask[:]...)
+	start := len(buf)
+	buf = append(buf, payload...)
+	maskBytes(mask, buf[start:])
+
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	// 对端停止读取时写入会一直阻塞，超时后帧可能只写了一部分，只能关闭连接
+	w.conn.SetWriteDeadline(time.Now().Add(w.writeTimeout))
+	if _, err := w.conn.Write(buf); err != nil {
+		w.closeConn()
+		return err
+	}
+	return nil
+}
+
+// maskBytes 掩码/解码
+func maskBytes(mask [4]byte, data []byte) {
+	for i := range data {
+		data[i] ^= mask[i%4]
+	}
+}
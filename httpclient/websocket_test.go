@@ -0,0 +1,505 @@
+package httpclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// wsTestFrame 服务端收到的帧
+type wsTestFrame struct {
+	fin     bool
+	opcode  int
+	masked  bool
+	payload []byte
+}
+
+// newWSTestServer 启动完成握手后交给 handler 处理原始连接的测试服务器
+func newWSTestServer(t *testing.T, handler func(conn net.Conn, br *bufio.Reader)) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") || r.Header.Get("Sec-WebSocket-Version") != "13" {
+			http.Error(w, "not websocket", http.StatusBadRequest)
+			return
+		}
+		conn, brw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("hijack: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		resp := "HTTP/1.1 101 Switching Protocols\r\n" +
+			"Upgrade: websocket\r\n" +
+			"Connection: Upgrade\r\n" +
+			"Sec-WebSocket-Accept: " + wsAcceptKey(r.Header.Get("Sec-WebSocket-Key")) + "\r\n"
+		if protocols := r.Header.Get("Sec-WebSocket-Protocol"); protocols != "" {
+			resp += "Sec-WebSocket-Protocol: " + strings.TrimSpace(strings.Split(protocols, ",")[0]) + "\r\n"
+		}
+		if _, err := conn.Write([]byte(resp + "\r\n")); err != nil {
+			t.Errorf("write handshake: %v", err)
+			return
+		}
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		handler(conn, brw.Reader)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// readTestFrame 服务端读取一帧
+func readTestFrame(br *bufio.Reader) (wsTestFrame, error) {
+	var f wsTestFrame
+	var header [2]byte
+	if _, err := io.ReadFull(br, header[:]); err != nil {
+		return f, err
+	}
+	f.fin = header[0]&0x80 != 0
+	f.opcode = int(header[0] & 0x0f)
+	f.masked = header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(br, ext[:]); err != nil {
+			return f, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(br, ext[:]); err != nil {
+			return f, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	var mask [4]byte
+	if f.masked {
+		if _, err := io.ReadFull(br, mask[:]); err != nil {
+			return f, err
+		}
+	}
+	f.payload = make([]byte, length)
+	if _, err := io.ReadFull(br, f.payload); err != nil {
+		return f, err
+	}
+	if f.masked {
+		maskBytes(mask, f.payload)
+	}
+	return f, nil
+}
+
+// writeTestFrame 服务端写入一帧（服务端帧不掩码）
+func writeTestFrame(conn net.Conn, fin bool, opcode int, payload []byte) error {
+	b0 := byte(opcode)
+	if fin {
+		b0 |= 0x80
+	}
+	buf := []byte{b0}
+	switch {
+	case len(payload) <= 125:
+		buf = append(buf, byte(len(payload)))
+	case len(payload) <= 0xffff:
+		buf = append(buf, 126)
+		buf = binary.BigEndian.AppendUint16(buf, uint16(len(payload)))
+	default:
+		buf = append(buf, 127)
+		buf = binary.BigEndian.AppendUint64(buf, uint64(len(payload)))
+	}
+	_, err := conn.Write(append(buf, payload...))
+	return err
+}
+
+func TestWebSocketHandshake(t *testing.T) {
+	got := make(chan *http.Request, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got <- r
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		defer conn.Close()
+		conn.Write([]byte("HTTP/1.1 101 Switching Protocols\r\n" +
+			"Upgrade: websocket\r\n" +
+			"Connection: Upgrade\r\n" +
+			"Sec-WebSocket-Protocol: chat\r\n" +
+			"Sec-WebSocket-Accept: " + wsAcceptKey(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n"))
+	}))
+	defer srv.Close()
+
+	client := New().SetHeaders(map[string]string{"X-Client": "1"}).AddCookie("sid", "abc")
+	opts := &WSOptions{
+		Headers:      map[string]string{"Origin": "http://example.com"},
+		Subprotocols: []string{"chat", "superchat"},
+	}
+	ws, err := client.WebSocketContext(context.Background(), srv.URL, opts)
+	if err != nil {
+		t.Fatalf("WebSocket: %v", err)
+	}
+	defer ws.Close()
+
+	if opts.MaxMessageSize != 0 {
+		t.Errorf("调用方的选项被修改: MaxMessageSize=%d", opts.MaxMessageSize)
+	}
+	if ws.Subprotocol() != "chat" {
+		t.Errorf("Subprotocol = %q, want chat", ws.Subprotocol())
+	}
+	if ws.Response().StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("StatusCode = %d", ws.Response().StatusCode)
+	}
+
+	r := <-got
+	checks := map[string]string{
+		"Upgrade":                "websocket",
+		"Connection":             "Upgrade",
+		"Sec-WebSocket-Version":  "13",
+		"Sec-WebSocket-Protocol": "chat, superchat",
+		"Origin":                 "http://example.com",
+		"X-Client":               "1",
+	}
+	for name, want := range checks {
+		if v := r.Header.Get(name); v != want {
+			t.Errorf("%s = %q, want %q", name, v, want)
+		}
+	}
+	if cookie, err := r.Cookie("sid"); err != nil || cookie.Value != "abc" {
+		t.Errorf("Cookie sid = %v, %v", cookie, err)
+	}
+}
+
+func TestWebSocketHandshakeRejected(t *testing.T) {
+	// 普通HTTP服务器不会切换协议
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	_, err := New().WebSocket(srv.URL)
+	if !errors.Is(err, ErrWSHandshake) {
+		t.Fatalf("err = %v, want ErrWSHandshake", err)
+	}
+}
+
+func TestWebSocketHandshakeBadAccept(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		defer conn.Close()
+		conn.Write([]byte("HTTP/1.1 101 Switching Protocols\r\n" +
+			"Upgrade: websocket\r\n" +
+			"Connection: Upgrade\r\n" +
+			"Sec-WebSocket-Accept: wrong\r\n\r\n"))
+	}))
+	defer srv.Close()
+
+	_, err := New().WebSocket(srv.URL)
+	if !errors.Is(err, ErrWSHandshake) {
+		t.Fatalf("err = %v, want ErrWSHandshake", err)
+	}
+}
+
+func TestWebSocketMaskedFrames(t *testing.T) {
+	frames := make(chan wsTestFrame, 3)
+	srv := newWSTestServer(t, func(conn net.Conn, br *bufio.Reader) {
+		for i := 0; i < 3; i++ {
+			f, err := readTestFrame(br)
+			if err != nil {
+				t.Errorf("read frame: %v", err)
+				return
+			}
+			frames <- f
+			// 回显数据帧
+			if f.opcode == TextMessage || f.opcode == BinaryMessage {
+				writeTestFrame(conn, true, f.opcode, f.payload)
+			}
+		}
+	})
+
+	ws, err := New().WebSocket(srv.URL)
+	if err != nil {
+		t.Fatalf("WebSocket: %v", err)
+	}
+	defer ws.Close()
+
+	large := strings.Repeat("x", 70000) // 64位长度
+	messages := []struct {
+		opcode int
+		data   string
+	}{
+		{TextMessage, "hello"},
+		{BinaryMessage, strings.Repeat("b", 300)}, // 16位长度
+		{TextMessage, large},
+	}
+	for _, m := range messages {
+		if err := ws.WriteMessage(m.opcode, []byte(m.data)); err != nil {
+			t.Fatalf("WriteMessage: %v", err)
+		}
+		f := <-frames
+		if !f.masked {
+			t.Errorf("客户端帧未掩码")
+		}
+		if !f.fin || f.opcode != m.opcode || string(f.payload) != m.data {
+			t.Errorf("服务端收到 fin=%v opcode=%d len=%d, want opcode=%d len=%d", f.fin, f.opcode, len(f.payload), m.opcode, len(m.data))
+		}
+
+		typ, data, err := ws.ReadMessage()
+		if err != nil {
+			t.Fatalf("ReadMessage: %v", err)
+		}
+		if typ != m.opcode || string(data) != m.data {
+			t.Errorf("回显 opcode=%d len=%d, want opcode=%d len=%d", typ, len(data), m.opcode, len(m.data))
+		}
+	}
+}
+
+func TestWebSocketFragmentedMessage(t *testing.T) {
+	pong := make(chan wsTestFrame, 1)
+	srv := newWSTestServer(t, func(conn net.Conn, br *bufio.Reader) {
+		// 分片之间插入控制帧
+		writeTestFrame(conn, false, TextMessage, []byte("Hel"))
+		writeTestFrame(conn, true, PingMessage, []byte("p1"))
+		writeTestFrame(conn, false, 0, []byte("lo, "))
+		writeTestFrame(conn, true, 0, []byte("world"))
+		f, err := readTestFrame(br)
+		if err != nil {
+			t.Errorf("read pong: %v", err)
+			return
+		}
+		pong <- f
+		readTestFrame(br)
+	})
+
+	ws, err := New().WebSocket(srv.URL)
+	if err != nil {
+		t.Fatalf("WebSocket: %v", err)
+	}
+	defer ws.Close()
+
+	text, err := ws.ReadText()
+	if err != nil {
+		t.Fatalf("ReadText: %v", err)
+	}
+	if text != "Hello, world" {
+		t.Errorf("ReadText = %q, want %q", text, "Hello, world")
+	}
+
+	f := <-pong
+	if f.opcode != PongMessage || string(f.payload) != "p1" || !f.masked {
+		t.Errorf("Pong opcode=%d payload=%q masked=%v", f.opcode, f.payload, f.masked)
+	}
+}
+
+func TestWebSocketUnexpectedContinuation(t *testing.T) {
+	srv := newWSTestServer(t, func(conn net.Conn, br *bufio.Reader) {
+		writeTestFrame(conn, true, 0, []byte("orphan"))
+		readTestFrame(br)
+	})
+
+	ws, err := New().WebSocket(srv.URL)
+	if err != nil {
+		t.Fatalf("WebSocket: %v", err)
+	}
+	defer ws.Close()
+
+	if _, _, err := ws.ReadMessage(); err == nil {
+		t.Fatal("意外的续帧应返回错误")
+	}
+}
+
+func TestWebSocketPingPong(t *testing.T) {
+	srv := newWSTestServer(t, func(conn net.Conn, br *bufio.Reader) {
+		f, err := readTestFrame(br)
+		if err != nil {
+			t.Errorf("read ping: %v", err)
+			return
+		}
+		if f.opcode != PingMessage {
+			t.Errorf("opcode = %d, want Ping", f.opcode)
+		}
+		writeTestFrame(conn, true, PongMessage, f.payload)
+		writeTestFrame(conn, true, TextMessage, []byte("after pong"))
+		readTestFrame(br)
+	})
+
+	ws, err := New().WebSocket(srv.URL)
+	if err != nil {
+		t.Fatalf("WebSocket: %v", err)
+	}
+	defer ws.Close()
+
+	var got string
+	ws.SetPongHandler(func(data string) { got = data })
+	if err := ws.Ping([]byte("ping-1")); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+
+	// Pong 在读取消息时处理
+	text, err := ws.ReadText()
+	if err != nil {
+		t.Fatalf("ReadText: %v", err)
+	}
+	if text != "after pong" {
+		t.Errorf("ReadText = %q", text)
+	}
+	if got != "ping-1" {
+		t.Errorf("Pong data = %q, want ping-1", got)
+	}
+}
+
+func TestWebSocketServerClose(t *testing.T) {
+	reply := make(chan wsTestFrame, 1)
+	srv := newWSTestServer(t, func(conn net.Conn, br *bufio.Reader) {
+		payload := binary.BigEndian.AppendUint16(nil, CloseGoingAway)
+		writeTestFrame(conn, true, CloseMessage, append(payload, "bye"...))
+		f, err := readTestFrame(br)
+		if err != nil {
+			t.Errorf("read close: %v", err)
+			return
+		}
+		reply <- f
+	})
+
+	ws, err := New().WebSocket(srv.URL)
+	if err != nil {
+		t.Fatalf("WebSocket: %v", err)
+	}
+
+	_, _, err = ws.ReadMessage()
+	var closeErr *WSCloseError
+	if !errors.As(err, &closeErr) {
+		t.Fatalf("err = %v, want *WSCloseError", err)
+	}
+	if closeErr.Code != CloseGoingAway || closeErr.Reason != "bye" {
+		t.Errorf("close = %d %q", closeErr.Code, closeErr.Reason)
+	}
+
+	// 客户端回复关闭帧
+	f := <-reply
+	if f.opcode != CloseMessage || binary.BigEndian.Uint16(f.payload) != CloseGoingAway {
+		t.Errorf("回复 opcode=%d payload=%v", f.opcode, f.payload)
+	}
+
+	select {
+	case <-ws.Done():
+	case <-time.After(time.Second):
+		t.Fatal("连接未关闭")
+	}
+	if err := ws.WriteText("x"); !errors.Is(err, ErrWSClosed) {
+		t.Errorf("关闭后写入 err = %v, want ErrWSClosed", err)
+	}
+}
+
+func TestWebSocketClientClose(t *testing.T) {
+	frames := make(chan wsTestFrame, 1)
+	srv := newWSTestServer(t, func(conn net.Conn, br *bufio.Reader) {
+		f, err := readTestFrame(br)
+		if err != nil {
+			t.Errorf("read close: %v", err)
+			return
+		}
+		frames <- f
+	})
+
+	ws, err := New().WebSocket(srv.URL)
+	if err != nil {
+		t.Fatalf("WebSocket: %v", err)
+	}
+	if err := ws.CloseWithCode(CloseNormalClosure, "done"); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	f := <-frames
+	if f.opcode != CloseMessage || !f.masked {
+		t.Fatalf("opcode=%d masked=%v", f.opcode, f.masked)
+	}
+	if code := binary.BigEndian.Uint16(f.payload); code != CloseNormalClosure || string(f.payload[2:]) != "done" {
+		t.Errorf("close = %d %q", code, f.payload[2:])
+	}
+
+	if _, _, err := ws.ReadMessage(); !errors.Is(err, ErrWSClosed) {
+		t.Errorf("关闭后读取 err = %v, want ErrWSClosed", err)
+	}
+	// 重复关闭不报错
+	if err := ws.Close(); err != nil {
+		t.Errorf("重复关闭: %v", err)
+	}
+}
+
+func TestWebSocketWriteTimeout(t *testing.T) {
+	// 服务端完成握手后不再读取
+	release := make(chan struct{})
+	defer close(release)
+	srv := newWSTestServer(t, func(conn net.Conn, br *bufio.Reader) {
+		<-release
+	})
+
+	ws, err := New().WebSocketContext(context.Background(), srv.URL, &WSOptions{WriteTimeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("WebSocket: %v", err)
+	}
+	data := make([]byte, 1<<20)
+	var writeErr error
+	d := elapsed(func() {
+		for i := 0; i < 256 && writeErr == nil; i++ {
+			writeErr = ws.WriteMessage(BinaryMessage, data)
+		}
+	})
+	var netErr net.Error
+	if !errors.As(writeErr, &netErr) || !netErr.Timeout() {
+		t.Fatalf("写入 err = %v，耗时 %v", writeErr, d)
+	}
+	select {
+	case <-ws.Done():
+	default:
+		t.Error("写入超时后连接未关闭")
+	}
+}
+
+func TestWebSocketHTTPSProxy(t *testing.T) {
+	srv := newWSTestServer(t, func(conn net.Conn, br *bufio.Reader) {
+		writeTestFrame(conn, true, TextMessage, []byte("hello"))
+	})
+
+	// HTTPS 代理替身：TLS 连接上处理 CONNECT 并转发
+	var connects []string
+	proxySrv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			http.Error(w, "connect only", http.StatusMethodNotAllowed)
+			return
+		}
+		connects = append(connects, r.Host)
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer upstream.Close()
+		conn, brw, _ := w.(http.Hijacker).Hijack()
+		defer conn.Close()
+		conn.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n"))
+		go io.Copy(upstream, brw)
+		io.Copy(conn, upstream)
+	}))
+	defer proxySrv.Close()
+
+	client := New().SetVerify(false).SetProxy(proxySrv.URL, "http")
+	ws, err := client.WebSocket(srv.URL)
+	if err != nil {
+		t.Fatalf("WebSocket: %v", err)
+	}
+	defer ws.Close()
+	if text, err := ws.ReadText(); err != nil || text != "hello" {
+		t.Errorf("ReadText = %q, %v", text, err)
+	}
+	if len(connects) != 1 || connects[0] != strings.TrimPrefix(srv.URL, "http://") {
+		t.Errorf("CONNECT = %v", connects)
+	}
+
+	// 代理证书校验失败
+	if _, err := New().SetProxy(proxySrv.URL, "http").WebSocket(srv.URL); err == nil || !strings.Contains(err.Error(), "代理TLS握手失败") {
+		t.Errorf("未校验代理证书: %v", err)
+	}
+}
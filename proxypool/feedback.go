@@ -0,0 +1,101 @@
+package proxypool
+
+import (
+	"time"
+
+	"github.com/Drunkard-baifeng/golibs/logger"
+)
+
+// FailureReason 代理失败原因
+type FailureReason string
+
+const (
+	FailTimeout FailureReason = "timeout" // 超时
+	FailConnect FailureReason = "connect" // 连接失败
+	FailBlocked FailureReason = "blocked" // 被目标站封禁（如 403、验证码）
+	FailAuth    FailureReason = "auth"    // 代理认证失败
+	FailOther   FailureReason = "other"   // 其他
+)
+
+// SetMaxFailures 设置连续失败多少次后封禁代理（小于等于0 不按次数封禁）
+func (p *ProxyPool) SetMaxFailures(count int) *ProxyPool {
+	p.maxFailures = count
+	return p
+}
+
+// SetBanReasons 设置立即封禁的失败原因
+func (p *ProxyPool) SetBanReasons(reasons ...FailureReason) *ProxyPool {
+	p.banReasons = reasons
+	return p
+}
+
+// SetBanDuration 设置封禁时长（0 永久封禁，清理时移除；大于0 临时封禁，健康检查不会提前解除）
+func (p *ProxyPool) SetBanDuration(d time.Duration) *ProxyPool {
+	p.banDuration = d
+	return p
+}
+
+// ReportSuccess 报告代理使用成功，latency 为本次请求耗时（0 不记录）
+func (p *ProxyPool) ReportSuccess(proxy *ProxyItem, latency time.Duration) {
+	proxy.recordSuccess(latency)
+}
+
+// ReportFailure 报告代理使用失败
+// 原因在封禁列表中时立即封禁，否则连续失败达到阈值后封禁
+// 返回是否封禁了该代理
+func (p *ProxyPool) ReportFailure(proxy *ProxyItem, reason FailureReason) bool {
+	failures := proxy.recordFailure(reason)
+
+	ban := p.maxFailures > 0 && failures >= p.maxFailures
+	for _, r := range p.banReasons {
+		if r == reason {
+			ban = true
+			break
+		}
+	}
+	if !ban {
+		return false
+	}
+
+	if p.banDuration > 0 {
+		proxy.BanFor(p.banDuration)
+		proxy.resetConsecutiveFailures()
+		logger.Debugf("代理 %s 失败(%s) %d 次，封禁 %v", proxy.String(), reason, failures, p.banDuration)
+	} else {
+		proxy.Ban()
+		logger.Debugf("代理 %s 失败(%s) %d 次，已封禁", proxy.String(), reason, failures)
+	}
+	return true
+}
+
+// ReportSuccessByString 通过 ip:port（IPv6 可带或不带方括号）或 Key() 报告代理使用成功
+func (p *ProxyPool) ReportSuccessByString(proxyStr string, latency time.Duration) bool {
+	proxy := p.find(proxyStr)
+	if proxy == nil {
+		return false
+	}
+	p.ReportSuccess(proxy, latency)
+	return true
+}
+
+// ReportFailureByString 通过 ip:port（IPv6 可带或不带方括号）或 Key() 报告代理使用失败，返回是否封禁
+func (p *ProxyPool) ReportFailureByString(proxyStr string, reason FailureReason) bool {
+	proxy := p.find(proxyStr)
+	if proxy == nil {
+		return false
+	}
+	return p.ReportFailure(proxy, reason)
+}
+
+// find 查找代理（见 ProxyItem.matches）
+func (p *ProxyPool) find(proxyStr string) *ProxyItem {
+	p.poolMu.RLock()
+	defer p.poolMu.RUnlock()
+
+	for _, proxy := range p.proxies {
+		if proxy.matches(proxyStr) {
+			return proxy
+		}
+	}
+	return nil
+}
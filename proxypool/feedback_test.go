@@ -0,0 +1,126 @@
+package proxypool
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTimedBanSurvivesHealthCheck(t *testing.T) {
+	target := newTestTarget(t)
+	var healthy atomic.Bool
+	healthy.Store(true)
+	proxy := newTestProxy(t, &healthy)
+
+	p := New(Config{MaxUseCount: 100, ExpireSeconds: 60, BanDuration: 50 * time.Millisecond})
+	item := addServerProxy(t, p, proxy.URL)
+
+	if !p.ReportFailure(item, FailBlocked) {
+		t.Fatal("FailBlocked 应立即封禁")
+	}
+	if !item.IsBanned() || item.IsQuarantined() {
+		t.Fatalf("banned=%v quarantined=%v", item.IsBanned(), item.IsQuarantined())
+	}
+	stats := p.GetStats()
+	if stats.Banned != 1 || stats.Quarantined != 0 || stats.Available != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	// 健康检查通过不能解除封禁
+	results, _ := p.CheckNow(context.Background(), HealthCheckConfig{TestURL: target.URL, Action: HealthQuarantine})
+	if len(results) != 1 || results[0].OK {
+		t.Errorf("封禁中的代理不应检测: %+v", results)
+	}
+	if !item.IsBanned() {
+		t.Fatal("健康检查解除了封禁")
+	}
+
+	// 临时封禁不会被清理，到期后恢复
+	time.Sleep(60 * time.Millisecond)
+	if !p.Contains(item) || item.IsBanned() {
+		t.Fatalf("到期后 contains=%v banned=%v", p.Contains(item), item.IsBanned())
+	}
+	if got, err := p.Get(); err != nil || got != item {
+		t.Errorf("到期后 Get = %v, %v", got, err)
+	}
+}
+
+func TestMaxFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      int
+		set      *int
+		failures int
+		banned   bool
+	}{
+		{"默认3次", 0, nil, 3, true},
+		{"默认未到3次", 0, nil, 2, false},
+		{"配置-1不按次数封禁", -1, nil, 10, false},
+		{"SetMaxFailures(0)不按次数封禁", 0, new(int), 10, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(Config{MaxUseCount: 100, ExpireSeconds: 60, MaxFailures: tt.cfg})
+			if tt.set != nil {
+				p.SetMaxFailures(*tt.set)
+			}
+			p.AddProxy("10.0.0.1", "8080")
+			item := p.GetAll()[0]
+			for i := 0; i < tt.failures; i++ {
+				p.ReportFailure(item, FailTimeout)
+			}
+			if item.IsBanned() != tt.banned {
+				t.Errorf("banned = %v, want %v", item.IsBanned(), tt.banned)
+			}
+		})
+	}
+}
+
+func TestRefreshCountsOnlyAvailable(t *testing.T) {
+	tests := []struct {
+		name    string
+		disable func(p *ProxyPool, item *ProxyItem)
+	}{
+		{"隔离中", func(p *ProxyPool, item *ProxyItem) { item.Quarantine(time.Minute) }},
+		{"临时封禁", func(p *ProxyPool, item *ProxyItem) { item.BanFor(time.Minute) }},
+		{"SetBanDuration后按原因封禁", func(p *ProxyPool, item *ProxyItem) {
+			p.SetBanDuration(time.Minute)
+			p.ReportFailure(item, FailBlocked)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fetches atomic.Int32
+			p := New(Config{MaxUseCount: 100, ExpireSeconds: 60, FetchFunc: func(string) ([]ProxyAddr, error) {
+				n := fetches.Add(1)
+				return []ProxyAddr{{IP: "10.0.1.1", Port: fmt.Sprint(9000 + n)}}, nil
+			}})
+			p.AddProxy("10.0.0.1", "8080")
+			tt.disable(p, p.GetAll()[0])
+
+			// 池中仍有代理但都不可用，同步刷新
+			got, err := p.Get()
+			if err != nil || got.IP != "10.0.1.1" {
+				t.Fatalf("Get = %v, %v", got, err)
+			}
+			if fetches.Load() != 1 {
+				t.Fatalf("同步刷新 %d 次, want 1", fetches.Load())
+			}
+
+			// 可用数量低于最小池大小，后台刷新
+			p.SetMinPoolSize(2)
+			if _, err := p.Get(); err != nil {
+				t.Fatal(err)
+			}
+			deadline := time.Now().Add(time.Second)
+			for fetches.Load() < 2 && time.Now().Before(deadline) {
+				time.Sleep(5 * time.Millisecond)
+			}
+			if fetches.Load() != 2 {
+				t.Errorf("后台刷新 %d 次, want 1", fetches.Load()-1)
+			}
+		})
+	}
+}
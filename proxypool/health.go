@@ -0,0 +1,270 @@
+package proxypool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/Drunkard-baifeng/golibs/logger"
+)
+
+// ErrHealthURLEmpty 未设置检测地址
+var ErrHealthURLEmpty = errors.New("健康检查地址为空")
+
+// HealthAction 检测失败后的处理方式
+type HealthAction string
+
+const (
+	HealthEvict      HealthAction = "evict"      // 从池中移除
+	HealthQuarantine HealthAction = "quarantine" // 隔离一段时间，期间不分配，恢复后可继续使用
+)
+
+// HealthCheckConfig 健康检查配置
+type HealthCheckConfig struct {
+	TestURL            string             // 检测地址（必填）
+	ProxyType          ProxyType          // 通过哪种协议访问代理（默认按代理自身的协议）
+	Interval           time.Duration      // 检测间隔（默认30秒）
+	Timeout            time.Duration      // 单次检测超时（默认5秒）
+	Concurrency        int                // 并发检测数（默认10）
+	ExpectStatus       int                // 期望的状态码（0 表示任意 2xx）
+	MaxFailures        int                // 连续失败多少次后处理（默认1）
+	Action             HealthAction       // 处理方式（默认移除）
+	QuarantineDuration time.Duration      // 隔离时长（默认60秒）
+	OnCheck            func(HealthResult) // 每次检测后的回调
+}
+
+// HealthResult 单个代理的检测结果
+type HealthResult struct {
+	Proxy      *ProxyItem    // 被检测的代理
+	OK         bool          // 是否通过
+	StatusCode int           // 响应状态码
+	Latency    time.Duration // 耗时
+	Err        error         // 失败原因
+}
+
+// healthChecker 后台检测任务
+type healthChecker struct {
+	cfg  HealthCheckConfig
+	stop chan struct{}
+	done chan struct{}
+}
+
+// withDefaults 填充默认值
+func (cfg HealthCheckConfig) withDefaults() HealthCheckConfig {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 30 * time.Second
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 10
+	}
+	if cfg.MaxFailures <= 0 {
+		cfg.MaxFailures = 1
+	}
+	if cfg.Action == "" {
+		cfg.Action = HealthEvict
+	}
+	if cfg.QuarantineDuration <= 0 {
+		cfg.QuarantineDuration = 60 * time.Second
+	}
+	return cfg
+}
+
+// SetHealthCheck 设置并启动后台健康检查（已在运行的会先停止）
+// 配置错误时记录日志并不启动，需要处理错误请使用 StartHealthCheck
+func (p *ProxyPool) SetHealthCheck(cfg HealthCheckConfig) *ProxyPool {
+	if err := p.StartHealthCheck(cfg); err != nil {
+		logger.Errorf("启动健康检查失败: %v", err)
+	}
+	return p
+}
+
+// StartHealthCheck 启动后台健康检查，立即检测一轮，之后按间隔检测
+func (p *ProxyPool) StartHealthCheck(cfg HealthCheckConfig) error {
+	if cfg.TestURL == "" {
+		return ErrHealthURLEmpty
+	}
+	p.StopHealthCheck()
+
+	h := &healthChecker{
+		cfg:  cfg.withDefaults(),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	p.healthMu.Lock()
+	p.health = h
+	p.healthMu.Unlock()
+
+	go p.runHealthCheck(h)
+	return nil
+}
+
+// StopHealthCheck 停止后台健康检查（等待进行中的检测结束）
+func (p *ProxyPool) StopHealthCheck() {
+	p.healthMu.Lock()
+	h := p.health
+	p.health = nil
+	p.healthMu.Unlock()
+
+	if h != nil {
+		close(h.stop)
+		<-h.done
+	}
+}
+
+// runHealthCheck 后台检测循环
+func (p *ProxyPool) runHealthCheck(h *healthChecker) {
+	defer close(h.done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-h.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(h.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		p.checkAll(ctx, h.cfg)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// CheckNow 立即检测池中所有代理并按配置处理失败的代理
+func (p *ProxyPool) CheckNow(ctx context.Context, cfg HealthCheckConfig) ([]HealthResult, error) {
+	if cfg.TestURL == "" {
+		return nil, ErrHealthURLEmpty
+	}
+	return p.checkAll(ctx, cfg.withDefaults()), nil
+}
+
+// checkAll 并发检测所有未过期的代理
+func (p *ProxyPool) checkAll(ctx context.Context, cfg HealthCheckConfig) []HealthResult {
+	proxies := p.GetAll()
+	results := make([]HealthResult, len(proxies))
+
+	sem := make(chan struct{}, cfg.Concurrency)
+	var wg sync.WaitGroup
+	for i, proxy := range proxies {
+		if proxy.isRetired() {
+			results[i] = HealthResult{Proxy: proxy, Err: errors.New("代理已失效")}
+			continue
+		}
+		// 封禁中的代理不检测，检测通过也不能解除封禁
+		if proxy.IsBanned() {
+			results[i] = HealthResult{Proxy: proxy, Err: errors.New("代理封禁中")}
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i] = HealthResult{Proxy: proxy, Err: ctx.Err()}
+			continue
+		}
+		wg.Add(1)
+		go func(i int, proxy *ProxyItem) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = p.checkOne(ctx, proxy, cfg)
+		}(i, proxy)
+	}
+	wg.Wait()
+	return results
+}
+
+// checkOne 检测单个代理并记录结果
+func (p *ProxyPool) checkOne(ctx context.Context, proxy *ProxyItem, cfg HealthCheckConfig) HealthResult {
+	result := CheckProxy(ctx, proxy, cfg)
+	if ctx.Err() != nil {
+		// 检测被取消，不计入失败
+		return result
+	}
+
+	failures := proxy.recordCheck(result)
+	if result.OK {
+		// 隔离中的代理可能已恢复
+		p.notifyWaiters()
+	} else if failures >= cfg.MaxFailures {
+		switch cfg.Action {
+		case HealthQuarantine:
+			proxy.Quarantine(cfg.QuarantineDuration)
+			logger.Debugf("代理 %s 检测失败 %d 次，隔离 %v: %v", proxy.String(), failures, cfg.QuarantineDuration, result.Err)
+		default:
+			p.RemoveItem(proxy)
+			logger.Debugf("代理 %s 检测失败 %d 次，已移除: %v", proxy.String(), failures, result.Err)
+		}
+	}
+
+	if cfg.OnCheck != nil {
+		cfg.OnCheck(result)
+	}
+	return result
+}
+
+// CheckProxy 通过代理访问检测地址，返回检测结果（不修改代理状态）
+func CheckProxy(ctx context.Context, proxy *ProxyItem, cfg HealthCheckConfig) HealthResult {
+	cfg = cfg.withDefaults()
+	result := HealthResult{Proxy: proxy}
+
+	rawURL := proxy.URL()
+	if cfg.ProxyType != "" {
+		rawURL = proxy.buildURL(string(cfg.ProxyType))
+	}
+	proxyURL, err := url.Parse(rawURL)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	transport := &http.Transport{
+		Proxy:             http.ProxyURL(proxyURL),
+		DisableKeepAlives: true,
+	}
+	defer transport.CloseIdleConnections()
+	client := &http.Client{Transport: transport, Timeout: cfg.Timeout}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cfg.TestURL, nil)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		result.Latency = time.Since(start)
+		result.Err = err
+		return result
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+	result.Latency = time.Since(start)
+	result.StatusCode = resp.StatusCode
+
+	if cfg.ExpectStatus != 0 {
+		result.OK = resp.StatusCode == cfg.ExpectStatus
+	} else {
+		result.OK = resp.StatusCode >= 200 && resp.StatusCode < 300
+	}
+	if !result.OK {
+		result.Err = fmt.Errorf("状态码异常: %d", resp.StatusCode)
+	}
+	return result
+}
@@ -0,0 +1,256 @@
+package proxypool
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestTarget 健康检查访问的目标服务器
+func newTestTarget(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newTestProxy 模拟HTTP代理，healthy 为 false 时返回 502
+func newTestProxy(t *testing.T, healthy *atomic.Bool) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() || r.URL.Host == "" {
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+			return
+		}
+		// 转发到目标地址
+		out := r.Clone(r.Context())
+		out.RequestURI = ""
+		resp, err := http.DefaultTransport.RoundTrip(out)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		w.WriteHeader(resp.StatusCode)
+		buf := make([]byte, 1024)
+		n, _ := resp.Body.Read(buf)
+		w.Write(buf[:n])
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// closedAddr 没有监听的地址（连接被拒绝）
+func closedAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
+
+// addServerProxy 把测试服务器作为HTTP代理加入池中
+func addServerProxy(t *testing.T, p *ProxyPool, addr string) *ProxyItem {
+	t.Helper()
+	if u, err := url.Parse(addr); err == nil && u.Host != "" {
+		addr = u.Host
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.AddProxyAddr(ProxyAddr{IP: host, Port: port, Protocol: TypeHTTP}) {
+		t.Fatalf("添加代理失败: %s", addr)
+	}
+	for _, item := range p.GetAll() {
+		if item.String() == addr {
+			return item
+		}
+	}
+	t.Fatalf("代理不在池中: %s", addr)
+	return nil
+}
+
+func TestCheckProxy(t *testing.T) {
+	target := newTestTarget(t)
+	var healthy atomic.Bool
+	healthy.Store(true)
+	proxy := newTestProxy(t, &healthy)
+
+	p := New(Config{MaxUseCount: 100, ExpireSeconds: 60})
+	good := addServerProxy(t, p, proxy.URL)
+	bad := addServerProxy(t, p, closedAddr(t))
+	cfg := HealthCheckConfig{TestURL: target.URL, Timeout: 2 * time.Second}
+
+	result := CheckProxy(context.Background(), good, cfg)
+	if !result.OK || result.StatusCode != http.StatusOK || result.Err != nil {
+		t.Errorf("正常代理检测结果 = %+v", result)
+	}
+	if result.Latency <= 0 {
+		t.Errorf("Latency = %v", result.Latency)
+	}
+
+	result = CheckProxy(context.Background(), bad, cfg)
+	if result.OK || result.Err == nil {
+		t.Errorf("失效代理检测结果 = %+v", result)
+	}
+
+	// 状态码不符合期望
+	cfg.ExpectStatus = http.StatusNoContent
+	result = CheckProxy(context.Background(), good, cfg)
+	if result.OK || result.StatusCode != http.StatusOK {
+		t.Errorf("ExpectStatus 检测结果 = %+v", result)
+	}
+
+	// CheckProxy 不修改代理状态
+	if good.GetCheckFailures() != 0 || !good.GetLastCheckTime().IsZero() {
+		t.Error("CheckProxy 修改了代理状态")
+	}
+}
+
+func TestCheckNowEvictsFailingProxy(t *testing.T) {
+	target := newTestTarget(t)
+	var healthy atomic.Bool
+	healthy.Store(true)
+	proxy := newTestProxy(t, &healthy)
+
+	p := New(Config{MaxUseCount: 100, ExpireSeconds: 60})
+	good := addServerProxy(t, p, proxy.URL)
+	bad := addServerProxy(t, p, closedAddr(t))
+
+	var checked atomic.Int32
+	results, err := p.CheckNow(context.Background(), HealthCheckConfig{
+		TestURL: target.URL,
+		Timeout: 2 * time.Second,
+		OnCheck: func(HealthResult) { checked.Add(1) },
+	})
+	if err != nil {
+		t.Fatalf("CheckNow: %v", err)
+	}
+	if len(results) != 2 || checked.Load() != 2 {
+		t.Fatalf("results = %d, OnCheck = %d", len(results), checked.Load())
+	}
+	for _, r := range results {
+		if (r.Proxy == good) != r.OK {
+			t.Errorf("%s OK = %v", r.Proxy, r.OK)
+		}
+	}
+
+	// 默认处理方式为移除
+	if p.Contains(bad) {
+		t.Error("失效代理未被移除")
+	}
+	if !p.Contains(good) || good.GetLatency() <= 0 || good.GetLastCheckTime().IsZero() {
+		t.Errorf("正常代理状态异常: latency=%v", good.GetLatency())
+	}
+}
+
+func TestCheckNowQuarantineAndRecover(t *testing.T) {
+	target := newTestTarget(t)
+	var healthy atomic.Bool
+	proxy := newTestProxy(t, &healthy)
+
+	p := New(Config{MaxUseCount: 100, ExpireSeconds: 60})
+	item := addServerProxy(t, p, proxy.URL)
+	cfg := HealthCheckConfig{
+		TestURL:            target.URL,
+		Timeout:            2 * time.Second,
+		MaxFailures:        2,
+		Action:             HealthQuarantine,
+		QuarantineDuration: time.Minute,
+	}
+
+	// 第一次失败未达到阈值
+	p.CheckNow(context.Background(), cfg)
+	if item.IsQuarantined() || item.GetCheckFailures() != 1 {
+		t.Fatalf("失败1次: quarantined=%v failures=%d", item.IsQuarantined(), item.GetCheckFailures())
+	}
+
+	// 连续失败达到阈值后隔离，不再分配
+	p.CheckNow(context.Background(), cfg)
+	if !item.IsQuarantined() {
+		t.Fatal("连续失败后未隔离")
+	}
+	if stats := p.GetStats(); stats.Quarantined != 1 || stats.Available != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if _, err := p.get("", false, false); !errors.Is(err, ErrNoAvailableProxy) {
+		t.Errorf("隔离中的代理被分配: %v", err)
+	}
+	if !p.Contains(item) {
+		t.Fatal("隔离的代理不应被移除")
+	}
+
+	// 恢复后检测通过，解除隔离
+	healthy.Store(true)
+	results, _ := p.CheckNow(context.Background(), cfg)
+	if len(results) != 1 || !results[0].OK {
+		t.Fatalf("恢复后检测结果 = %+v", results)
+	}
+	if item.IsQuarantined() || item.GetCheckFailures() != 0 {
+		t.Errorf("恢复后 quarantined=%v failures=%d", item.IsQuarantined(), item.GetCheckFailures())
+	}
+	got, err := p.Get()
+	if err != nil || got != item {
+		t.Errorf("恢复后 Get = %v, %v", got, err)
+	}
+}
+
+func TestStartHealthCheck(t *testing.T) {
+	target := newTestTarget(t)
+	var healthy atomic.Bool
+	healthy.Store(true)
+	proxy := newTestProxy(t, &healthy)
+
+	// 配置错误时 New 记录日志，不启动检测
+	p := New(Config{MaxUseCount: 100, ExpireSeconds: 60, HealthCheck: &HealthCheckConfig{}})
+	if p.health != nil {
+		t.Error("缺少检测地址时不应启动健康检查")
+	}
+	if err := p.StartHealthCheck(HealthCheckConfig{}); !errors.Is(err, ErrHealthURLEmpty) {
+		t.Errorf("err = %v, want ErrHealthURLEmpty", err)
+	}
+	if _, err := p.CheckNow(context.Background(), HealthCheckConfig{}); !errors.Is(err, ErrHealthURLEmpty) {
+		t.Errorf("CheckNow err = %v, want ErrHealthURLEmpty", err)
+	}
+
+	good := addServerProxy(t, p, proxy.URL)
+	bad := addServerProxy(t, p, closedAddr(t))
+
+	checked := make(chan HealthResult, 10)
+	err := p.StartHealthCheck(HealthCheckConfig{
+		TestURL:  target.URL,
+		Interval: time.Hour,
+		Timeout:  2 * time.Second,
+		OnCheck:  func(r HealthResult) { checked <- r },
+	})
+	if err != nil {
+		t.Fatalf("StartHealthCheck: %v", err)
+	}
+	defer p.StopHealthCheck()
+
+	// 启动后立即检测一轮
+	for i := 0; i < 2; i++ {
+		select {
+		case <-checked:
+		case <-time.After(5 * time.Second):
+			t.Fatal("等待检测超时")
+		}
+	}
+	p.StopHealthCheck()
+
+	if p.Contains(bad) || !p.Contains(good) {
+		t.Errorf("后台检测后 good=%v bad=%v", p.Contains(good), p.Contains(bad))
+	}
+}
@@ -0,0 +1,316 @@
+package proxypool
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 服务商错误类型（使用 errors.Is 判断）
+var (
+	ErrProvider            = errors.New("代理服务商返回错误")
+	ErrBalanceInsufficient = errors.New("代理余额不足")
+	ErrWhitelist           = errors.New("IP不在白名单")
+	ErrRateLimited         = errors.New("提取过于频繁")
+	ErrProviderAuth        = errors.New("代理API认证失败")
+)
+
+// ProviderError 服务商返回的错误
+type ProviderError struct {
+	Kind error  // 错误类型 ErrBalanceInsufficient、ErrWhitelist 等，无法识别时为 ErrProvider
+	Code string // 服务商错误码
+	Msg  string // 服务商错误信息
+}
+
+func (e *ProviderError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("%v: [%s] %s", e.Kind, e.Code, e.Msg)
+	}
+	return fmt.Sprintf("%v: %s", e.Kind, e.Msg)
+}
+
+func (e *ProviderError) Is(target error) bool {
+	return target == e.Kind || target == ErrProvider
+}
+
+// 错误信息关键字（小写匹配，按顺序）
+var providerErrorKeywords = []struct {
+	kind     error
+	keywords []string
+}{
+	{ErrWhitelist, []string{"白名单", "whitelist", "white list", "not in allowed ip", "ip not allowed"}},
+	{ErrBalanceInsufficient, []string{"余额", "欠费", "套餐", "额度", "balance", "insufficient", "no package", "quota"}},
+	{ErrRateLimited, []string{"频繁", "过快", "稍后", "too many", "too frequent", "rate limit"}},
+	{ErrProviderAuth, []string{"密钥", "签名", "apikey", "api key", "token", "signature", "unauthorized", "认证失败"}},
+}
+
+// ClassifyProviderError 根据错误码和错误信息识别服务商错误类型
+func ClassifyProviderError(code, msg string) *ProviderError {
+	lower := strings.ToLower(msg)
+	for _, item := range providerErrorKeywords {
+		for _, kw := range item.keywords {
+			if strings.Contains(lower, kw) {
+				return &ProviderError{Kind: item.kind, Code: code, Msg: msg}
+			}
+		}
+	}
+	return &ProviderError{Kind: ErrProvider, Code: code, Msg: msg}
+}
+
+// DetectProviderError 检查文本响应是否为已知的服务商错误（余额、白名单等），不是则返回 nil
+func DetectProviderError(text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	if err := ClassifyProviderError("", text); err.Kind != ErrProvider {
+		return err
+	}
+	return nil
+}
+
+// JSONFetchConfig JSON 格式提取接口的解析配置
+// 路径用 . 分隔，数组下标用数字，例如 "data.list"、"data.0.ip"
+type JSONFetchConfig struct {
+	Client *http.Client // HTTP客户端（默认10秒超时）
+
+	ListPath     string // 代理列表路径（空表示根节点，根节点为对象时按单个代理处理）
+	IPPath       string // IP 字段（默认 "ip"）
+	PortPath     string // 端口字段（默认 "port"）
+	AddrPath     string // ip:port 合并字段（设置后忽略 IPPath、PortPath）
+	UserPath     string // 用户名字段
+	PassPath     string // 密码字段
+	ProtocolPath string // 协议字段
+	CountryPath  string // 国家/地区字段
+	ExpirePath   string // 过期时间字段（见 ParseExpireTimeIn）
+	TTLPath      string // 剩余有效秒数字段
+
+	ExpireLocation *time.Location // 不带时区的过期时间所用时区（默认本地时区）
+
+	CodePath     string   // 错误码字段
+	MsgPath      string   // 错误信息字段
+	SuccessCodes []string // 表示成功的错误码，忽略大小写（默认 DefaultSuccessCodes）
+
+	Protocol ProxyType // 未返回协议时使用的协议
+
+	// Classify 自定义错误识别（默认 ClassifyProviderError）
+	Classify func(code, msg string) *ProviderError
+}
+
+// DefaultSuccessCodes 服务商常用的表示成功的错误码
+// 不包含 "1"、"true"：不少服务商用 1 表示错误，以它们表示成功的服务商需在 SuccessCodes 中指定
+var DefaultSuccessCodes = []string{"0", "200", "success", "ok"}
+
+// withDefaults 填充默认值
+func (cfg JSONFetchConfig) withDefaults() JSONFetchConfig {
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if cfg.IPPath == "" {
+		cfg.IPPath = "ip"
+	}
+	if cfg.PortPath == "" {
+		cfg.PortPath = "port"
+	}
+	if len(cfg.SuccessCodes) == 0 {
+		cfg.SuccessCodes = DefaultSuccessCodes
+	}
+	if cfg.Classify == nil {
+		cfg.Classify = ClassifyProviderError
+	}
+	return cfg
+}
+
+// NewJSONFetchFunc 创建解析 JSON 提取接口的获取函数
+func NewJSONFetchFunc(cfg JSONFetchConfig) FetchFunc {
+	cfg = cfg.withDefaults()
+	return func(apiURL string) ([]ProxyAddr, error) {
+		resp, err := cfg.Client.Get(apiURL)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return nil, &ProviderError{Kind: ErrRateLimited, Code: strconv.Itoa(resp.StatusCode), Msg: string(body)}
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return nil, cfg.Classify(strconv.Itoa(resp.StatusCode), string(body))
+		}
+
+		return ParseJSONProxies(body, cfg)
+	}
+}
+
+// ParseJSONProxies 按配置解析 JSON 响应
+// 错误码不在 SuccessCodes 中，或设置了 CodePath 但没有代理列表时返回 *ProviderError
+func ParseJSONProxies(body []byte, cfg JSONFetchConfig) ([]ProxyAddr, error) {
+	cfg = cfg.withDefaults()
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var root interface{}
+	if err := decoder.Decode(&root); err != nil {
+		// 不是 JSON，可能是纯文本错误信息
+		if perr := DetectProviderError(string(body)); perr != nil {
+			return nil, perr
+		}
+		return nil, fmt.Errorf("解析代理JSON失败: %w", err)
+	}
+
+	code, _ := jsonString(jsonPath(root, cfg.CodePath))
+	if cfg.CodePath != "" {
+		if !containsString(cfg.SuccessCodes, code) {
+			msg, _ := jsonString(jsonPath(root, cfg.MsgPath))
+			return nil, cfg.Classify(code, msg)
+		}
+	}
+
+	node := root
+	if cfg.ListPath != "" {
+		node = jsonPath(root, cfg.ListPath)
+	}
+
+	var items []interface{}
+	switch v := node.(type) {
+	case []interface{}:
+		items = v
+	case map[string]interface{}:
+		items = []interface{}{v}
+	case nil:
+		msg, _ := jsonString(jsonPath(root, cfg.MsgPath))
+		// 有错误码时成功响应应带代理列表，没有列表按服务商错误处理
+		if cfg.CodePath != "" {
+			if msg == "" {
+				msg = "响应中没有代理列表"
+			}
+			return nil, cfg.Classify(code, msg)
+		}
+		// 没有代理列表，可能是没有错误码的错误响应
+		if perr := DetectProviderError(msg); perr != nil {
+			return nil, perr
+		}
+		return []ProxyAddr{}, nil
+	default:
+		return nil, fmt.Errorf("代理列表格式错误: %s", cfg.ListPath)
+	}
+
+	result := make([]ProxyAddr, 0, len(items))
+	for _, item := range items {
+		addr, ok := parseJSONProxy(item, cfg)
+		if ok {
+			result = append(result, addr)
+		}
+	}
+	return result, nil
+}
+
+// parseJSONProxy 解析单个代理
+func parseJSONProxy(item interface{}, cfg JSONFetchConfig) (ProxyAddr, bool) {
+	var addr ProxyAddr
+
+	// 列表元素也可能直接是 "ip:port" 字符串
+	if s, ok := item.(string); ok {
+		host, port, err := net.SplitHostPort(strings.TrimSpace(s))
+		if err != nil {
+			return addr, false
+		}
+		addr.IP, addr.Port = host, port
+		addr.Protocol = cfg.Protocol
+		return addr, true
+	}
+
+	if cfg.AddrPath != "" {
+		s, _ := jsonString(jsonPath(item, cfg.AddrPath))
+		host, port, err := net.SplitHostPort(strings.TrimSpace(s))
+		if err != nil {
+			return addr, false
+		}
+		addr.IP, addr.Port = host, port
+	} else {
+		addr.IP, _ = jsonString(jsonPath(item, cfg.IPPath))
+		addr.Port, _ = jsonString(jsonPath(item, cfg.PortPath))
+	}
+	if addr.IP == "" || addr.Port == "" {
+		return addr, false
+	}
+
+	addr.Username, _ = jsonString(jsonPath(item, cfg.UserPath))
+	addr.Password, _ = jsonString(jsonPath(item, cfg.PassPath))
+	addr.Country, _ = jsonString(jsonPath(item, cfg.CountryPath))
+
+	addr.Protocol = cfg.Protocol
+	if protocol, _ := jsonString(jsonPath(item, cfg.ProtocolPath)); protocol != "" {
+		addr.Protocol = ProxyType(strings.ToLower(protocol))
+	}
+
+	if expire, ok := jsonString(jsonPath(item, cfg.ExpirePath)); ok {
+		if t, err := ParseExpireTimeIn(expire, cfg.ExpireLocation); err == nil {
+			addr.ExpireTime = t
+		}
+	}
+	if ttl, ok := jsonString(jsonPath(item, cfg.TTLPath)); ok {
+		if seconds, err := strconv.Atoi(ttl); err == nil && seconds > 0 {
+			addr.ExpireSeconds = seconds
+		}
+	}
+	return addr, true
+}
+
+// jsonPath 按路径取值（路径为空或不存在时返回 nil）
+func jsonPath(node interface{}, path string) interface{} {
+	if path == "" {
+		return nil
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch v := node.(type) {
+		case map[string]interface{}:
+			node = v[key]
+		case []interface{}:
+			idx, err := strconv.Atoi(key)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return nil
+			}
+			node = v[idx]
+		default:
+			return nil
+		}
+	}
+	return node
+}
+
+// jsonString 将 JSON 标量转为字符串
+func jsonString(v interface{}) (string, bool) {
+	switch val := v.(type) {
+	case string:
+		return val, true
+	case json.Number:
+		return val.String(), true
+	case bool:
+		return strconv.FormatBool(val), true
+	default:
+		return "", false
+	}
+}
+
+// containsString 切片是否包含字符串（忽略大小写）
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,204 @@
+package proxypool
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONFetchExpireLocation(t *testing.T) {
+	body := []byte(`{"code":0,"data":[{"ip":"10.0.0.1","port":8080,"expire":"2099-01-01 08:00:00"}]}`)
+	cfg := JSONFetchConfig{
+		ListPath:       "data",
+		ExpirePath:     "expire",
+		CodePath:       "code",
+		ExpireLocation: time.FixedZone("CST", 8*3600),
+	}
+	addrs, err := ParseJSONProxies(body, cfg)
+	if err != nil || len(addrs) != 1 {
+		t.Fatalf("ParseJSONProxies = %v, %v", addrs, err)
+	}
+	if want := time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC); !addrs[0].ExpireTime.Equal(want) {
+		t.Errorf("ExpireTime = %v, want %v", addrs[0].ExpireTime.UTC(), want)
+	}
+}
+
+func TestParseJSONProxies(t *testing.T) {
+	envelope := JSONFetchConfig{CodePath: "code", MsgPath: "msg", ListPath: "data"}
+	tests := []struct {
+		name string
+		body string
+		cfg  JSONFetchConfig
+		want []ProxyAddr
+		err  error // nil 表示应成功
+	}{
+		{"根节点列表", `[{"ip":"10.0.0.1","port":8080},{"ip":"10.0.0.2","port":"8081"}]`, JSONFetchConfig{},
+			[]ProxyAddr{{IP: "10.0.0.1", Port: "8080"}, {IP: "10.0.0.2", Port: "8081"}}, nil},
+		{"根节点对象", `{"ip":"10.0.0.1","port":8080}`, JSONFetchConfig{},
+			[]ProxyAddr{{IP: "10.0.0.1", Port: "8080"}}, nil},
+		{"嵌套列表和合并地址", `{"data":{"list":[{"addr":"10.0.0.1:8080"},{"addr":"bad"}]}}`, JSONFetchConfig{ListPath: "data.list", AddrPath: "addr"},
+			[]ProxyAddr{{IP: "10.0.0.1", Port: "8080"}}, nil},
+		{"字符串列表", `{"data":["10.0.0.1:8080","[::1]:1080"]}`, JSONFetchConfig{ListPath: "data", Protocol: TypeSocks5},
+			[]ProxyAddr{{IP: "10.0.0.1", Port: "8080", Protocol: TypeSocks5}, {IP: "::1", Port: "1080", Protocol: TypeSocks5}}, nil},
+		{"认证和其他字段", `[{"ip":"10.0.0.1","port":8080,"u":"user","p":"pass","type":"SOCKS5","cc":"CN","ttl":60}]`,
+			JSONFetchConfig{UserPath: "u", PassPath: "p", ProtocolPath: "type", CountryPath: "cc", TTLPath: "ttl"},
+			[]ProxyAddr{{IP: "10.0.0.1", Port: "8080", Username: "user", Password: "pass", Protocol: TypeSocks5, Country: "CN", ExpireSeconds: 60}}, nil},
+		{"成功的错误码", `{"code":0,"msg":"ok","data":[{"ip":"10.0.0.1","port":8080}]}`, envelope,
+			[]ProxyAddr{{IP: "10.0.0.1", Port: "8080"}}, nil},
+		{"成功的空列表", `{"code":"success","data":[]}`, envelope, []ProxyAddr{}, nil},
+		{"错误码识别余额不足", `{"code":10001,"msg":"账户余额不足"}`, envelope, nil, ErrBalanceInsufficient},
+		{"错误码1默认为错误", `{"code":1,"msg":"参数错误","data":[{"ip":"10.0.0.1","port":8080}]}`, envelope, nil, ErrProvider},
+		{"指定1为成功", `{"code":1,"data":[{"ip":"10.0.0.1","port":8080}]}`,
+			JSONFetchConfig{CodePath: "code", ListPath: "data", SuccessCodes: []string{"1"}},
+			[]ProxyAddr{{IP: "10.0.0.1", Port: "8080"}}, nil},
+		{"有错误码但没有代理列表", `{"code":0,"msg":"提取成功"}`, envelope, nil, ErrProvider},
+		{"有错误码但代理列表为null", `{"code":0,"data":null}`, envelope, nil, ErrProvider},
+		{"没有错误码时按错误信息识别", `{"msg":"IP不在白名单内"}`, JSONFetchConfig{MsgPath: "msg", ListPath: "data"}, nil, ErrWhitelist},
+		{"没有错误码也没有代理列表", `{"msg":"暂无代理"}`, JSONFetchConfig{MsgPath: "msg", ListPath: "data"}, []ProxyAddr{}, nil},
+		{"纯文本错误", `提取过于频繁，请稍后再试`, JSONFetchConfig{}, nil, ErrRateLimited},
+		{"代理列表格式错误", `{"data":"10.0.0.1:8080"}`, JSONFetchConfig{ListPath: "data"}, nil, errJSONTest},
+		{"无法解析", `<html>`, JSONFetchConfig{}, nil, errJSONTest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addrs, err := ParseJSONProxies([]byte(tt.body), tt.cfg)
+			switch {
+			case tt.err == errJSONTest:
+				var perr *ProviderError
+				if err == nil || errors.As(err, &perr) {
+					t.Fatalf("err = %v, want 非服务商错误", err)
+				}
+				return
+			case tt.err != nil:
+				var perr *ProviderError
+				if !errors.Is(err, tt.err) || !errors.As(err, &perr) {
+					t.Fatalf("err = %v, want %v", err, tt.err)
+				}
+				return
+			case err != nil:
+				t.Fatalf("err = %v", err)
+			}
+			if addrs == nil || !reflect.DeepEqual(addrs, tt.want) {
+				t.Errorf("addrs = %+v, want %+v", addrs, tt.want)
+			}
+		})
+	}
+}
+
+// errJSONTest 表示期望非服务商错误
+var errJSONTest = errors.New("json error")
+
+func TestJSONPath(t *testing.T) {
+	var root interface{}
+	decoder := json.NewDecoder(strings.NewReader(`{"data":{"list":[{"ip":"10.0.0.1"},{"port":8080}],"ok":true}}`))
+	decoder.UseNumber()
+	if err := decoder.Decode(&root); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+		ok   bool
+	}{
+		{"data.list.0.ip", "10.0.0.1", true},
+		{"data.list.1.port", "8080", true},
+		{"data.ok", "true", true},
+		{"", "", false},
+		{"data.missing", "", false},
+		{"data.list.2.ip", "", false},
+		{"data.list.-1.ip", "", false},
+		{"data.list.x", "", false},
+		{"data.ok.x", "", false},
+		{"data.list", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := jsonString(jsonPath(root, tt.path))
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("jsonPath(%q) = %q, %v, want %q, %v", tt.path, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestClassifyProviderError(t *testing.T) {
+	tests := []struct {
+		msg  string
+		kind error
+	}{
+		{"您的IP不在白名单中", ErrWhitelist},
+		{"IP not allowed", ErrWhitelist},
+		{"账户余额不足", ErrBalanceInsufficient},
+		{"Insufficient Balance", ErrBalanceInsufficient},
+		{"请求过于频繁", ErrRateLimited},
+		{"Too Many Requests", ErrRateLimited},
+		{"签名错误", ErrProviderAuth},
+		{"Unauthorized", ErrProviderAuth},
+		{"系统维护中", ErrProvider},
+	}
+	for _, tt := range tests {
+		perr := ClassifyProviderError("500", tt.msg)
+		if perr.Kind != tt.kind || !errors.Is(perr, tt.kind) || !errors.Is(perr, ErrProvider) {
+			t.Errorf("%s: Kind = %v, want %v", tt.msg, perr.Kind, tt.kind)
+		}
+		if perr.Code != "500" || perr.Msg != tt.msg {
+			t.Errorf("%s: %+v", tt.msg, perr)
+		}
+	}
+
+	// 文本响应只返回能识别的错误
+	if err := DetectProviderError("  "); err != nil {
+		t.Errorf("空文本 = %v", err)
+	}
+	if err := DetectProviderError("10.0.0.1:8080"); err != nil {
+		t.Errorf("代理列表 = %v", err)
+	}
+	if err := DetectProviderError("余额不足"); !errors.Is(err, ErrBalanceInsufficient) {
+		t.Errorf("余额不足 = %v", err)
+	}
+}
+
+func TestJSONFetchFunc(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.Write([]byte(`{"code":0,"data":[{"ip":"10.0.0.1","port":8080}]}`))
+		case "/limited":
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "/forbidden":
+			http.Error(w, "IP不在白名单", http.StatusForbidden)
+		case "/error":
+			w.Write([]byte(`{"code":1,"msg":"失败"}`))
+		}
+	}))
+	defer srv.Close()
+
+	fetch := NewJSONFetchFunc(JSONFetchConfig{CodePath: "code", MsgPath: "msg", ListPath: "data"})
+	tests := []struct {
+		path string
+		err  error
+		code string
+	}{
+		{"/ok", nil, ""},
+		{"/limited", ErrRateLimited, "429"},
+		{"/forbidden", ErrWhitelist, "403"},
+		{"/error", ErrProvider, "1"},
+	}
+	for _, tt := range tests {
+		addrs, err := fetch(srv.URL + tt.path)
+		if tt.err == nil {
+			if err != nil || len(addrs) != 1 {
+				t.Errorf("%s: %v, %v", tt.path, addrs, err)
+			}
+			continue
+		}
+		var perr *ProviderError
+		if !errors.Is(err, tt.err) || !errors.As(err, &perr) || perr.Code != tt.code {
+			t.Errorf("%s: err = %v, want %v [%s]", tt.path, err, tt.err, tt.code)
+		}
+	}
+}
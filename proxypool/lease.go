@@ -0,0 +1,137 @@
+package proxypool
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Drunkard-baifeng/golibs/logger"
+)
+
+// Lease 代理租约，使用完毕后必须调用 Release 归还
+type Lease struct {
+	Proxy *ProxyItem // 租用的代理
+
+	pool      *ProxyPool
+	startTime time.Time
+	timeout   time.Duration
+	timer     *time.Timer
+	once      sync.Once   // 并发名额只释放一次
+	reported  atomic.Bool // 使用结果已报告
+	reclaimed atomic.Bool
+}
+
+// SetMaxConcurrency 设置单个代理最大并发租用数（0 不限制）
+func (p *ProxyPool) SetMaxConcurrency(n int) *ProxyPool {
+	p.poolMu.Lock()
+	p.maxConcurrency = n
+	p.poolMu.Unlock()
+	p.notifyWaiters()
+	return p
+}
+
+// SetLeaseTimeout 设置租约超时时间，超时未归还的租约自动回收（0 不回收）
+func (p *ProxyPool) SetLeaseTimeout(d time.Duration) *ProxyPool {
+	p.poolMu.Lock()
+	defer p.poolMu.Unlock()
+	p.leaseTimeout = d
+	return p
+}
+
+// hasCapacity 代理是否还有并发名额（需持有 poolMu）
+func (p *ProxyPool) hasCapacity(proxy *ProxyItem) bool {
+	return p.maxConcurrency <= 0 || proxy.GetInUse() < p.maxConcurrency
+}
+
+// Acquire 租用一个代理，所有代理都达到并发上限时等待归还，直到 ctx 取消或超时
+func (p *ProxyPool) Acquire(ctx context.Context) (*Lease, error) {
+	return p.AcquireByKey(ctx, "")
+}
+
+// AcquireByKey 按业务键租用代理（key 会传给选择策略）
+func (p *ProxyPool) AcquireByKey(ctx context.Context, key string) (*Lease, error) {
+	proxy, err := p.waitGet(ctx, key, true)
+	if err != nil {
+		return nil, err
+	}
+
+	p.poolMu.RLock()
+	timeout := p.leaseTimeout
+	p.poolMu.RUnlock()
+
+	lease := &Lease{
+		Proxy:     proxy,
+		pool:      p,
+		startTime: time.Now(),
+		timeout:   timeout,
+	}
+	if lease.timeout > 0 {
+		lease.timer = time.AfterFunc(lease.timeout, lease.reclaim)
+	}
+	return lease, nil
+}
+
+// Release 归还租约并报告使用结果（成功不记录耗时，失败按 FailOther 计入失败次数）
+// 租约可能跨越多次请求，租用时长不代表代理耗时，需要记录耗时请使用 ReleaseWithLatency
+// 租约已超时回收时仍报告使用结果，但返回 false；重复调用不再报告，返回 false
+func (l *Lease) Release(success bool) bool {
+	return l.ReleaseWithLatency(success, 0)
+}
+
+// ReleaseWithLatency 归还租约并报告使用结果，成功时记录 latency（0 不记录）
+func (l *Lease) ReleaseWithLatency(success bool, latency time.Duration) bool {
+	if success {
+		return l.release(func() {
+			l.pool.ReportSuccess(l.Proxy, latency)
+		})
+	}
+	return l.Fail(FailOther)
+}
+
+// Fail 以指定原因归还租约（见 ProxyPool.ReportFailure）
+func (l *Lease) Fail(reason FailureReason) bool {
+	return l.release(func() {
+		l.pool.ReportFailure(l.Proxy, reason)
+	})
+}
+
+// Reclaimed 租约是否已超时回收
+func (l *Lease) Reclaimed() bool {
+	return l.reclaimed.Load()
+}
+
+// Duration 租用时长
+func (l *Lease) Duration() time.Duration {
+	return time.Since(l.startTime)
+}
+
+// release 报告使用结果并释放并发名额，返回是否由本次调用释放了名额
+// 超时回收只释放名额，之后归还时仍报告一次结果
+func (l *Lease) release(report func()) bool {
+	if !l.reported.CompareAndSwap(false, true) {
+		return false
+	}
+	if l.timer != nil {
+		l.timer.Stop()
+	}
+	report()
+
+	released := false
+	l.once.Do(func() {
+		released = true
+		atomic.AddInt64(&l.Proxy.inUse, -1)
+	})
+	l.pool.notifyWaiters()
+	return released
+}
+
+// reclaim 超时回收租约
+func (l *Lease) reclaim() {
+	l.once.Do(func() {
+		l.reclaimed.Store(true)
+		atomic.AddInt64(&l.Proxy.inUse, -1)
+		logger.Warnf("代理 %s 租约超过 %v 未归还，已回收", l.Proxy.String(), l.timeout)
+		l.pool.notifyWaiters()
+	})
+}
@@ -0,0 +1,107 @@
+package proxypool
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLeaseReleaseLatency(t *testing.T) {
+	p := New(Config{MaxUseCount: 100, ExpireSeconds: 60})
+	p.AddProxy("10.0.0.1", "8080")
+
+	// Release 不把租用时长当作代理耗时
+	lease, err := p.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if !lease.Release(true) {
+		t.Fatal("Release 返回 false")
+	}
+	if latency := lease.Proxy.GetLatency(); latency != 0 {
+		t.Errorf("Release 记录了耗时 %v", latency)
+	}
+	if lease.Release(true) {
+		t.Error("重复 Release 应返回 false")
+	}
+
+	lease, _ = p.Acquire(context.Background())
+	lease.ReleaseWithLatency(true, 150*time.Millisecond)
+	if latency := lease.Proxy.GetLatency(); latency != 150*time.Millisecond {
+		t.Errorf("ReleaseWithLatency 记录耗时 %v", latency)
+	}
+	if n := lease.Proxy.GetSuccessCount(); n != 2 {
+		t.Errorf("SuccessCount = %d, want 2", n)
+	}
+}
+
+func TestLeaseTimeoutReclaim(t *testing.T) {
+	p := New(Config{MaxUseCount: 100, ExpireSeconds: 60, MaxConcurrency: 1})
+	p.AddProxy("10.0.0.1", "8080")
+
+	p.SetLeaseTimeout(20 * time.Millisecond)
+
+	lease, err := p.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 名额被占用时等待，租约超时回收后可以再次租用
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	second, err := p.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("租约回收后仍无法租用: %v", err)
+	}
+	defer second.Release(true)
+
+	if !lease.Reclaimed() {
+		t.Error("超时租约未标记为回收")
+	}
+	if lease.Release(true) {
+		t.Error("已回收的租约 Release 应返回 false")
+	}
+}
+
+func TestLeaseReportAfterReclaim(t *testing.T) {
+	tests := []struct {
+		name      string
+		release   func(l *Lease) bool
+		successes int
+		failures  int
+		banned    bool
+	}{
+		{"成功", func(l *Lease) bool { return l.Release(true) }, 1, 0, false},
+		{"失败", func(l *Lease) bool { return l.Release(false) }, 0, 1, false},
+		{"按原因失败", func(l *Lease) bool { return l.Fail(FailBlocked) }, 0, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(Config{MaxUseCount: 100, ExpireSeconds: 60, MaxConcurrency: 1, LeaseTimeout: 10 * time.Millisecond})
+			p.AddProxy("10.0.0.1", "8080")
+			lease, err := p.Acquire(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(30 * time.Millisecond)
+			if !lease.Reclaimed() || lease.Proxy.GetInUse() != 0 {
+				t.Fatalf("reclaimed=%v inUse=%d", lease.Reclaimed(), lease.Proxy.GetInUse())
+			}
+
+			// 回收后归还不再释放名额，但仍记录结果，重复归还不重复记录
+			for i := 0; i < 2; i++ {
+				if tt.release(lease) {
+					t.Error("已回收的租约归还应返回 false")
+				}
+			}
+			proxy := lease.Proxy
+			if proxy.GetInUse() != 0 {
+				t.Errorf("inUse = %d, want 0", proxy.GetInUse())
+			}
+			if proxy.GetSuccessCount() != tt.successes || proxy.GetFailureCount() != tt.failures || proxy.IsBanned() != tt.banned {
+				t.Errorf("success=%d failure=%d banned=%v", proxy.GetSuccessCount(), proxy.GetFailureCount(), proxy.IsBanned())
+			}
+		})
+	}
+}
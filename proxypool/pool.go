@@ -2,8 +2,11 @@ package proxypool
 
 import (
 	"errors"
+	"net"
 	"regexp"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 var (
@@ -14,17 +17,38 @@ var (
 
 // ProxyPool 代理池
 type ProxyPool struct {
-	proxies       []*ProxyItem // 代理列表
-	poolMu        sync.RWMutex // 代理池读写锁
-	refreshMu     sync.Mutex   // 刷新锁（防止并发刷新）
-	apiURL        string       // 代理API地址
-	maxUseCount   int          // 默认最大使用次数
-	expireSeconds int          // 默认过期时间（秒）
-	minPoolSize   int          // 最小池大小（低于此值触发刷新）
-	fetchFunc     FetchFunc    // 自定义获取代理函数
-	onProxyGet    OnProxyGetFn // 获取代理回调
-	onRefresh     OnRefreshFn  // 刷新代理回调
-	roundRobinIdx int          // 轮询索引
+	proxies         []*ProxyItem  // 代理列表
+	poolMu          sync.RWMutex  // 代理池读写锁
+	refreshMu       sync.Mutex    // 刷新状态锁
+	refreshing      *refreshCall  // 进行中的刷新（nil 表示空闲）
+	lastRefreshFail time.Time     // 最近一次刷新失败时间
+	lastRefreshErr  error         // 最近一次刷新错误
+	apiURL          string        // 代理API地址
+	maxUseCount     int           // 默认最大使用次数
+	expireSeconds   int           // 默认过期时间（秒）
+	expireMargin    time.Duration // 服务商过期时间的提前量
+	minPoolSize     int           // 最小池大小（可用代理低于此值触发刷新）
+	fetchFunc       FetchFunc     // 自定义获取代理函数
+	onProxyGet      OnProxyGetFn  // 获取代理回调
+	onRefresh       OnRefreshFn   // 刷新代理回调
+	strategy        Strategy      // 选择策略
+	nextSeq         uint64        // 代理加入序号
+	onRebind        OnRebindFn    // 固定代理换绑回调
+
+	waitMu sync.Mutex    // 等待通知锁
+	waitCh chan struct{} // 等待通知通道（有变化时关闭）
+
+	sticky *StickyStrategy // GetSticky 的绑定（换绑时使用当前选择策略）
+
+	maxFailures int             // 连续失败多少次封禁
+	banReasons  []FailureReason // 立即封禁的失败原因
+	banDuration time.Duration   // 封禁时长（0 永久）
+
+	maxConcurrency int           // 单个代理最大并发租用数（0 不限制）
+	leaseTimeout   time.Duration // 租约超时时间（0 不回收）
+
+	healthMu sync.Mutex     // 健康检查锁
+	health   *healthChecker // 后台健康检查（未启用为nil）
 }
 
 // FetchFunc 自定义获取代理函数类型
@@ -38,19 +62,39 @@ type OnRefreshFn func(count int, err error)
 
 // ProxyAddr 代理地址
 type ProxyAddr struct {
-	IP   string
-	Port string
+	IP         string    // IP地址（或主机名）
+	Port       string    // 端口
+	Username   string    // 用户名（无认证为空）
+	Password   string    // 密码
+	Protocol   ProxyType // 协议（空为http）
+	Country    string    // 国家/地区
+	ExpireTime time.Time // 服务商分配的过期时间（零值使用默认过期时间）
+
+	ExpireSeconds int // 有效秒数（ExpireTime 为零时生效，0 使用默认）
+	MaxUseCount   int // 最大使用次数（0 使用默认）
 }
 
 // Config 代理池配置
 type Config struct {
-	APIURL        string       // 代理API地址
-	MaxUseCount   int          // 最大使用次数（默认5）
-	ExpireSeconds int          // 过期时间秒数（默认180）
-	MinPoolSize   int          // 最小池大小（默认3）
-	FetchFunc     FetchFunc    // 自定义获取函数
-	OnProxyGet    OnProxyGetFn // 获取代理回调
-	OnRefresh     OnRefreshFn  // 刷新回调
+	APIURL        string        // 代理API地址
+	MaxUseCount   int           // 最大使用次数（默认5）
+	ExpireSeconds int           // 过期时间秒数（默认180）
+	ExpireMargin  time.Duration // 按服务商过期时间提前多久淘汰（默认0）
+	MinPoolSize   int           // 最小池大小（默认3）
+	FetchFunc     FetchFunc     // 自定义获取函数
+	OnProxyGet    OnProxyGetFn  // 获取代理回调
+	OnRefresh     OnRefreshFn   // 刷新回调
+	Strategy      Strategy      // 选择策略（默认轮询）
+	OnRebind      OnRebindFn    // 固定代理换绑回调
+
+	MaxFailures int             // 连续失败多少次封禁（0 使用默认值3，-1 不按次数封禁）
+	BanReasons  []FailureReason // 立即封禁的失败原因（默认 FailBlocked、FailAuth）
+	BanDuration time.Duration   // 封禁时长（默认0，永久封禁）
+
+	MaxConcurrency int           // 单个代理最大并发租用数（默认0，不限制）
+	LeaseTimeout   time.Duration // 租约超时自动回收（默认5分钟，-1 不回收）
+
+	HealthCheck *HealthCheckConfig // 后台健康检查（nil 不启用，配置错误时记录日志）
 }
 
 // New 创建代理池
@@ -64,17 +108,46 @@ func New(cfg Config) *ProxyPool {
 	if cfg.MinPoolSize <= 0 {
 		cfg.MinPoolSize = 3
 	}
+	if cfg.Strategy == nil {
+		cfg.Strategy = NewRoundRobinStrategy()
+	}
+	if cfg.MaxFailures == 0 {
+		cfg.MaxFailures = 3
+	}
+	if cfg.LeaseTimeout == 0 {
+		cfg.LeaseTimeout = 5 * time.Minute
+	} else if cfg.LeaseTimeout < 0 {
+		cfg.LeaseTimeout = 0
+	}
+	if cfg.BanReasons == nil {
+		cfg.BanReasons = []FailureReason{FailBlocked, FailAuth}
+	}
 
-	return &ProxyPool{
-		proxies:       make([]*ProxyItem, 0),
-		apiURL:        cfg.APIURL,
-		maxUseCount:   cfg.MaxUseCount,
-		expireSeconds: cfg.ExpireSeconds,
-		minPoolSize:   cfg.MinPoolSize,
-		fetchFunc:     cfg.FetchFunc,
-		onProxyGet:    cfg.OnProxyGet,
-		onRefresh:     cfg.OnRefresh,
+	p := &ProxyPool{
+		proxies:        make([]*ProxyItem, 0),
+		apiURL:         cfg.APIURL,
+		maxUseCount:    cfg.MaxUseCount,
+		expireSeconds:  cfg.ExpireSeconds,
+		expireMargin:   cfg.ExpireMargin,
+		minPoolSize:    cfg.MinPoolSize,
+		fetchFunc:      cfg.FetchFunc,
+		onProxyGet:     cfg.OnProxyGet,
+		onRefresh:      cfg.OnRefresh,
+		strategy:       cfg.Strategy,
+		onRebind:       cfg.OnRebind,
+		maxFailures:    cfg.MaxFailures,
+		banReasons:     cfg.BanReasons,
+		banDuration:    cfg.BanDuration,
+		maxConcurrency: cfg.MaxConcurrency,
+		leaseTimeout:   cfg.LeaseTimeout,
+	}
+	p.sticky = NewStickyStrategy(StrategyFunc(func(candidates []*ProxyItem, key string) *ProxyItem {
+		return p.strategy.Select(candidates, key)
+	}))
+	if cfg.HealthCheck != nil {
+		p.SetHealthCheck(*cfg.HealthCheck)
 	}
+	return p
 }
 
 // 默认的IP:Port正则
@@ -105,7 +178,13 @@ func (p *ProxyPool) SetExpireSeconds(seconds int) *ProxyPool {
 	return p
 }
 
-// SetMinPoolSize 设置最小池大小
+// SetExpireMargin 设置服务商过期时间的提前量，代理在服务商截止时间前提前淘汰
+func (p *ProxyPool) SetExpireMargin(margin time.Duration) *ProxyPool {
+	p.expireMargin = margin
+	return p
+}
+
+// SetMinPoolSize 设置最小池大小（可用代理低于此值时后台刷新）
 func (p *ProxyPool) SetMinPoolSize(size int) *ProxyPool {
 	p.minPoolSize = size
 	return p
@@ -129,18 +208,70 @@ func (p *ProxyPool) SetOnRefresh(fn OnRefreshFn) *ProxyPool {
 	return p
 }
 
+// SetStrategy 设置选择策略
+func (p *ProxyPool) SetStrategy(strategy Strategy) *ProxyPool {
+	p.poolMu.Lock()
+	defer p.poolMu.Unlock()
+	if strategy == nil {
+		strategy = NewRoundRobinStrategy()
+	}
+	p.strategy = strategy
+	return p
+}
+
+// refreshCall 一次进行中的刷新
+type refreshCall struct {
+	done chan struct{}
+	err  error
+}
+
 // Refresh 刷新代理池
+// 已有刷新在进行时等待其完成并返回它的结果，不会重复请求API
 func (p *ProxyPool) Refresh() error {
 	if p.apiURL == "" && p.fetchFunc == nil {
 		return ErrAPIURLEmpty
 	}
 
-	// 尝试获取刷新锁，避免并发刷新
-	if !p.refreshMu.TryLock() {
-		return nil // 已有刷新在进行
+	p.refreshMu.Lock()
+	if call := p.refreshing; call != nil {
+		p.refreshMu.Unlock()
+		<-call.done
+		return call.err
+	}
+	call := &refreshCall{done: make(chan struct{})}
+	p.refreshing = call
+	p.refreshMu.Unlock()
+
+	call.err = p.doRefresh()
+
+	p.refreshMu.Lock()
+	p.refreshing = nil
+	p.lastRefreshErr = call.err
+	if call.err != nil {
+		p.lastRefreshFail = time.Now()
+	}
+	p.refreshMu.Unlock()
+
+	close(call.done)
+	p.notifyWaiters()
+	return call.err
+}
+
+// refreshAsync 后台刷新（已在刷新或刚刷新失败时跳过，避免频繁请求API）
+func (p *ProxyPool) refreshAsync() {
+	if p.apiURL == "" && p.fetchFunc == nil {
+		return
+	}
+	p.refreshMu.Lock()
+	busy := p.refreshing != nil || time.Since(p.lastRefreshFail) < refreshRetryDelay
+	p.refreshMu.Unlock()
+	if !busy {
+		go p.Refresh()
 	}
-	defer p.refreshMu.Unlock()
+}
 
+// doRefresh 调用 FetchFunc 获取并添加代理
+func (p *ProxyPool) doRefresh() error {
 	var proxies []ProxyAddr
 	var err error
 
@@ -160,7 +291,7 @@ func (p *ProxyPool) Refresh() error {
 	// 添加新代理
 	count := 0
 	for _, addr := range proxies {
-		if p.AddProxy(addr.IP, addr.Port) {
+		if p.AddProxyAddr(addr) {
 			count++
 		}
 	}
@@ -174,50 +305,106 @@ func (p *ProxyPool) Refresh() error {
 
 // AddProxy 添加代理
 func (p *ProxyPool) AddProxy(ip, port string) bool {
+	return p.AddProxyAddr(ProxyAddr{IP: ip, Port: port})
+}
+
+// AddProxyAddr 添加代理（支持账密、协议、国家、过期时间、使用次数）
+// 协议、用户名、ip:port 都相同时视为重复；扣除提前量后已过期的代理不添加
+func (p *ProxyPool) AddProxyAddr(addr ProxyAddr) bool {
+	return p.addAddr(addr, true)
+}
+
+// addAddr 添加代理，applyMargin 为 true 时服务商指定的有效期扣除提前量
+func (p *ProxyPool) addAddr(addr ProxyAddr, applyMargin bool) bool {
 	p.poolMu.Lock()
 	defer p.poolMu.Unlock()
 
+	now := time.Now()
+	if addr.ExpireTime.IsZero() && addr.ExpireSeconds > 0 {
+		addr.ExpireTime = now.Add(time.Duration(addr.ExpireSeconds) * time.Second)
+	}
+	if !addr.ExpireTime.IsZero() {
+		if applyMargin {
+			addr.ExpireTime = addr.ExpireTime.Add(-p.expireMargin)
+		}
+		if !addr.ExpireTime.After(now) {
+			return false
+		}
+	}
+
+	proxy := NewProxyItemFromAddr(addr, p.maxUseCount, p.expireSeconds)
+
 	// 检查是否已存在
-	key := ip + ":" + port
-	for _, proxy := range p.proxies {
-		if proxy.String() == key {
+	key := proxy.Key()
+	for _, existing := range p.proxies {
+		if existing.Key() == key {
 			return false
 		}
 	}
 
-	proxy := NewProxyItemWithConfig(ip, port, p.maxUseCount, p.expireSeconds)
+	p.nextSeq++
+	proxy.seq = p.nextSeq
 	p.proxies = append(p.proxies, proxy)
+	p.notifyWaiters()
 	return true
 }
 
-// AddProxyItem 添加代理项
+// AddProxyItem 添加代理项（复制地址信息、过期时间和最大使用次数，使用次数从0开始）
+// 代理项的过期时间已是实际淘汰时间，不再扣除提前量；已过期时不添加
 func (p *ProxyPool) AddProxyItem(proxy *ProxyItem) bool {
-	return p.AddProxy(proxy.IP, proxy.Port)
+	return p.addAddr(ProxyAddr{
+		IP:          proxy.IP,
+		Port:        proxy.Port,
+		Username:    proxy.Username,
+		Password:    proxy.Password,
+		Protocol:    proxy.Protocol,
+		Country:     proxy.Country,
+		ExpireTime:  proxy.GetExpireTime(),
+		MaxUseCount: int(proxy.maxUseCount),
+	}, false)
 }
 
 // Get 获取一个可用代理
 func (p *ProxyPool) Get() (*ProxyItem, error) {
+	return p.GetByKey("")
+}
+
+// GetByKey 按业务键获取代理，key 会传给选择策略（如 StickyStrategy 按 key 固定代理）
+func (p *ProxyPool) GetByKey(key string) (*ProxyItem, error) {
+	return p.get(key, true, false)
+}
+
+// get 获取代理，syncRefresh 为 false 时池子为空也只在后台刷新
+// acquire 为 true 时在持有锁的情况下占用一个并发名额
+func (p *ProxyPool) get(key string, syncRefresh, acquire bool) (*ProxyItem, error) {
+	return p.getWith(nil, key, syncRefresh, acquire)
+}
+
+// getWith 使用指定的选择策略获取代理（nil 使用代理池的策略），选择在持有锁的情况下进行
+func (p *ProxyPool) getWith(strategy Strategy, key string, syncRefresh, acquire bool) (*ProxyItem, error) {
 	p.poolMu.Lock()
 
 	// 清理无效代理
 	p.cleanupUnsafe()
 
-	// 如果池子为空，同步刷新一次
-	if len(p.proxies) == 0 {
+	// 按可用数量判断是否刷新：隔离中、封禁中的代理仍在池中，但不能使用
+	usable := p.availableCountUnsafe()
+	if usable == 0 && syncRefresh {
+		// 没有可用代理，同步刷新一次（已有刷新在进行时等待它完成）
 		p.poolMu.Unlock()
-		p.Refresh() // 同步刷新
+		p.Refresh()
 		p.poolMu.Lock()
-	} else if len(p.proxies) < p.minPoolSize {
-		// 池子不为空但低于最小值，异步刷新
-		go p.Refresh()
+	} else if usable < p.minPoolSize {
+		// 可用代理低于最小值，异步刷新
+		p.refreshAsync()
 	}
 
 	defer p.poolMu.Unlock()
 
-	// 获取可用代理
+	// 获取可用且未达到并发上限的代理
 	available := make([]*ProxyItem, 0)
 	for _, proxy := range p.proxies {
-		if proxy.IsAvailable() {
+		if proxy.IsAvailable() && p.hasCapacity(proxy) {
 			available = append(available, proxy)
 		}
 	}
@@ -226,12 +413,18 @@ func (p *ProxyPool) Get() (*ProxyItem, error) {
 		return nil, ErrNoAvailableProxy
 	}
 
-	// 轮询选择（均匀分配）
-	idx := p.roundRobinIdx % len(available)
-	p.roundRobinIdx++
-	proxy := available[idx]
+	if strategy == nil {
+		strategy = p.strategy
+	}
+	proxy := strategy.Select(available, key)
+	if proxy == nil {
+		return nil, ErrNoAvailableProxy
+	}
 
 	if proxy.IncrementUseCount() {
+		if acquire {
+			atomic.AddInt64(&proxy.inUse, 1)
+		}
 		if p.onProxyGet != nil {
 			p.onProxyGet(proxy)
 		}
@@ -250,7 +443,7 @@ func (p *ProxyPool) GetString() (string, error) {
 	return proxy.String(), nil
 }
 
-// GetURL 获取代理URL http://ip:port
+// GetURL 获取代理URL（按代理协议，含账密）
 func (p *ProxyPool) GetURL() (string, error) {
 	proxy, err := p.Get()
 	if err != nil {
@@ -259,11 +452,12 @@ func (p *ProxyPool) GetURL() (string, error) {
 	return proxy.URL(), nil
 }
 
-// cleanupUnsafe 清理无效代理（非线程安全，需要在持有锁时调用）
+// cleanupUnsafe 清理过期或用尽的代理（非线程安全，需要在持有锁时调用）
+// 隔离中的代理保留，等待健康检查恢复
 func (p *ProxyPool) cleanupUnsafe() {
 	valid := make([]*ProxyItem, 0, len(p.proxies))
 	for _, proxy := range p.proxies {
-		if proxy.IsAvailable() {
+		if !proxy.isRetired() {
 			valid = append(valid, proxy)
 		}
 	}
@@ -280,11 +474,12 @@ func (p *ProxyPool) Cleanup() int {
 	return before - len(p.proxies)
 }
 
-// Clear 清空代理池
+// Clear 清空代理池（同时解除所有固定代理绑定）
 func (p *ProxyPool) Clear() {
 	p.poolMu.Lock()
-	defer p.poolMu.Unlock()
 	p.proxies = make([]*ProxyItem, 0)
+	p.poolMu.Unlock()
+	p.ReleaseAllSticky()
 }
 
 // Remove 移除指定代理
@@ -292,7 +487,7 @@ func (p *ProxyPool) Remove(ip, port string) bool {
 	p.poolMu.Lock()
 	defer p.poolMu.Unlock()
 
-	key := ip + ":" + port
+	key := net.JoinHostPort(ip, port)
 	for i, proxy := range p.proxies {
 		if proxy.String() == key {
 			p.proxies = append(p.proxies[:i], p.proxies[i+1:]...)
@@ -302,13 +497,27 @@ func (p *ProxyPool) Remove(ip, port string) bool {
 	return false
 }
 
-// RemoveByString 通过字符串移除代理
+// RemoveByString 通过字符串移除代理（ip:port、[ipv6]:port 或 Key()，IPv6 也兼容不带方括号的写法）
 func (p *ProxyPool) RemoveByString(proxyStr string) bool {
 	p.poolMu.Lock()
 	defer p.poolMu.Unlock()
 
 	for i, proxy := range p.proxies {
-		if proxy.String() == proxyStr {
+		if proxy.matches(proxyStr) {
+			p.proxies = append(p.proxies[:i], p.proxies[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveItem 移除指定代理项
+func (p *ProxyPool) RemoveItem(item *ProxyItem) bool {
+	p.poolMu.Lock()
+	defer p.poolMu.Unlock()
+
+	for i, proxy := range p.proxies {
+		if proxy == item {
 			p.proxies = append(p.proxies[:i], p.proxies[i+1:]...)
 			return true
 		}
@@ -316,6 +525,19 @@ func (p *ProxyPool) RemoveByString(proxyStr string) bool {
 	return false
 }
 
+// Contains 代理项是否仍在池中
+func (p *ProxyPool) Contains(item *ProxyItem) bool {
+	p.poolMu.RLock()
+	defer p.poolMu.RUnlock()
+
+	for _, proxy := range p.proxies {
+		if proxy == item {
+			return true
+		}
+	}
+	return false
+}
+
 // Size 获取代理池大小
 func (p *ProxyPool) Size() int {
 	p.poolMu.RLock()
@@ -327,7 +549,11 @@ func (p *ProxyPool) Size() int {
 func (p *ProxyPool) AvailableCount() int {
 	p.poolMu.RLock()
 	defer p.poolMu.RUnlock()
+	return p.availableCountUnsafe()
+}
 
+// availableCountUnsafe 可用代理数量（需要在持有锁时调用）
+func (p *ProxyPool) availableCountUnsafe() int {
 	count := 0
 	for _, proxy := range p.proxies {
 		if proxy.IsAvailable() {
@@ -339,10 +565,19 @@ func (p *ProxyPool) AvailableCount() int {
 
 // Stats 代理池统计信息
 type Stats struct {
-	Total     int `json:"total"`     // 总数
-	Available int `json:"available"` // 可用数
-	Expired   int `json:"expired"`   // 已过期
-	MaxUsed   int `json:"max_used"`  // 达到最大使用次数
+	Total       int `json:"total"`       // 总数
+	Available   int `json:"available"`   // 可用数
+	Expired     int `json:"expired"`     // 已过期
+	MaxUsed     int `json:"max_used"`    // 达到最大使用次数
+	Quarantined int `json:"quarantined"` // 隔离中
+	Banned      int `json:"banned"`      // 封禁中（永久或临时）
+	InUse       int `json:"in_use"`      // 正在被租用的代理数
+	Leases      int `json:"leases"`      // 未归还的租约数
+
+	Successes   int           `json:"successes"`    // 使用成功次数
+	Failures    int           `json:"failures"`     // 使用失败次数
+	SuccessRate float64       `json:"success_rate"` // 成功率（没有反馈时为1）
+	AvgLatency  time.Duration `json:"avg_latency"`  // 平均耗时（已测量代理的均值）
 }
 
 // GetStats 获取统计信息
@@ -351,6 +586,8 @@ func (p *ProxyPool) GetStats() Stats {
 	defer p.poolMu.RUnlock()
 
 	stats := Stats{Total: len(p.proxies)}
+	var totalLatency time.Duration
+	measured := 0
 	for _, proxy := range p.proxies {
 		if proxy.IsAvailable() {
 			stats.Available++
@@ -361,6 +598,30 @@ func (p *ProxyPool) GetStats() Stats {
 		if proxy.IsMaxUsed() {
 			stats.MaxUsed++
 		}
+		if proxy.IsQuarantined() {
+			stats.Quarantined++
+		}
+		if proxy.IsBanned() {
+			stats.Banned++
+		}
+		if inUse := proxy.GetInUse(); inUse > 0 {
+			stats.InUse++
+			stats.Leases += inUse
+		}
+		stats.Successes += proxy.GetSuccessCount()
+		stats.Failures += proxy.GetFailureCount()
+		if latency := proxy.GetLatency(); latency > 0 {
+			totalLatency += latency
+			measured++
+		}
+	}
+
+	stats.SuccessRate = 1
+	if total := stats.Successes + stats.Failures; total > 0 {
+		stats.SuccessRate = float64(stats.Successes) / float64(total)
+	}
+	if measured > 0 {
+		stats.AvgLatency = totalLatency / time.Duration(measured)
 	}
 	return stats
 }
@@ -0,0 +1,154 @@
+package providers_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/Drunkard-baifeng/golibs/proxypool"
+	"github.com/Drunkard-baifeng/golibs/proxypool/providers"
+)
+
+// newStandIn 模拟服务商提取接口
+func newStandIn(routes map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/limited" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(routes[r.URL.Path]))
+	}))
+}
+
+func Example_textLines() {
+	srv := newStandIn(map[string]string{
+		"/get":       "1.2.3.4:8080\r\n5.6.7.8:1080:user:p:ss\r\nu2:pw@proxy.example.com:3128\r\n9.9.9.9 80",
+		"/balance":   "您的账户余额不足，请充值",
+		"/whitelist": `{"code":113,"msg":"请将 10.0.0.1 添加到白名单"}`,
+	})
+	defer srv.Close()
+
+	provider := providers.NewTextProvider(providers.Options{})
+	proxies, err := provider.Fetch(srv.URL + "/get")
+	fmt.Println(len(proxies), err)
+	for _, p := range proxies {
+		fmt.Printf("%s %s [%s:%s]\n", p.IP, p.Port, p.Username, p.Password)
+	}
+
+	_, err = provider.Fetch(srv.URL + "/balance")
+	fmt.Println(errors.Is(err, proxypool.ErrBalanceInsufficient))
+
+	_, err = provider.Fetch(srv.URL + "/whitelist")
+	fmt.Println(errors.Is(err, proxypool.ErrWhitelist))
+
+	// Output:
+	// 4 <nil>
+	// 1.2.3.4 8080 [:]
+	// 5.6.7.8 1080 [user:p:ss]
+	// proxy.example.com 3128 [u2:pw]
+	// 9.9.9.9 80 [:]
+	// true
+	// true
+}
+
+func Example_jsonList() {
+	srv := newStandIn(map[string]string{
+		"/get":   `[{"host":"1.2.3.4","port":8080,"country":"CN"},{"host":"2001:db8::1","port":"1080"}]`,
+		"/empty": `{"msg":"提取过于频繁"}`,
+	})
+	defer srv.Close()
+
+	provider := providers.NewJSONListProvider(proxypool.JSONFetchConfig{
+		IPPath:      "host",
+		CountryPath: "country",
+	}, providers.Options{Protocol: proxypool.TypeSocks5})
+
+	proxies, err := provider.Fetch(srv.URL + "/get")
+	fmt.Println(len(proxies), err)
+	for _, p := range proxies {
+		fmt.Printf("%s %s %s [%s]\n", p.IP, p.Port, p.Protocol, p.Country)
+	}
+
+	_, err = provider.Fetch(srv.URL + "/empty")
+	fmt.Println(errors.Is(err, proxypool.ErrRateLimited))
+
+	// Output:
+	// 2 <nil>
+	// 1.2.3.4 8080 socks5 [CN]
+	// 2001:db8::1 1080 socks5 []
+	// true
+}
+
+func Example_envelope() {
+	srv := newStandIn(map[string]string{
+		"/get":       `{"code":0,"msg":"success","data":[{"ip":"1.2.3.4","port":8080,"account":"u","password":"p","expire_time":"2030-01-01 00:00:00"}]}`,
+		"/balance":   `{"code":"121","msg":"套餐已用完"}`,
+		"/whitelist": `{"code":115,"msg":"IP not in whitelist"}`,
+		"/other":     `{"code":500,"msg":"系统维护中"}`,
+	})
+	defer srv.Close()
+
+	provider := providers.NewEnvelopeProvider(proxypool.JSONFetchConfig{
+		UserPath:   "account",
+		PassPath:   "password",
+		ExpirePath: "expire_time",
+	}, providers.Options{})
+
+	proxies, err := provider.Fetch(srv.URL + "/get")
+	fmt.Println(len(proxies), err)
+	fmt.Println(proxies[0].IP, proxies[0].Port, proxies[0].Username, proxies[0].ExpireTime.Year())
+
+	_, err = provider.Fetch(srv.URL + "/balance")
+	fmt.Println(errors.Is(err, proxypool.ErrBalanceInsufficient))
+
+	_, err = provider.Fetch(srv.URL + "/whitelist")
+	fmt.Println(errors.Is(err, proxypool.ErrWhitelist))
+
+	_, err = provider.Fetch(srv.URL + "/other")
+	fmt.Println(errors.Is(err, proxypool.ErrProvider), err)
+
+	// Output:
+	// 1 <nil>
+	// 1.2.3.4 8080 u 2030
+	// true
+	// true
+	// true 代理服务商返回错误: [500] 系统维护中
+}
+
+func Example_backoff() {
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	provider := providers.NewTextProvider(providers.Options{})
+
+	// 第一次被限流后进入退避，退避期间不再请求接口
+	_, err := provider.Fetch(srv.URL)
+	fmt.Println(errors.Is(err, proxypool.ErrRateLimited), hits)
+	_, err = provider.Fetch(srv.URL)
+	fmt.Println(errors.Is(err, proxypool.ErrRateLimited), hits)
+
+	provider.Reset()
+	provider.Fetch(srv.URL)
+	fmt.Println(hits)
+
+	// Output:
+	// true 1
+	// true 1
+	// 2
+}
+
+func Example_withPool() {
+	pool := proxypool.New(proxypool.Config{
+		APIURL:    "http://your-proxy-api.com/get?num=10&format=txt",
+		FetchFunc: providers.NewTextProvider(providers.Options{}).Fetch,
+	})
+
+	if err := pool.Refresh(); errors.Is(err, proxypool.ErrWhitelist) {
+		fmt.Println("请把本机IP加入白名单")
+	}
+}
@@ -0,0 +1,110 @@
+package providers
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/Drunkard-baifeng/golibs/proxypool"
+)
+
+// NewJSONListProvider JSON 列表格式的适配器
+// 例如 [{"ip":"1.2.3.4","port":8080}]，字段路径见 proxypool.JSONFetchConfig
+// 列表不在根节点时设置 cfg.ListPath；HTTP 客户端使用 opts.Client（忽略 cfg.Client）
+func NewJSONListProvider(cfg proxypool.JSONFetchConfig, opts Options) *Provider {
+	return newProvider(opts, jsonParser(cfg))
+}
+
+// NewEnvelopeProvider 带 code/msg 信封的 JSON 格式适配器
+// 默认 {"code":0,"msg":"ok","data":[...]}，未设置的路径使用 code、msg、data
+func NewEnvelopeProvider(cfg proxypool.JSONFetchConfig, opts Options) *Provider {
+	if cfg.CodePath == "" {
+		cfg.CodePath = "code"
+	}
+	if cfg.MsgPath == "" {
+		cfg.MsgPath = "msg"
+	}
+	if cfg.ListPath == "" {
+		cfg.ListPath = "data"
+	}
+	return newProvider(opts, jsonParser(cfg))
+}
+
+// jsonParser 使用 proxypool.ParseJSONProxies 解析
+func jsonParser(cfg proxypool.JSONFetchConfig) parseFunc {
+	return func(body []byte) ([]proxypool.ProxyAddr, error) {
+		proxies, err := proxypool.ParseJSONProxies(body, cfg)
+		if err != nil {
+			return nil, err
+		}
+		// 列表为空时检查是否为没有错误码的错误响应
+		if len(proxies) == 0 {
+			if err := parseEnvelopeError(body, cfg.SuccessCodes); err != nil {
+				return nil, err
+			}
+		}
+		return proxies, nil
+	}
+}
+
+// 常见的错误码、错误信息字段名
+var (
+	codeFields = []string{"code", "status", "errcode", "error_code", "ret"}
+	msgFields  = []string{"msg", "message", "info", "errmsg", "error_msg", "error"}
+)
+
+// parseEnvelopeError 从 {"code":..,"msg":..} 形式的响应中识别错误，不是错误时返回 nil
+// 错误码不在 successCodes 中时为错误（为空使用 proxypool.DefaultSuccessCodes）
+func parseEnvelopeError(body []byte, successCodes []string) error {
+	var obj map[string]interface{}
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return proxypool.DetectProviderError(string(body))
+	}
+
+	code := firstField(obj, codeFields)
+	msg := firstField(obj, msgFields)
+	if code == "" && msg == "" {
+		return nil
+	}
+
+	perr := proxypool.ClassifyProviderError(code, msg)
+	if perr.Kind != proxypool.ErrProvider {
+		return perr
+	}
+	// 无法识别的错误信息，按错误码判断
+	if len(successCodes) == 0 {
+		successCodes = proxypool.DefaultSuccessCodes
+	}
+	if code != "" && !containsFold(successCodes, code) {
+		return perr
+	}
+	return nil
+}
+
+// firstField 取第一个存在的字段（转为字符串）
+func firstField(obj map[string]interface{}, names []string) string {
+	for _, name := range names {
+		switch v := obj[name].(type) {
+		case string:
+			return v
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			if v {
+				return "true"
+			}
+			return "false"
+		}
+	}
+	return ""
+}
+
+// containsFold 忽略大小写判断是否包含
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+// Package providers 常见代理服务商提取接口的适配器
+//
+// 支持三种常见的响应格式：
+//   - 文本行：每行一个 ip:port、ip:port:user:pass 或 user:pass@ip:port
+//   - JSON 列表：[{"ip":"1.2.3.4","port":8080}, ...]
+//   - JSON 信封：{"code":0,"msg":"ok","data":[...]}
+//
+// 余额不足、白名单等错误转换为 proxypool 的错误类型，提取过于频繁时自动退避
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Drunkard-baifeng/golibs/proxypool"
+)
+
+// Options 适配器通用配置
+type Options struct {
+	Client      *http.Client        // HTTP客户端（默认10秒超时）
+	Protocol    proxypool.ProxyType // 代理协议（默认http）
+	MinInterval time.Duration       // 两次提取的最小间隔（默认0）
+	Backoff     time.Duration       // 提取过于频繁后的初始退避时间（默认2秒，每次翻倍）
+	MaxBackoff  time.Duration       // 最大退避时间（默认1分钟）
+}
+
+// withDefaults 填充默认值
+func (o Options) withDefaults() Options {
+	if o.Client == nil {
+		o.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if o.Backoff <= 0 {
+		o.Backoff = 2 * time.Second
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = time.Minute
+	}
+	return o
+}
+
+// parseFunc 解析响应体
+type parseFunc func(body []byte) ([]proxypool.ProxyAddr, error)
+
+// Provider 服务商适配器
+type Provider struct {
+	opts  Options
+	parse parseFunc
+
+	mu          sync.Mutex
+	nextAllowed time.Time     // 下次允许请求的时间
+	backoff     time.Duration // 当前退避时间
+}
+
+// newProvider 创建适配器
+func newProvider(opts Options, parse parseFunc) *Provider {
+	opts = opts.withDefaults()
+	return &Provider{opts: opts, parse: parse, backoff: opts.Backoff}
+}
+
+// Fetch 请求提取接口并解析代理（可直接作为 proxypool.FetchFunc 使用）
+// 退避期间不请求接口，直接返回 proxypool.ErrRateLimited
+func (p *Provider) Fetch(apiURL string) ([]proxypool.ProxyAddr, error) {
+	if wait := p.waitTime(); wait > 0 {
+		return nil, &proxypool.ProviderError{
+			Kind: proxypool.ErrRateLimited,
+			Msg:  fmt.Sprintf("退避中，%v 后重试", wait.Round(time.Millisecond)),
+		}
+	}
+
+	proxies, err := p.fetch(apiURL)
+	p.record(err)
+	return proxies, err
+}
+
+// FetchFunc 返回 proxypool.FetchFunc
+func (p *Provider) FetchFunc() proxypool.FetchFunc {
+	return p.Fetch
+}
+
+// fetch 请求并解析
+func (p *Provider) fetch(apiURL string) ([]proxypool.ProxyAddr, error) {
+	resp, err := p.opts.Client.Get(apiURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, &proxypool.ProviderError{Kind: proxypool.ErrRateLimited, Code: "429", Msg: string(body)}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if err := parseEnvelopeError(body, nil); err != nil {
+			return nil, err
+		}
+		return nil, proxypool.ClassifyProviderError(strconv.Itoa(resp.StatusCode), string(body))
+	}
+
+	proxies, err := p.parse(body)
+	if err != nil {
+		return nil, err
+	}
+	if p.opts.Protocol != "" {
+		for i := range proxies {
+			if proxies[i].Protocol == "" {
+				proxies[i].Protocol = p.opts.Protocol
+			}
+		}
+	}
+	return proxies, nil
+}
+
+// waitTime 距离下次允许请求的时间
+func (p *Provider) waitTime() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return time.Until(p.nextAllowed)
+}
+
+// record 根据结果更新下次允许请求的时间
+func (p *Provider) record(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if errors.Is(err, proxypool.ErrRateLimited) {
+		p.nextAllowed = time.Now().Add(p.backoff)
+		p.backoff *= 2
+		if p.backoff > p.opts.MaxBackoff {
+			p.backoff = p.opts.MaxBackoff
+		}
+		return
+	}
+
+	p.backoff = p.opts.Backoff
+	p.nextAllowed = time.Now().Add(p.opts.MinInterval)
+}
+
+// Reset 清除退避状态
+func (p *Provider) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.backoff = p.opts.Backoff
+	p.nextAllowed = time.Time{}
+}
@@ -0,0 +1,145 @@
+package providers_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Drunkard-baifeng/golibs/proxypool"
+	"github.com/Drunkard-baifeng/golibs/proxypool/providers"
+)
+
+func TestParseTextLinesPasswordChars(t *testing.T) {
+	body := "1.2.3.4:8080:user:p,a;s@s\n5.6.7.8:1080<br/>u2:p@w,1@proxy.example.com:3128\r\n9.9.9.9 80"
+	proxies, err := providers.ParseTextLines([]byte(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []proxypool.ProxyAddr{
+		{IP: "1.2.3.4", Port: "8080", Username: "user", Password: "p,a;s@s"},
+		{IP: "5.6.7.8", Port: "1080"},
+		{IP: "proxy.example.com", Port: "3128", Username: "u2", Password: "p@w,1"},
+		{IP: "9.9.9.9", Port: "80"},
+	}
+	if len(proxies) != len(want) {
+		t.Fatalf("proxies = %+v", proxies)
+	}
+	for i, p := range proxies {
+		if p.IP != want[i].IP || p.Port != want[i].Port || p.Username != want[i].Username || p.Password != want[i].Password {
+			t.Errorf("proxies[%d] = %+v, want %+v", i, p, want[i])
+		}
+	}
+}
+
+func TestEnvelopeSuccessCodes(t *testing.T) {
+	tests := []struct {
+		code         string
+		successCodes []string
+		ok           bool
+	}{
+		{`0`, nil, true},
+		{`200`, nil, true},
+		{`"success"`, nil, true},
+		{`"OK"`, nil, true},
+		// 1、true 默认不表示成功，需要显式指定
+		{`1`, nil, false},
+		{`true`, nil, false},
+		{`1`, []string{"1"}, true},
+		{`true`, []string{"true"}, true},
+	}
+	for _, tt := range tests {
+		srv := newStandIn(map[string]string{
+			"/get": `{"code":` + tt.code + `,"msg":"提取成功","data":[{"ip":"1.2.3.4","port":8080}]}`,
+		})
+		provider := providers.NewEnvelopeProvider(proxypool.JSONFetchConfig{SuccessCodes: tt.successCodes}, providers.Options{})
+		proxies, err := provider.Fetch(srv.URL + "/get")
+		srv.Close()
+		if tt.ok && (err != nil || len(proxies) != 1) {
+			t.Errorf("code %s: proxies = %v, err = %v", tt.code, proxies, err)
+		}
+		if !tt.ok && !errors.Is(err, proxypool.ErrProvider) {
+			t.Errorf("code %s: err = %v, want ErrProvider", tt.code, err)
+		}
+	}
+}
+
+func TestEnvelopeErrorWithoutData(t *testing.T) {
+	srv := newStandIn(map[string]string{
+		"/code1":     `{"code":1,"msg":"参数错误"}`,
+		"/code1only": `{"code":1}`,
+		"/balance":   `{"code":0,"msg":"余额不足"}`,
+		"/nodata":    `{"code":0,"msg":"提取成功"}`,
+		"/empty":     `{"code":0,"msg":"提取成功","data":[]}`,
+	})
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		provider *providers.Provider
+		path     string
+		err      error // nil 表示应成功
+	}{
+		{"信封格式错误码1", providers.NewEnvelopeProvider(proxypool.JSONFetchConfig{}, providers.Options{}), "/code1", proxypool.ErrProvider},
+		{"信封格式没有代理列表", providers.NewEnvelopeProvider(proxypool.JSONFetchConfig{}, providers.Options{}), "/nodata", proxypool.ErrProvider},
+		{"信封格式空列表", providers.NewEnvelopeProvider(proxypool.JSONFetchConfig{}, providers.Options{}), "/empty", nil},
+		{"JSON列表格式错误码1", providers.NewJSONListProvider(proxypool.JSONFetchConfig{ListPath: "data"}, providers.Options{}), "/code1", proxypool.ErrProvider},
+		{"JSON列表格式只有错误码", providers.NewJSONListProvider(proxypool.JSONFetchConfig{ListPath: "data"}, providers.Options{}), "/code1only", proxypool.ErrProvider},
+		{"JSON列表格式指定1为成功", providers.NewJSONListProvider(proxypool.JSONFetchConfig{ListPath: "data", SuccessCodes: []string{"1"}}, providers.Options{}), "/code1", nil},
+		{"文本格式错误码1", providers.NewTextProvider(providers.Options{}), "/code1", proxypool.ErrProvider},
+		{"文本格式识别错误信息", providers.NewTextProvider(providers.Options{}), "/balance", proxypool.ErrBalanceInsufficient},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxies, err := tt.provider.Fetch(srv.URL + tt.path)
+			if tt.err == nil {
+				if err != nil || len(proxies) != 0 {
+					t.Errorf("proxies = %v, err = %v", proxies, err)
+				}
+				return
+			}
+			var perr *proxypool.ProviderError
+			if !errors.Is(err, tt.err) || !errors.As(err, &perr) {
+				t.Errorf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestParseTextLinesHostPort(t *testing.T) {
+	tests := []struct {
+		line string
+		host string // 为空表示应忽略该行
+		port string
+	}{
+		{"1.2.3.4:1", "1.2.3.4", "1"},
+		{"1.2.3.4:65535", "1.2.3.4", "65535"},
+		{"1.2.3.4:0", "", ""},
+		{"1.2.3.4:65536", "", ""},
+		{"1.2.3.4:99999", "", ""},
+		{"1.2.3.4:", "", ""},
+		{"1.2.3.4:80a", "", ""},
+		{"1.2.3.4 0", "", ""},
+		{"[2001:db8::1]:8080", "2001:db8::1", "8080"},
+		{"2001:db8::1:8080", "2001:db8::1", "8080"},
+		{"2001:db8::1 8080", "2001:db8::1", "8080"},
+		{"user:pass@2001:db8::1:8080", "2001:db8::1", "8080"},
+		{"[2001:db8::1]:0", "", ""},
+		{"2001:db8::1", "", ""},
+		{"2001:db8::g:8080", "", ""},
+	}
+	for _, tt := range tests {
+		// 附加一个合法行，避免整个响应被当作错误信息
+		proxies, err := providers.ParseTextLines([]byte(tt.line + "\n9.9.9.9:80"))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.line, err)
+		}
+		if tt.host == "" {
+			if len(proxies) != 1 {
+				t.Errorf("%s: 应忽略, proxies = %+v", tt.line, proxies)
+			}
+			continue
+		}
+		if len(proxies) != 2 || proxies[0].IP != tt.host || proxies[0].Port != tt.port {
+			t.Errorf("%s: proxies = %+v, want %s %s", tt.line, proxies, tt.host, tt.port)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package providers
+
+import (
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Drunkard-baifeng/golibs/proxypool"
+)
+
+// 行分隔符：换行、<br>（密码可能包含逗号、分号，不作为分隔符）
+var lineSplitRegex = regexp.MustCompile(`\r?\n|\r|<br\s*/?>`)
+
+// NewTextProvider 文本行格式的适配器
+// 每行一个代理，支持 ip:port、ip port、ip:port:user:pass、user:pass@ip:port
+// IPv6 可以写作 [ipv6]:port，不带方括号时最后一个冒号之后为端口
+// 没有解析到代理且响应不为空时，按错误信息识别（余额不足、白名单等）
+func NewTextProvider(opts Options) *Provider {
+	return newProvider(opts, ParseTextLines)
+}
+
+// ParseTextLines 解析文本行格式的响应
+func ParseTextLines(body []byte) ([]proxypool.ProxyAddr, error) {
+	text := strings.TrimSpace(string(body))
+	if text == "" {
+		return []proxypool.ProxyAddr{}, nil
+	}
+
+	// 文本接口出错时经常返回 JSON
+	if strings.HasPrefix(text, "{") {
+		if err := parseEnvelopeError(body, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]proxypool.ProxyAddr, 0)
+	for _, line := range lineSplitRegex.Split(text, -1) {
+		if addr, ok := parseLine(strings.TrimSpace(line)); ok {
+			result = append(result, addr)
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, proxypool.ClassifyProviderError("", text)
+	}
+	return result, nil
+}
+
+// parseLine 解析一行代理
+func parseLine(line string) (proxypool.ProxyAddr, bool) {
+	var addr proxypool.ProxyAddr
+	if line == "" {
+		return addr, false
+	}
+
+	// ip:port:user:pass（密码可能包含冒号、@）
+	if parts := strings.SplitN(line, ":", 4); len(parts) == 4 && validHostPort(parts[0], parts[1]) {
+		addr.IP, addr.Port = parts[0], parts[1]
+		addr.Username, addr.Password = parts[2], parts[3]
+		return addr, true
+	}
+
+	// user:pass@ip:port（密码可能包含@）
+	if at := strings.LastIndex(line, "@"); at >= 0 {
+		host, port, ok := splitHostPort(line[at+1:])
+		if !ok {
+			return addr, false
+		}
+		addr.IP, addr.Port = host, port
+		addr.Username, addr.Password, _ = strings.Cut(line[:at], ":")
+		return addr, true
+	}
+
+	// ip port
+	if fields := strings.Fields(line); len(fields) == 2 {
+		line = fields[0] + ":" + fields[1]
+	}
+
+	// ip:port
+	host, port, ok := splitHostPort(line)
+	if !ok {
+		return addr, false
+	}
+	addr.IP, addr.Port = host, port
+	return addr, true
+}
+
+// splitHostPort 拆分主机和端口，支持 [ipv6]:port 和不带方括号的 ipv6:port
+func splitHostPort(s string) (host, port string, ok bool) {
+	if strings.HasPrefix(s, "[") {
+		host, port, err := net.SplitHostPort(s)
+		return host, port, err == nil && validHostPort(host, port)
+	}
+	i := strings.LastIndex(s, ":")
+	if i < 0 {
+		return "", "", false
+	}
+	host, port = s[:i], s[i+1:]
+	// 主机部分包含冒号时必须是 IPv6 地址
+	if strings.Contains(host, ":") && net.ParseIP(host) == nil {
+		return "", "", false
+	}
+	return host, port, validHostPort(host, port)
+}
+
+// validHostPort 检查主机和端口是否合法
+func validHostPort(host, port string) bool {
+	if host == "" || strings.ContainsAny(host, " \t/") {
+		return false
+	}
+	if net.ParseIP(host) == nil && !strings.Contains(host, ".") {
+		return false
+	}
+	for _, c := range port {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	n, err := strconv.Atoi(port)
+	return err == nil && n >= 1 && n <= 65535
+}
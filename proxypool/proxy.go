@@ -1,6 +1,7 @@
 package proxypool
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"sync"
@@ -35,6 +36,7 @@ type ProxyResult struct {
 	Proxy   string            // 代理地址 ip:port 或 ip:port:user:pass
 	Headers map[string]string // 额外的HTTP头（虚拟IP模式用）
 	IP      string            // 显示用的IP
+	Item    *ProxyItem        // 代理池模式下对应的代理项（其他模式为nil）
 }
 
 // Proxy 代理管理器
@@ -216,14 +218,24 @@ func splitAuthProxy(s string) []string {
 
 // ==================== 获取代理 ====================
 
-// GetProxy 获取代理（统一入口，带重试）
+// getProxyTimeout 代理池模式下 GetProxy 等待可用代理的最长时间
+const getProxyTimeout = 10 * time.Second
+
+// GetProxy 获取代理（统一入口，代理池模式最多等待10秒）
 func (p *Proxy) GetProxy() (*ProxyResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), getProxyTimeout)
+	defer cancel()
+	return p.GetProxyContext(ctx)
+}
+
+// GetProxyContext 获取代理，代理池模式下等待刷新或新代理加入，直到 ctx 取消或超时
+func (p *Proxy) GetProxyContext(ctx context.Context) (*ProxyResult, error) {
 	p.mu.RLock()
 	mode := p.mode
 	proxyType := p.proxyType
 	p.mu.RUnlock()
 
-	// 不换IP 和 虚拟IP 模式不需要重试
+	// 不换IP 和 虚拟IP 模式不需要等待
 	if mode == ModeNone {
 		return p.getNoProxy()
 	}
@@ -234,18 +246,11 @@ func (p *Proxy) GetProxy() (*ProxyResult, error) {
 		return p.getAuthProxy(proxyType)
 	}
 
-	// 代理池模式：循环重试直到获取成功
-	maxRetry := 10
-	for i := 0; i < maxRetry; i++ {
-		result, err := p.getPoolProxy(proxyType)
-		if err == nil {
-			return result, nil
-		}
-		// 获取失败，等待1秒后重试
-		time.Sleep(time.Second)
+	result, err := p.getPoolProxy(ctx, proxyType)
+	if err != nil {
+		return nil, fmt.Errorf("获取代理失败: %w", err)
 	}
-
-	return nil, fmt.Errorf("获取代理失败，已重试 %d 次", maxRetry)
+	return result, nil
 }
 
 // getNoProxy 不换IP模式
@@ -288,23 +293,29 @@ func (p *Proxy) getVirtualProxy() (*ProxyResult, error) {
 }
 
 // getPoolProxy 代理池模式
-func (p *Proxy) getPoolProxy(proxyType ProxyType) (*ProxyResult, error) {
+func (p *Proxy) getPoolProxy(ctx context.Context, proxyType ProxyType) (*ProxyResult, error) {
 	p.mu.Lock()
 	p.initPool()
 	pool := p.pool
 	p.mu.Unlock()
 
-	proxy, err := pool.Get()
+	proxy, err := pool.GetContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	// 返回 ip:port 格式
+	// 代理自带协议时优先使用
+	if proxy.Protocol != "" {
+		proxyType = proxy.Protocol
+	}
+
+	// 返回 ip:port 或 ip:port:user:pass 格式
 	return &ProxyResult{
 		Type:    proxyType,
-		Proxy:   proxy.String(), // ip:port
+		Proxy:   proxy.AuthString(),
 		Headers: nil,
 		IP:      proxy.String(),
+		Item:    proxy,
 	}, nil
 }
 
@@ -338,6 +349,36 @@ func (p *Proxy) getAuthProxy(proxyType ProxyType) (*ProxyResult, error) {
 	}, nil
 }
 
+// ==================== 使用反馈 ====================
+
+// ReportSuccess 报告代理使用成功（仅代理池模式生效）
+func (p *Proxy) ReportSuccess(result *ProxyResult, latency time.Duration) {
+	if pool := p.feedbackPool(result); pool != nil {
+		pool.ReportSuccess(result.Item, latency)
+	}
+}
+
+// ReportFailure 报告代理使用失败（仅代理池模式生效），返回是否封禁
+func (p *Proxy) ReportFailure(result *ProxyResult, reason FailureReason) bool {
+	if pool := p.feedbackPool(result); pool != nil {
+		return pool.ReportFailure(result.Item, reason)
+	}
+	return false
+}
+
+// feedbackPool 获取可以接收反馈的代理池
+func (p *Proxy) feedbackPool(result *ProxyResult) *ProxyPool {
+	if result == nil || result.Item == nil {
+		return nil
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.mode != ModePool {
+		return nil
+	}
+	return p.pool
+}
+
 // ==================== 状态获取 ====================
 
 // GetMode 获取当前模式
@@ -2,19 +2,40 @@ package proxypool
 
 import (
 	"fmt"
+	"net"
+	"net/url"
+	"sync"
 	"sync/atomic"
 	"time"
 )
 
 // ProxyItem 代理项
 type ProxyItem struct {
-	IP          string    // IP地址
+	IP          string    // IP地址（或主机名）
 	Port        string    // 端口
+	Username    string    // 用户名（无认证为空）
+	Password    string    // 密码
+	Protocol    ProxyType // 协议（默认http）
+	Country     string    // 国家/地区
 	usedCount   int64     // 使用次数（原子操作）
 	maxUseCount int64     // 最大使用次数
 	expireTime  time.Time // 过期时间
 	createTime  time.Time // 创建时间
 	lastUseTime time.Time // 最后使用时间
+	seq         uint64    // 加入代理池的序号（轮询用）
+	inUse       int64     // 正在使用的租约数（原子操作）
+
+	healthMu            sync.Mutex    // 健康状态锁
+	checkFailures       int           // 健康检查连续失败次数
+	latency             time.Duration // 平均耗时（检测与使用反馈）
+	lastCheckTime       time.Time     // 最近一次检测时间
+	quarantineUntil     time.Time     // 隔离截止时间
+	banned              bool          // 是否已永久封禁
+	bannedUntil         time.Time     // 临时封禁截止时间（健康检查不会解除）
+	successCount        int64         // 使用成功次数
+	failureCount        int64         // 使用失败次数
+	consecutiveFailures int           // 使用连续失败次数
+	lastFailReason      FailureReason // 最近一次失败原因
 }
 
 // NewProxyItem 创建代理项
@@ -41,24 +62,95 @@ func NewProxyItemWithConfig(ip, port string, maxUseCount int, expireSeconds int)
 	}
 }
 
-// String 返回代理字符串格式 ip:port
+// NewProxyItemFromAddr 根据 ProxyAddr 创建代理项（带配置）
+// addr 中的过期时间、有效秒数、最大使用次数不为零时覆盖默认配置
+func NewProxyItemFromAddr(addr ProxyAddr, maxUseCount int, expireSeconds int) *ProxyItem {
+	if addr.MaxUseCount > 0 {
+		maxUseCount = addr.MaxUseCount
+	}
+	if addr.ExpireSeconds > 0 {
+		expireSeconds = addr.ExpireSeconds
+	}
+	item := NewProxyItemWithConfig(addr.IP, addr.Port, maxUseCount, expireSeconds)
+	item.Username = addr.Username
+	item.Password = addr.Password
+	item.Protocol = addr.Protocol
+	item.Country = addr.Country
+	if !addr.ExpireTime.IsZero() {
+		item.expireTime = addr.ExpireTime
+	}
+	return item
+}
+
+// String 返回代理字符串格式 ip:port（IPv6 为 [ip]:port）
 func (p *ProxyItem) String() string {
-	return fmt.Sprintf("%s:%s", p.IP, p.Port)
+	return net.JoinHostPort(p.IP, p.Port)
+}
+
+// matches 是否匹配代理字符串（ip:port、[ip]:port 或 Key()）
+// IPv6 同时接受不带方括号的旧格式 ip:port
+func (p *ProxyItem) matches(s string) bool {
+	return s == p.String() || s == p.IP+":"+p.Port || s == p.Key()
+}
+
+// Key 返回代理唯一标识 协议://用户名@ip:port（不含密码，用于去重）
+func (p *ProxyItem) Key() string {
+	key := string(p.GetProtocol()) + "://"
+	if p.Username != "" {
+		key += p.Username + "@"
+	}
+	return key + p.String()
+}
+
+// GetProtocol 获取协议（未设置时为http）
+func (p *ProxyItem) GetProtocol() ProxyType {
+	if p.Protocol == "" {
+		return TypeHTTP
+	}
+	return p.Protocol
+}
+
+// HasAuth 是否需要账密认证
+func (p *ProxyItem) HasAuth() bool {
+	return p.Username != ""
 }
 
-// URL 返回代理URL格式 http://ip:port
+// URL 返回代理URL，按协议生成，有账密时包含认证信息
+// 例如 http://ip:port、socks5://user:pass@ip:port
 func (p *ProxyItem) URL() string {
-	return fmt.Sprintf("http://%s:%s", p.IP, p.Port)
+	return p.buildURL(string(p.GetProtocol()))
 }
 
-// Socks5URL 返回SOCKS5代理URL格式
+// Socks5URL 返回SOCKS5代理URL格式（有账密时包含认证信息）
 func (p *ProxyItem) Socks5URL() string {
-	return fmt.Sprintf("socks5://%s:%s", p.IP, p.Port)
+	return p.buildURL(string(TypeSocks5))
+}
+
+// AuthString 返回 ip:port:user:pass 格式（无账密时为 ip:port）
+func (p *ProxyItem) AuthString() string {
+	if !p.HasAuth() {
+		return p.String()
+	}
+	return fmt.Sprintf("%s:%s:%s:%s", p.IP, p.Port, p.Username, p.Password)
+}
+
+// buildURL 生成指定协议的代理URL
+func (p *ProxyItem) buildURL(scheme string) string {
+	u := url.URL{Scheme: scheme, Host: p.String()}
+	if p.Username != "" {
+		u.User = url.UserPassword(p.Username, p.Password)
+	}
+	return u.String()
 }
 
 // IsAvailable 检查代理是否可用
 func (p *ProxyItem) IsAvailable() bool {
-	return time.Now().Before(p.expireTime) && atomic.LoadInt64(&p.usedCount) < p.maxUseCount
+	return time.Now().Before(p.expireTime) && atomic.LoadInt64(&p.usedCount) < p.maxUseCount && !p.IsQuarantined() && !p.IsBanned()
+}
+
+// isRetired 是否已过期、用尽或被永久封禁（隔离和临时封禁的代理不算，到期后仍可使用）
+func (p *ProxyItem) isRetired() bool {
+	return !time.Now().Before(p.expireTime) || p.IsMaxUsed() || p.isBannedForever()
 }
 
 // IsExpired 检查代理是否过期
@@ -91,6 +183,11 @@ func (p *ProxyItem) GetUsedCount() int {
 	return int(atomic.LoadInt64(&p.usedCount))
 }
 
+// GetInUse 获取正在使用的租约数
+func (p *ProxyItem) GetInUse() int {
+	return int(atomic.LoadInt64(&p.inUse))
+}
+
 // GetMaxUseCount 获取最大使用次数
 func (p *ProxyItem) GetMaxUseCount() int {
 	return int(p.maxUseCount)
@@ -124,6 +221,11 @@ func (p *ProxyItem) SetExpireTime(expireTime time.Time) {
 	p.expireTime = expireTime
 }
 
+// GetExpireTime 获取过期时间
+func (p *ProxyItem) GetExpireTime() time.Time {
+	return p.expireTime
+}
+
 // ExtendExpireTime 延长过期时间
 func (p *ProxyItem) ExtendExpireTime(duration time.Duration) {
 	p.expireTime = p.expireTime.Add(duration)
@@ -134,3 +236,177 @@ func (p *ProxyItem) Reset(expireSeconds int) {
 	atomic.StoreInt64(&p.usedCount, 0)
 	p.expireTime = time.Now().Add(time.Duration(expireSeconds) * time.Second)
 }
+
+// Quarantine 隔离代理一段时间，期间不会被分配
+func (p *ProxyItem) Quarantine(d time.Duration) {
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+	p.quarantineUntil = time.Now().Add(d)
+}
+
+// Unquarantine 解除隔离
+func (p *ProxyItem) Unquarantine() {
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+	p.quarantineUntil = time.Time{}
+}
+
+// IsQuarantined 是否处于隔离中
+func (p *ProxyItem) IsQuarantined() bool {
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+	return time.Now().Before(p.quarantineUntil)
+}
+
+// GetLatency 获取平均耗时（健康检查与成功反馈的加权平均，未测量为0）
+func (p *ProxyItem) GetLatency() time.Duration {
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+	return p.latency
+}
+
+// GetCheckFailures 获取健康检查连续失败次数
+func (p *ProxyItem) GetCheckFailures() int {
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+	return p.checkFailures
+}
+
+// GetLastCheckTime 获取最近一次健康检查时间
+func (p *ProxyItem) GetLastCheckTime() time.Time {
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+	return p.lastCheckTime
+}
+
+// recordCheck 记录健康检查结果，返回连续失败次数
+// 检测通过时清零失败次数并解除隔离
+func (p *ProxyItem) recordCheck(result HealthResult) int {
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+
+	p.lastCheckTime = time.Now()
+	if result.OK {
+		p.updateLatencyUnsafe(result.Latency)
+		p.checkFailures = 0
+		p.quarantineUntil = time.Time{}
+	} else {
+		p.checkFailures++
+	}
+	return p.checkFailures
+}
+
+// Ban 永久封禁代理，清理时移除
+func (p *ProxyItem) Ban() {
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+	p.banned = true
+}
+
+// BanFor 临时封禁代理一段时间，期间不会被分配，健康检查通过也不会解除
+func (p *ProxyItem) BanFor(d time.Duration) {
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+	p.bannedUntil = time.Now().Add(d)
+}
+
+// Unban 解除封禁（永久和临时）
+func (p *ProxyItem) Unban() {
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+	p.banned = false
+	p.bannedUntil = time.Time{}
+}
+
+// IsBanned 是否处于封禁中（永久或临时）
+func (p *ProxyItem) IsBanned() bool {
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+	return p.banned || time.Now().Before(p.bannedUntil)
+}
+
+// isBannedForever 是否已永久封禁
+func (p *ProxyItem) isBannedForever() bool {
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+	return p.banned
+}
+
+// GetSuccessCount 获取使用成功次数
+func (p *ProxyItem) GetSuccessCount() int {
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+	return int(p.successCount)
+}
+
+// GetFailureCount 获取使用失败次数
+func (p *ProxyItem) GetFailureCount() int {
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+	return int(p.failureCount)
+}
+
+// GetConsecutiveFailures 获取使用连续失败次数
+func (p *ProxyItem) GetConsecutiveFailures() int {
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+	return p.consecutiveFailures
+}
+
+// GetLastFailReason 获取最近一次失败原因
+func (p *ProxyItem) GetLastFailReason() FailureReason {
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+	return p.lastFailReason
+}
+
+// GetSuccessRate 获取成功率（0~1，没有反馈时为1）
+func (p *ProxyItem) GetSuccessRate() float64 {
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+	total := p.successCount + p.failureCount
+	if total == 0 {
+		return 1
+	}
+	return float64(p.successCount) / float64(total)
+}
+
+// recordSuccess 记录一次使用成功
+func (p *ProxyItem) recordSuccess(latency time.Duration) {
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+
+	p.successCount++
+	p.consecutiveFailures = 0
+	p.updateLatencyUnsafe(latency)
+}
+
+// recordFailure 记录一次使用失败，返回连续失败次数
+func (p *ProxyItem) recordFailure(reason FailureReason) int {
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+
+	p.failureCount++
+	p.consecutiveFailures++
+	p.lastFailReason = reason
+	return p.consecutiveFailures
+}
+
+// resetConsecutiveFailures 清零连续失败次数
+func (p *ProxyItem) resetConsecutiveFailures() {
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+	p.consecutiveFailures = 0
+}
+
+// updateLatencyUnsafe 更新平均耗时（指数加权，需持有 healthMu）
+func (p *ProxyItem) updateLatencyUnsafe(latency time.Duration) {
+	if latency <= 0 {
+		return
+	}
+	if p.latency == 0 {
+		p.latency = latency
+		return
+	}
+	p.latency = (p.latency*7 + latency*3) / 10
+}
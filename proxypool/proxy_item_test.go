@@ -0,0 +1,55 @@
+package proxypool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProxyLookupAcceptsBothIPv6Forms(t *testing.T) {
+	p := New(Config{MaxUseCount: 100, ExpireSeconds: 60})
+	p.AddProxyAddr(ProxyAddr{IP: "2001:db8::1", Port: "8080", Username: "u", Password: "p"})
+	p.AddProxy("10.0.0.1", "8080")
+	item := p.GetAll()[0]
+
+	if s := item.String(); s != "[2001:db8::1]:8080" {
+		t.Errorf("String = %q", s)
+	}
+	for _, s := range []string{"[2001:db8::1]:8080", "2001:db8::1:8080", "http://u@[2001:db8::1]:8080"} {
+		if !p.ReportSuccessByString(s, 0) {
+			t.Errorf("ReportSuccessByString(%q) 未找到代理", s)
+		}
+	}
+	if p.find("10.0.0.1:8080") == nil {
+		t.Error("IPv4 查找失败")
+	}
+
+	if !p.RemoveByString("2001:db8::1:8080") {
+		t.Fatal("RemoveByString 不接受旧格式")
+	}
+	if p.Contains(item) || p.Size() != 1 {
+		t.Errorf("移除后 Size = %d", p.Size())
+	}
+}
+
+func TestAddProxyItemKeepsExpiryAndMaxUse(t *testing.T) {
+	expire := time.Now().Add(30 * time.Second).Truncate(time.Second)
+	item := NewProxyItemFromAddr(ProxyAddr{IP: "10.0.0.1", Port: "8080", ExpireTime: expire, MaxUseCount: 2}, 100, 600)
+
+	p := New(Config{MaxUseCount: 100, ExpireSeconds: 600, ExpireMargin: 10 * time.Second})
+	if !p.AddProxyItem(item) {
+		t.Fatal("AddProxyItem 失败")
+	}
+	added := p.GetAll()[0]
+	if !added.GetExpireTime().Equal(expire) {
+		t.Errorf("ExpireTime = %v, want %v（不应使用默认值或再次扣除提前量）", added.GetExpireTime(), expire)
+	}
+	if added.GetMaxUseCount() != 2 {
+		t.Errorf("MaxUseCount = %d, want 2", added.GetMaxUseCount())
+	}
+
+	// 已过期的代理项不添加
+	expired := NewProxyItemFromAddr(ProxyAddr{IP: "10.0.0.2", Port: "8080", ExpireTime: time.Now().Add(-time.Second)}, 100, 600)
+	if p.AddProxyItem(expired) {
+		t.Error("已过期的代理项不应添加")
+	}
+}
@@ -0,0 +1,64 @@
+package proxypool
+
+import "time"
+
+// OnRebindFn 固定代理换绑时的回调（old 为 nil 表示首次绑定）
+type OnRebindFn func(key string, old, new *ProxyItem)
+
+// SetOnRebind 设置固定代理换绑回调
+func (p *ProxyPool) SetOnRebind(fn OnRebindFn) *ProxyPool {
+	p.onRebind = fn
+	return p
+}
+
+// GetSticky 按 key（如账号）获取固定代理
+// 绑定的代理可用、未达到并发上限且最近没有失败时一直返回它；过期、用尽、被移除或报告失败后按选择策略换绑新代理
+// 绑定由 StickyStrategy 维护，检查和换绑在代理池锁内完成，同一个 key 并发调用也只会绑定一个代理
+func (p *ProxyPool) GetSticky(key string) (*ProxyItem, error) {
+	if key == "" {
+		return p.Get()
+	}
+
+	var old *ProxyItem
+	var rebound bool
+	strategy := StrategyFunc(func(candidates []*ProxyItem, key string) *ProxyItem {
+		var proxy *ProxyItem
+		proxy, old, rebound = p.sticky.bind(candidates, key)
+		return proxy
+	})
+	proxy, err := p.getWith(strategy, key, true, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if rebound && p.onRebind != nil {
+		p.onRebind(key, old, proxy)
+	}
+	return proxy, nil
+}
+
+// GetStickyBound 获取 key 当前绑定的代理（未绑定返回 nil，不增加使用次数）
+func (p *ProxyPool) GetStickyBound(key string) *ProxyItem {
+	return p.sticky.boundItem(key)
+}
+
+// ReleaseSticky 解除 key 的绑定（如账号退出登录）
+func (p *ProxyPool) ReleaseSticky(key string) {
+	p.sticky.Release(key)
+}
+
+// ReleaseAllSticky 解除所有绑定
+func (p *ProxyPool) ReleaseAllSticky() {
+	p.sticky.ReleaseAll()
+}
+
+// StickyCount 当前绑定的 key 数量
+func (p *ProxyPool) StickyCount() int {
+	return p.sticky.Len()
+}
+
+// SetStickyTTL 设置绑定的代理不可用多久后清理绑定（默认10分钟，0 不清理）
+func (p *ProxyPool) SetStickyTTL(ttl time.Duration) *ProxyPool {
+	p.sticky.SetTTL(ttl)
+	return p
+}
@@ -0,0 +1,155 @@
+package proxypool
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newStickyTestPool 包含4个代理的代理池
+func newStickyTestPool(cfg Config) *ProxyPool {
+	cfg.MaxUseCount = 100
+	cfg.ExpireSeconds = 60
+	p := New(cfg)
+	for _, port := range []string{"8001", "8002", "8003", "8004"} {
+		p.AddProxy("10.0.0.1", port)
+	}
+	return p
+}
+
+func TestGetStickyConcurrentSameKey(t *testing.T) {
+	p := newStickyTestPool(Config{})
+	var mu sync.Mutex
+	rebinds := 0
+	p.SetOnRebind(func(key string, old, new *ProxyItem) {
+		mu.Lock()
+		rebinds++
+		mu.Unlock()
+	})
+
+	results := make([]*ProxyItem, 50)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = p.GetSticky("account")
+		}(i)
+	}
+	wg.Wait()
+
+	for _, proxy := range results {
+		if proxy == nil || proxy != results[0] {
+			t.Fatalf("同一个 key 绑定了不同的代理: %v, %v", proxy, results[0])
+		}
+	}
+	if rebinds != 1 {
+		t.Errorf("OnRebind 调用 %d 次, want 1", rebinds)
+	}
+	if p.StickyCount() != 1 || p.GetStickyBound("account") != results[0] {
+		t.Errorf("StickyCount=%d bound=%v", p.StickyCount(), p.GetStickyBound("account"))
+	}
+}
+
+func TestGetStickyRespectsCapacity(t *testing.T) {
+	p := newStickyTestPool(Config{MaxConcurrency: 1})
+
+	bound, err := p.GetSticky("account")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 绑定的代理被租满后换绑
+	var lease *Lease
+	for lease == nil || lease.Proxy != bound {
+		if lease != nil {
+			lease.Release(true)
+		}
+		if lease, err = p.Acquire(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer lease.Release(true)
+
+	proxy, err := p.GetSticky("account")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proxy == bound {
+		t.Error("并发已满的代理仍被返回")
+	}
+}
+
+func TestGetStickyRebindsAfterFailure(t *testing.T) {
+	p := newStickyTestPool(Config{MaxFailures: -1})
+
+	var events [][2]*ProxyItem
+	p.SetOnRebind(func(key string, old, new *ProxyItem) {
+		events = append(events, [2]*ProxyItem{old, new})
+	})
+
+	first, _ := p.GetSticky("account")
+	if again, _ := p.GetSticky("account"); again != first {
+		t.Fatalf("未失败时换绑: %v -> %v", first, again)
+	}
+
+	p.ReportFailure(first, FailTimeout)
+	second, _ := p.GetSticky("account")
+	if second == nil || second == first {
+		t.Fatalf("失败后未换绑: %v", second)
+	}
+
+	if len(events) != 2 || events[0][0] != nil || events[0][1] != first || events[1][0] != first || events[1][1] != second {
+		t.Errorf("OnRebind 事件 = %v", events)
+	}
+
+	p.ReleaseSticky("account")
+	if p.GetStickyBound("account") != nil || p.StickyCount() != 0 {
+		t.Error("ReleaseSticky 后仍有绑定")
+	}
+}
+
+func TestGetStickyUsesPoolStrategy(t *testing.T) {
+	p := newStickyTestPool(Config{})
+	last := p.GetAll()[3]
+	p.SetStrategy(StrategyFunc(func(candidates []*ProxyItem, key string) *ProxyItem {
+		return candidates[len(candidates)-1]
+	}))
+
+	if proxy, _ := p.GetSticky("account"); proxy != last {
+		t.Errorf("GetSticky = %v, want %v", proxy, last)
+	}
+}
+
+func TestStickyStrategyPrunesBindings(t *testing.T) {
+	items := newStickyTestPool(Config{}).GetAll()
+	var refuse bool
+	s := NewStickyStrategy(StrategyFunc(func(candidates []*ProxyItem, key string) *ProxyItem {
+		if refuse || len(candidates) == 0 {
+			return nil
+		}
+		return candidates[0]
+	})).SetTTL(100 * time.Millisecond)
+
+	s.Select(items[0:1], "removed")
+	s.Select(items[1:2], "kept")
+	time.Sleep(60 * time.Millisecond)
+	s.Select(items[2:3], "recent")
+
+	// 清理超过TTL不在可用列表中的绑定，刚绑定的代理暂时不可用时保留
+	time.Sleep(45 * time.Millisecond)
+	s.Select(items[1:2], "kept")
+	if s.Len() != 2 || s.Bound("removed") != "" || s.Bound("recent") != items[2].Key() || s.Bound("kept") != items[1].Key() {
+		t.Fatalf("清理后 removed=%q recent=%q kept=%q", s.Bound("removed"), s.Bound("recent"), s.Bound("kept"))
+	}
+
+	// 没有可换绑的代理时解除绑定
+	refuse = true
+	if got := s.Select(items[3:4], "kept"); got != nil {
+		t.Fatalf("Select = %v, want nil", got)
+	}
+	if s.Bound("kept") != "" || s.Len() != 1 {
+		t.Errorf("换绑失败后 kept=%q len=%d", s.Bound("kept"), s.Len())
+	}
+}
@@ -0,0 +1,278 @@
+package proxypool
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Strategy 代理选择策略
+// candidates 为当前可用的代理（按加入顺序），key 为调用方传入的业务键（可为空）
+// 返回 nil 表示没有合适的代理
+type Strategy interface {
+	Select(candidates []*ProxyItem, key string) *ProxyItem
+}
+
+// StrategyFunc 函数形式的选择策略
+type StrategyFunc func(candidates []*ProxyItem, key string) *ProxyItem
+
+// Select 实现 Strategy
+func (f StrategyFunc) Select(candidates []*ProxyItem, key string) *ProxyItem {
+	return f(candidates, key)
+}
+
+// ==================== 轮询 ====================
+
+// roundRobinStrategy 轮询（按代理加入顺序，可用列表变化时不会跳过或重复）
+type roundRobinStrategy struct {
+	mu      sync.Mutex
+	lastSeq uint64
+}
+
+// NewRoundRobinStrategy 轮询策略（默认）
+func NewRoundRobinStrategy() Strategy {
+	return &roundRobinStrategy{}
+}
+
+func (s *roundRobinStrategy) Select(candidates []*ProxyItem, key string) *ProxyItem {
+	if len(candidates) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 取上次之后加入的第一个代理，没有则从头开始
+	var next, first *ProxyItem
+	for _, proxy := range candidates {
+		if first == nil || proxy.seq < first.seq {
+			first = proxy
+		}
+		if proxy.seq > s.lastSeq && (next == nil || proxy.seq < next.seq) {
+			next = proxy
+		}
+	}
+	if next == nil {
+		next = first
+	}
+	s.lastSeq = next.seq
+	return next
+}
+
+// ==================== 随机 ====================
+
+// NewRandomStrategy 随机策略
+func NewRandomStrategy() Strategy {
+	return StrategyFunc(func(candidates []*ProxyItem, key string) *ProxyItem {
+		if len(candidates) == 0 {
+			return nil
+		}
+		return candidates[rand.Intn(len(candidates))]
+	})
+}
+
+// ==================== 最少使用 ====================
+
+// NewLeastUsedStrategy 最少使用策略（使用次数相同时取先加入的）
+func NewLeastUsedStrategy() Strategy {
+	return StrategyFunc(func(candidates []*ProxyItem, key string) *ProxyItem {
+		var best *ProxyItem
+		for _, proxy := range candidates {
+			if best == nil || proxy.GetUsedCount() < best.GetUsedCount() {
+				best = proxy
+			}
+		}
+		return best
+	})
+}
+
+// ==================== 最低延迟 ====================
+
+// NewLowestLatencyStrategy 最低延迟策略
+// 耗时来自健康检查和 ReportSuccess，未测量的代理排在已测量的之后
+func NewLowestLatencyStrategy() Strategy {
+	return StrategyFunc(func(candidates []*ProxyItem, key string) *ProxyItem {
+		var best *ProxyItem
+		var bestLatency int64
+		for _, proxy := range candidates {
+			latency := int64(proxy.GetLatency())
+			if best == nil || (latency > 0 && (bestLatency == 0 || latency < bestLatency)) {
+				best = proxy
+				bestLatency = latency
+			}
+		}
+		return best
+	})
+}
+
+// ==================== 按成功率加权 ====================
+
+// NewWeightedStrategy 按成功率加权随机策略
+// 没有反馈的代理成功率按1计算，成功率很低的代理仍保留少量机会
+func NewWeightedStrategy() Strategy {
+	const minWeight = 0.05
+	return StrategyFunc(func(candidates []*ProxyItem, key string) *ProxyItem {
+		if len(candidates) == 0 {
+			return nil
+		}
+		weights := make([]float64, len(candidates))
+		total := 0.0
+		for i, proxy := range candidates {
+			w := proxy.GetSuccessRate()
+			if w < minWeight {
+				w = minWeight
+			}
+			weights[i] = w
+			total += w
+		}
+		r := rand.Float64() * total
+		for i, w := range weights {
+			if r < w {
+				return candidates[i]
+			}
+			r -= w
+		}
+		return candidates[len(candidates)-1]
+	})
+}
+
+// ==================== 按键固定 ====================
+
+// DefaultStickyTTL 绑定的代理不在可用列表中多久后清理绑定
+const DefaultStickyTTL = 10 * time.Minute
+
+// StickyStrategy 按键固定策略：相同的 key 返回同一个代理，直到它不可用或报告了失败
+// key 为空或绑定的代理不可用时使用 fallback 选择并重新绑定
+// 绑定的代理超过 TTL 没有出现在可用列表中（如已被移除）时清理绑定，不再持有该代理
+type StickyStrategy struct {
+	mu        sync.Mutex
+	fallback  Strategy
+	bindings  map[string]*stickyBinding // key -> 绑定
+	ttl       time.Duration             // 清理不可用绑定的时间（0 不清理）
+	lastPrune time.Time                 // 上次清理时间
+}
+
+// stickyBinding 绑定的代理和它最近一次出现在可用列表中的时间
+type stickyBinding struct {
+	proxy *ProxyItem
+	seen  time.Time
+}
+
+// NewStickyStrategy 按键固定策略（fallback 为 nil 时使用轮询）
+func NewStickyStrategy(fallback Strategy) *StickyStrategy {
+	if fallback == nil {
+		fallback = NewRoundRobinStrategy()
+	}
+	return &StickyStrategy{
+		fallback:  fallback,
+		bindings:  make(map[string]*stickyBinding),
+		ttl:       DefaultStickyTTL,
+		lastPrune: time.Now(),
+	}
+}
+
+// SetTTL 设置绑定的代理不可用多久后清理绑定（0 不清理）
+func (s *StickyStrategy) SetTTL(ttl time.Duration) *StickyStrategy {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ttl = ttl
+	return s
+}
+
+// Select 实现 Strategy
+func (s *StickyStrategy) Select(candidates []*ProxyItem, key string) *ProxyItem {
+	proxy, _, _ := s.bind(candidates, key)
+	return proxy
+}
+
+// bind 选择并绑定代理，返回选中的代理、之前绑定的代理和是否换绑
+// 绑定的代理仍在候选列表中且最近没有失败时继续使用；没有可换绑的代理时解除绑定
+func (s *StickyStrategy) bind(candidates []*ProxyItem, key string) (proxy, old *ProxyItem, rebound bool) {
+	if key == "" {
+		return s.fallback.Select(candidates, key), nil, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.pruneLocked(candidates, now)
+
+	if b := s.bindings[key]; b != nil {
+		old = b.proxy
+		bound := old.Key()
+		for _, candidate := range candidates {
+			// 同一地址被移除后重新加入时按 Key() 继续绑定
+			if candidate.Key() == bound && candidate.GetConsecutiveFailures() == 0 {
+				s.bindings[key] = &stickyBinding{proxy: candidate, seen: now}
+				return candidate, old, false
+			}
+		}
+	}
+
+	proxy = s.fallback.Select(candidates, key)
+	if proxy == nil {
+		delete(s.bindings, key)
+		return nil, old, false
+	}
+	s.bindings[key] = &stickyBinding{proxy: proxy, seen: now}
+	return proxy, old, true
+}
+
+// pruneLocked 每隔 TTL 检查一次绑定，清理超过 TTL 不在候选列表中的绑定（需持有锁）
+func (s *StickyStrategy) pruneLocked(candidates []*ProxyItem, now time.Time) {
+	if s.ttl <= 0 || now.Sub(s.lastPrune) < s.ttl {
+		return
+	}
+	s.lastPrune = now
+
+	present := make(map[string]bool, len(candidates))
+	for _, candidate := range candidates {
+		present[candidate.Key()] = true
+	}
+	for key, b := range s.bindings {
+		if present[b.proxy.Key()] {
+			b.seen = now
+		} else if now.Sub(b.seen) >= s.ttl {
+			delete(s.bindings, key)
+		}
+	}
+}
+
+// Bound 获取 key 当前绑定的代理 Key()（未绑定返回空）
+func (s *StickyStrategy) Bound(key string) string {
+	if proxy := s.boundItem(key); proxy != nil {
+		return proxy.Key()
+	}
+	return ""
+}
+
+// boundItem 获取 key 当前绑定的代理
+func (s *StickyStrategy) boundItem(key string) *ProxyItem {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if b := s.bindings[key]; b != nil {
+		return b.proxy
+	}
+	return nil
+}
+
+// Len 当前绑定的 key 数量
+func (s *StickyStrategy) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.bindings)
+}
+
+// Release 解除 key 的绑定
+func (s *StickyStrategy) Release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.bindings, key)
+}
+
+// ReleaseAll 解除所有绑定
+func (s *StickyStrategy) ReleaseAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bindings = make(map[string]*stickyBinding)
+}
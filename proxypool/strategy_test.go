@@ -0,0 +1,142 @@
+package proxypool
+
+import (
+	"testing"
+	"time"
+)
+
+// pick 按下标取代理
+func pick(items []*ProxyItem, idx ...int) []*ProxyItem {
+	out := make([]*ProxyItem, 0, len(idx))
+	for _, i := range idx {
+		out = append(out, items[i])
+	}
+	return out
+}
+
+func TestRoundRobinStrategy(t *testing.T) {
+	p := newStickyTestPool(Config{MaxFailures: -1})
+	p.AddProxy("10.0.0.1", "8005")
+	items := p.GetAll()
+
+	// 每步的候选列表和期望选中的代理，候选列表变化时按加入顺序继续
+	steps := []struct {
+		name       string
+		candidates []int
+		want       int
+	}{
+		{"从第一个开始", []int{0, 1, 2, 3}, 0},
+		{"依次轮询", []int{0, 1, 2, 3}, 1},
+		{"下一个不可用时跳到其后", []int{0, 1, 3}, 3},
+		{"上次选中的被移除", []int{0, 1, 2}, 0},
+		{"重新可用的代理按顺序轮到", []int{0, 1, 2, 3}, 1},
+		{"候选列表顺序打乱", []int{3, 0, 2, 1}, 2},
+		{"新加入的代理排在最后", []int{0, 1, 2, 3, 4}, 3},
+		{"轮到新加入的代理", []int{0, 1, 2, 3, 4}, 4},
+		{"一轮结束后从头开始", []int{1, 2, 3, 4}, 1},
+	}
+	s := NewRoundRobinStrategy()
+	for _, step := range steps {
+		if got := s.Select(pick(items, step.candidates...), ""); got != items[step.want] {
+			t.Fatalf("%s: 选中 %v, want %v", step.name, got, items[step.want])
+		}
+	}
+	if s.Select(nil, "") != nil {
+		t.Error("没有候选时应返回nil")
+	}
+}
+
+func TestLeastUsedStrategy(t *testing.T) {
+	tests := []struct {
+		name string
+		used []int
+		want int
+	}{
+		{"选使用最少的", []int{3, 1, 2, 5}, 1},
+		{"次数相同取先加入的", []int{2, 1, 1, 1}, 1},
+		{"都未使用", []int{0, 0, 0, 0}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := newStickyTestPool(Config{}).GetAll()
+			for i, n := range tt.used {
+				for j := 0; j < n; j++ {
+					items[i].IncrementUseCount()
+				}
+			}
+			if got := NewLeastUsedStrategy().Select(items, ""); got != items[tt.want] {
+				t.Errorf("选中 %v, want %v", got, items[tt.want])
+			}
+		})
+	}
+	if NewLeastUsedStrategy().Select(nil, "") != nil {
+		t.Error("没有候选时应返回nil")
+	}
+}
+
+func TestLowestLatencyStrategy(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		name    string
+		latency []time.Duration // 0 表示未测量
+		want    int
+	}{
+		{"选耗时最低的", []time.Duration{30 * ms, 10 * ms, 20 * ms, 40 * ms}, 1},
+		{"未测量的排在已测量之后", []time.Duration{0, 0, 50 * ms, 0}, 2},
+		{"第一个已测量", []time.Duration{5 * ms, 0, 50 * ms, 0}, 0},
+		{"都未测量取第一个", []time.Duration{0, 0, 0, 0}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newStickyTestPool(Config{})
+			items := p.GetAll()
+			for i, latency := range tt.latency {
+				if latency > 0 {
+					p.ReportSuccess(items[i], latency)
+				}
+			}
+			if got := NewLowestLatencyStrategy().Select(items, ""); got != items[tt.want] {
+				t.Errorf("选中 %v, want %v", got, items[tt.want])
+			}
+		})
+	}
+}
+
+func TestWeightedStrategy(t *testing.T) {
+	p := newStickyTestPool(Config{MaxFailures: -1})
+	items := p.GetAll()[:3]
+	// 0: 没有反馈（按1计算），1: 成功率0.5，2: 全部失败（按0.05计算）
+	p.ReportSuccess(items[1], 0)
+	p.ReportFailure(items[1], FailTimeout)
+	for i := 0; i < 5; i++ {
+		p.ReportFailure(items[2], FailTimeout)
+	}
+
+	const n = 20000
+	counts := make(map[*ProxyItem]int)
+	s := NewWeightedStrategy()
+	for i := 0; i < n; i++ {
+		counts[s.Select(items, "")]++
+	}
+
+	tests := []struct {
+		name   string
+		item   *ProxyItem
+		weight float64
+	}{
+		{"没有反馈", items[0], 1},
+		{"成功率一半", items[1], 0.5},
+		{"全部失败仍保留少量机会", items[2], 0.05},
+	}
+	total := 1 + 0.5 + 0.05
+	for _, tt := range tests {
+		got := float64(counts[tt.item]) / n
+		want := tt.weight / total
+		if got < want*0.8 || got > want*1.2 {
+			t.Errorf("%s: 比例 %.3f, want %.3f", tt.name, got, want)
+		}
+	}
+	if s.Select(nil, "") != nil {
+		t.Error("没有候选时应返回nil")
+	}
+}
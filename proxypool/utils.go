@@ -1,9 +1,12 @@
 package proxypool
 
 import (
+	"errors"
 	"io"
 	"net/http"
 	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Drunkard-baifeng/golibs/logger"
@@ -47,6 +50,13 @@ func SimpleFetchFunc(apiURL string) ([]ProxyAddr, error) {
 	}
 	logger.Debugf("加载代理: %s", string(body))
 	result := ExtractIPPort(string(body))
+	if len(result) == 0 {
+		// 没有提取到代理时检查是否为余额不足、白名单等错误
+		if err := DetectProviderError(string(body)); err != nil {
+			logger.Errorf("加载代理失败: %v", err)
+			return nil, err
+		}
+	}
 	logger.Successf("成功加载 %d 个代理", len(result))
 	return result, nil
 }
@@ -66,6 +76,53 @@ func CreateFetchFunc(httpClient *http.Client) FetchFunc {
 			return nil, err
 		}
 
-		return ExtractIPPort(string(body)), nil
+		result := ExtractIPPort(string(body))
+		if len(result) == 0 {
+			if err := DetectProviderError(string(body)); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+}
+
+// 服务商常见的过期时间格式
+var expireTimeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006/01/02 15:04:05",
+	"2006-01-02T15:04:05",
+	time.RFC3339,
+	"2006-01-02 15:04",
+}
+
+// ParseExpireTime 解析服务商返回的过期时间
+// 支持 "2006-01-02 15:04:05"（本地时区）、RFC3339、Unix 秒/毫秒时间戳
+func ParseExpireTime(s string) (time.Time, error) {
+	return ParseExpireTimeIn(s, time.Local)
+}
+
+// ParseExpireTimeIn 按指定时区解析不带时区的过期时间（如服务商固定返回北京时间）
+// loc 为 nil 时使用本地时区；RFC3339 和时间戳不受 loc 影响
+func ParseExpireTimeIn(s string, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, errors.New("过期时间为空")
+	}
+
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if n > 1e12 {
+			return time.UnixMilli(n), nil
+		}
+		return time.Unix(n, 0), nil
+	}
+
+	for _, layout := range expireTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
 	}
+	return time.Time{}, errors.New("无法解析过期时间: " + s)
 }
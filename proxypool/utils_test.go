@@ -0,0 +1,37 @@
+package proxypool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseExpireTimeIn(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+
+	got, err := ParseExpireTimeIn("2024-05-01 12:00:00", shanghai)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 5, 1, 4, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("ParseExpireTimeIn = %v, want %v", got.UTC(), want)
+	}
+
+	// 自带时区和时间戳不受 loc 影响
+	cases := map[string]time.Time{
+		"2024-05-01T12:00:00Z": time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		"1714564800":           time.Unix(1714564800, 0),
+		"1714564800000":        time.UnixMilli(1714564800000),
+	}
+	for s, want := range cases {
+		got, err := ParseExpireTimeIn(s, shanghai)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("ParseExpireTimeIn(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+
+	// nil 使用本地时区
+	got, _ = ParseExpireTimeIn("2024-05-01 12:00:00", nil)
+	if want := time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local); !got.Equal(want) {
+		t.Errorf("nil loc = %v, want %v", got, want)
+	}
+}
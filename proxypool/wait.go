@@ -0,0 +1,84 @@
+package proxypool
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// refreshRetryDelay 后台刷新失败后的重试间隔
+const refreshRetryDelay = time.Second
+
+// waitPollInterval 等待时的兜底检查间隔（隔离到期等按时间恢复的情况没有通知）
+const waitPollInterval = time.Second
+
+// GetContext 获取一个可用代理，没有可用代理时等待
+// 等待刷新完成或有新代理加入，直到 ctx 取消或超时
+func (p *ProxyPool) GetContext(ctx context.Context) (*ProxyItem, error) {
+	return p.GetByKeyContext(ctx, "")
+}
+
+// GetByKeyContext 按业务键获取代理，没有可用代理时等待（见 GetContext）
+func (p *ProxyPool) GetByKeyContext(ctx context.Context, key string) (*ProxyItem, error) {
+	return p.waitGet(ctx, key, false)
+}
+
+// waitGet 循环获取代理直到成功或 ctx 结束
+func (p *ProxyPool) waitGet(ctx context.Context, key string, acquire bool) (*ProxyItem, error) {
+	timer := time.NewTimer(waitPollInterval)
+	defer timer.Stop()
+
+	for {
+		// 先取通知通道再尝试获取，避免错过两者之间的通知
+		wait := p.waitChan()
+		proxy, err := p.get(key, false, acquire)
+		if err == nil {
+			return proxy, nil
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(waitPollInterval)
+
+		select {
+		case <-wait:
+		case <-timer.C:
+		case <-ctx.Done():
+			if refreshErr := p.lastRefreshError(); refreshErr != nil {
+				return nil, fmt.Errorf("%w: %w, 最近一次刷新失败: %w", ErrNoAvailableProxy, ctx.Err(), refreshErr)
+			}
+			return nil, fmt.Errorf("%w: %w", ErrNoAvailableProxy, ctx.Err())
+		}
+	}
+}
+
+// waitChan 获取当前的通知通道，代理池有变化时关闭
+func (p *ProxyPool) waitChan() <-chan struct{} {
+	p.waitMu.Lock()
+	defer p.waitMu.Unlock()
+	if p.waitCh == nil {
+		p.waitCh = make(chan struct{})
+	}
+	return p.waitCh
+}
+
+// notifyWaiters 唤醒所有等待中的 GetContext
+func (p *ProxyPool) notifyWaiters() {
+	p.waitMu.Lock()
+	defer p.waitMu.Unlock()
+	if p.waitCh != nil {
+		close(p.waitCh)
+		p.waitCh = nil
+	}
+}
+
+// lastRefreshError 最近一次刷新的错误（成功为 nil）
+func (p *ProxyPool) lastRefreshError() error {
+	p.refreshMu.Lock()
+	defer p.refreshMu.Unlock()
+	return p.lastRefreshErr
+}
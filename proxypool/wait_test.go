@@ -0,0 +1,110 @@
+package proxypool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetContext(t *testing.T) {
+	errAPI := errors.New("api down")
+
+	tests := []struct {
+		name    string
+		fetch   FetchFunc
+		setup   func(p *ProxyPool)
+		timeout time.Duration
+		wantErr []error       // 为空表示应成功
+		maxWait time.Duration // 返回前最多等待的时间
+	}{
+		{
+			name: "加入代理时唤醒",
+			setup: func(p *ProxyPool) {
+				time.AfterFunc(30*time.Millisecond, func() { p.AddProxy("10.0.0.1", "8080") })
+			},
+			timeout: 5 * time.Second,
+			maxWait: waitPollInterval / 2,
+		},
+		{
+			name: "后台刷新完成时唤醒",
+			fetch: func(string) ([]ProxyAddr, error) {
+				time.Sleep(30 * time.Millisecond)
+				return []ProxyAddr{{IP: "10.0.0.2", Port: "8080"}}, nil
+			},
+			timeout: 5 * time.Second,
+			maxWait: waitPollInterval / 2,
+		},
+		{
+			name:    "超时",
+			timeout: 50 * time.Millisecond,
+			wantErr: []error{ErrNoAvailableProxy, context.DeadlineExceeded},
+			maxWait: waitPollInterval / 2,
+		},
+		{
+			name:    "超时错误包含最近一次刷新错误",
+			fetch:   func(string) ([]ProxyAddr, error) { return nil, errAPI },
+			timeout: 50 * time.Millisecond,
+			wantErr: []error{ErrNoAvailableProxy, context.DeadlineExceeded, errAPI},
+			maxWait: waitPollInterval / 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(Config{MaxUseCount: 100, ExpireSeconds: 60, FetchFunc: tt.fetch})
+			if tt.setup != nil {
+				tt.setup(p)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+			start := time.Now()
+			proxy, err := p.GetContext(ctx)
+			if took := time.Since(start); took > tt.maxWait {
+				t.Errorf("等待了 %v, want < %v", took, tt.maxWait)
+			}
+
+			if len(tt.wantErr) == 0 {
+				if err != nil || proxy == nil {
+					t.Fatalf("GetContext = %v, %v", proxy, err)
+				}
+				return
+			}
+			for _, want := range tt.wantErr {
+				if !errors.Is(err, want) {
+					t.Errorf("err = %v, want %v", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetContextTriggersSingleRefresh(t *testing.T) {
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	p := New(Config{MaxUseCount: 100, ExpireSeconds: 60, FetchFunc: func(string) ([]ProxyAddr, error) {
+		fetches.Add(1)
+		<-release
+		return []ProxyAddr{{IP: "10.0.0.1", Port: "8080"}}, nil
+	}})
+
+	// 多个等待者共享同一次刷新
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		go func() {
+			_, err := p.GetContext(context.Background())
+			errs <- err
+		}()
+	}
+	time.Sleep(30 * time.Millisecond)
+	if fetches.Load() != 1 {
+		t.Errorf("等待中刷新 %d 次, want 1", fetches.Load())
+	}
+	close(release)
+	for i := 0; i < 5; i++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+}